@@ -0,0 +1,99 @@
+// Command archive-import loads a cold archive file written by the cleaner
+// back into the events archive, from where the events can be restored through
+// the API:
+//
+//	archive-import -file /archive/events-2025-09.jsonl.gz
+//
+// The file is checked against the manifest of its directory first. Rows that
+// are already in the database are skipped, so a file can be imported twice.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/avraam311/improved-calendar-service/internal/config"
+	"github.com/avraam311/improved-calendar-service/internal/models"
+	"github.com/avraam311/improved-calendar-service/internal/pkg/coldarchive"
+	archiveRepo "github.com/avraam311/improved-calendar-service/internal/repository/archive"
+)
+
+func main() {
+	file := flag.String("file", "", "cold archive file to import")
+	skipVerify := flag.Bool("skip-verify", false, "import without checking the file against the manifest")
+	batch := flag.Int("batch", 1000, "records imported in one transaction")
+	flag.Parse()
+
+	if *file == "" || *batch < 1 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	if err := run(*file, *skipVerify, *batch); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// run imports the file. It returns instead of exiting, so the connection pool
+// is closed and the signal handler is stopped on failure too.
+func run(file string, skipVerify bool, batch int) error {
+	if !skipVerify {
+		if err := coldarchive.Verify(file); err != nil {
+			return fmt.Errorf("archive file verification failed: %w", err)
+		}
+	}
+
+	cfg := config.MustLoad()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	dbpool, err := pgxpool.New(ctx, cfg.DatabaseURL())
+	if err != nil {
+		return fmt.Errorf("error creating connection pool: %w", err)
+	}
+	defer dbpool.Close()
+
+	repo := archiveRepo.New(dbpool)
+	var read, imported int
+	records := make([]*models.ArchiveRecord, 0, batch)
+	flush := func() error {
+		n, err := repo.ImportArchive(ctx, records)
+		if err != nil {
+			return err
+		}
+		imported += n
+		records = records[:0]
+		return nil
+	}
+
+	err = coldarchive.Read(file, func(record *models.ArchiveRecord) error {
+		read++
+		records = append(records, record)
+		if len(records) < batch {
+			return nil
+		}
+		return flush()
+	})
+	if err == nil && len(records) > 0 {
+		err = flush()
+	}
+	if errors.Is(err, context.Canceled) {
+		return fmt.Errorf("import interrupted after %d of %d records", imported, read)
+	}
+	if err != nil {
+		return fmt.Errorf("import failed after %d records: %w", imported, err)
+	}
+
+	fmt.Printf("imported %d of %d records from %s, %d already present\n", imported, read, file, read-imported)
+
+	return nil
+}
@@ -3,7 +3,9 @@ package main
 import (
 	"context"
 	"errors"
+	"io/fs"
 	"net/http"
+	"os"
 	"os/signal"
 	"syscall"
 	"time"
@@ -11,16 +13,39 @@ import (
 	"github.com/jackc/pgx/v5/pgxpool"
 	"go.uber.org/zap"
 
+	archiveHandler "github.com/avraam311/improved-calendar-service/internal/api/handlers/archive"
+	attendeeHandler "github.com/avraam311/improved-calendar-service/internal/api/handlers/attendee"
+	caldavHandler "github.com/avraam311/improved-calendar-service/internal/api/handlers/caldav"
 	eventHandler "github.com/avraam311/improved-calendar-service/internal/api/handlers/event"
+	feedHandler "github.com/avraam311/improved-calendar-service/internal/api/handlers/feed"
+	reminderHandler "github.com/avraam311/improved-calendar-service/internal/api/handlers/reminder"
+	settingsHandler "github.com/avraam311/improved-calendar-service/internal/api/handlers/settings"
 	"github.com/avraam311/improved-calendar-service/internal/api/server"
 	"github.com/avraam311/improved-calendar-service/internal/config"
 	"github.com/avraam311/improved-calendar-service/internal/models"
+	"github.com/avraam311/improved-calendar-service/internal/pkg/coldarchive"
+	"github.com/avraam311/improved-calendar-service/internal/pkg/cron"
 	"github.com/avraam311/improved-calendar-service/internal/pkg/logger"
 	sender "github.com/avraam311/improved-calendar-service/internal/pkg/notifier"
+	"github.com/avraam311/improved-calendar-service/internal/pkg/templates"
+	"github.com/avraam311/improved-calendar-service/internal/pkg/timerange"
 	"github.com/avraam311/improved-calendar-service/internal/pkg/validator"
 	"github.com/avraam311/improved-calendar-service/internal/pkg/workers"
+	archiveRepo "github.com/avraam311/improved-calendar-service/internal/repository/archive"
+	attendeeRepo "github.com/avraam311/improved-calendar-service/internal/repository/attendee"
+	caldavRepo "github.com/avraam311/improved-calendar-service/internal/repository/caldav"
+	digestRepo "github.com/avraam311/improved-calendar-service/internal/repository/digest"
 	eventRepo "github.com/avraam311/improved-calendar-service/internal/repository/event"
+	feedRepo "github.com/avraam311/improved-calendar-service/internal/repository/feed"
+	reminderRepo "github.com/avraam311/improved-calendar-service/internal/repository/reminder"
+	settingsRepo "github.com/avraam311/improved-calendar-service/internal/repository/settings"
+	archiveService "github.com/avraam311/improved-calendar-service/internal/service/archive"
+	attendeeService "github.com/avraam311/improved-calendar-service/internal/service/attendee"
+	caldavService "github.com/avraam311/improved-calendar-service/internal/service/caldav"
 	eventService "github.com/avraam311/improved-calendar-service/internal/service/event"
+	feedService "github.com/avraam311/improved-calendar-service/internal/service/feed"
+	reminderService "github.com/avraam311/improved-calendar-service/internal/service/reminder"
+	settingsService "github.com/avraam311/improved-calendar-service/internal/service/settings"
 )
 
 func main() {
@@ -32,6 +57,56 @@ func main() {
 	mdLog := logger.SetupLogger(cfg.Logger.Env, cfg.Logger.MdLogFilePath)
 	val := validator.New()
 
+	weekStart, err := timerange.ParseWeekday(cfg.Calendar.WeekStart)
+	if err != nil {
+		log.Fatal("invalid calendar.weekStart", zap.Error(err))
+	}
+	if p := cfg.Calendar.ConflictPolicy; p != models.ConflictReject && p != models.ConflictWarn {
+		log.Fatal("invalid calendar.conflictPolicy", zap.String("policy", p))
+	}
+	if cfg.Notifier.MaxAttempts < 1 || cfg.Notifier.RetryBase <= 0 || cfg.Notifier.RetryMax < cfg.Notifier.RetryBase {
+		log.Fatal("invalid notifier retry settings", zap.Any("notifier", cfg.Notifier))
+	}
+	cleanerSchedule, err := cron.Parse(cfg.Cleaner.Schedule)
+	if err != nil {
+		log.Fatal("invalid cleaner.schedule", zap.Error(err))
+	}
+	if cfg.Cleaner.MaxAge <= 0 {
+		log.Fatal("invalid cleaner.maxAge", zap.Duration("maxAge", cfg.Cleaner.MaxAge))
+	}
+	if cfg.Cleaner.BatchSize < 1 || cfg.Cleaner.BatchPause < 0 {
+		log.Fatal("invalid cleaner batch settings", zap.Int("batchSize", cfg.Cleaner.BatchSize),
+			zap.Duration("batchPause", cfg.Cleaner.BatchPause))
+	}
+	retention := workers.Retention{
+		MaxAge:    cfg.Cleaner.MaxAge,
+		Overrides: make(map[int]time.Duration, len(cfg.Cleaner.Overrides)),
+		LegalHold: cfg.Cleaner.LegalHold,
+	}
+	for _, override := range cfg.Cleaner.Overrides {
+		if override.UserID == 0 || override.MaxAge <= 0 {
+			log.Fatal("invalid cleaner retention override", zap.Any("override", override))
+		}
+		retention.Overrides[override.UserID] = override.MaxAge
+	}
+	batch := workers.Batch{Size: cfg.Cleaner.BatchSize, Pause: cfg.Cleaner.BatchPause}
+	cold := workers.ColdArchive{After: cfg.Cleaner.ColdAfter}
+	if cfg.Cleaner.ColdDir != "" {
+		if cfg.Cleaner.ColdAfter < 0 {
+			log.Fatal("invalid cleaner.coldAfter", zap.Duration("coldAfter", cfg.Cleaner.ColdAfter))
+		}
+		cold.Writer = coldarchive.NewWriter(cfg.Cleaner.ColdDir)
+	}
+
+	templateFS := []fs.FS{templates.Default()}
+	if cfg.Templates.Dir != "" {
+		templateFS = append(templateFS, os.DirFS(cfg.Templates.Dir))
+	}
+	renderer, err := templates.Load(templateFS...)
+	if err != nil {
+		log.Fatal("error loading notification templates", zap.Error(err))
+	}
+
 	dbpool, err := pgxpool.New(ctx, cfg.DatabaseURL())
 	if err != nil {
 		log.Fatal("error creating connection pool", zap.Error(err))
@@ -41,17 +116,61 @@ func main() {
 	asyncLog := workers.NewAsyncLogger(logsCh, log)
 	go asyncLog.Run(ctx)
 
+	mail := sender.NewMail(cfg.Mail.Host, cfg.Mail.Port, cfg.Mail.User, cfg.Mail.From, cfg.Mail.Password)
+	attendeeR := attendeeRepo.New(dbpool)
+	settingsR := settingsRepo.New(dbpool)
+	attendeeS := attendeeService.New(attendeeR, settingsR, mail, renderer)
 	eventR := eventRepo.New(dbpool)
-	eventS := eventService.New(eventR)
+	reminderR := reminderRepo.New(dbpool)
+	eventS := eventService.New(eventR, settingsR, reminderR, attendeeS, cfg.Calendar.ConflictPolicy)
 	eventPostH := eventHandler.NewPostHandler(logsCh, val, eventS)
-	eventGetH := eventHandler.NewGetHandler(logsCh, val, eventS)
-	r := server.NewRouter(eventPostH, eventGetH, mdLog)
+	eventGetH := eventHandler.NewGetHandler(logsCh, val, eventS, weekStart)
+	feedR := feedRepo.New(dbpool)
+	feedS := feedService.New(feedR)
+	feedH := feedHandler.NewHandler(logsCh, val, feedS, eventS, cfg.Feed.PastDays, cfg.Feed.FutureDays)
+	caldavR := caldavRepo.New(dbpool)
+	caldavS := caldavService.New(caldavR)
+	caldavH := caldavHandler.NewHandler(logsCh, val, eventS, caldavS)
+	settingsS := settingsService.New(settingsR)
+	settingsH := settingsHandler.NewHandler(logsCh, val, settingsS)
+	attendeeH := attendeeHandler.NewHandler(logsCh, val, attendeeS)
+	reminderS := reminderService.New(reminderR)
+	reminderH := reminderHandler.NewHandler(logsCh, val, reminderS)
+	archiveR := archiveRepo.New(dbpool)
+	archiveS := archiveService.New(archiveR, eventS)
+	archiveH := archiveHandler.NewHandler(logsCh, val, archiveS)
+	r := server.NewRouter(&server.Handlers{
+		EventPost: eventPostH,
+		EventGet:  eventGetH,
+		Feed:      feedH,
+		CalDAV:    caldavH,
+		Settings:  settingsH,
+		Attendee:  attendeeH,
+		Reminder:  reminderH,
+		Archive:   archiveH,
+	}, cfg.Admin.Token, mdLog)
 	s := server.NewServer(cfg.Server.HTTPPort, r)
 
-	evsCh := make(chan *models.EventCreate, 10)
-	mail := sender.NewMail(cfg.Mail.Host, cfg.Mail.Port, cfg.Mail.User, cfg.Mail.From, cfg.Mail.Password)
-	notifier := workers.NewNotifier(evsCh, mail, log)
-	cleaner := workers.NewCleaner(eventR, log)
+	retry := workers.RetryPolicy{
+		MaxAttempts: cfg.Notifier.MaxAttempts,
+		Base:        cfg.Notifier.RetryBase,
+		Max:         cfg.Notifier.RetryMax,
+	}
+	httpClient := &http.Client{Timeout: cfg.Channels.Timeout}
+	channels := sender.NewRegistry()
+	channels.Register(models.ChannelEmail, mail)
+	channels.Register(models.ChannelWebhook, sender.NewWebhook(httpClient, cfg.Channels.WebhookSecret))
+	channels.Register(models.ChannelLog, sender.NewLog(log))
+	if cfg.Channels.TelegramToken != "" {
+		channels.Register(models.ChannelTelegram, sender.NewTelegram(httpClient, cfg.Channels.TelegramURL, cfg.Channels.TelegramToken))
+	}
+	if cfg.Channels.SMSURL != "" {
+		channels.Register(models.ChannelSMS, sender.NewSMS(httpClient, cfg.Channels.SMSURL, cfg.Channels.SMSAPIKey, cfg.Channels.SMSFrom))
+	}
+	notifier := workers.NewNotifier(reminderR, eventS, channels, settingsR, renderer, retry, log)
+	digestR := digestRepo.New(dbpool)
+	digest := workers.NewDigest(digestR, eventS, channels, renderer, weekStart, log)
+	cleaner := workers.NewCleaner(archiveR, cleanerSchedule, retention, batch, cold, cfg.Cleaner.DryRun, log)
 
 	go func() {
 		log.Info("starting HTTP server", zap.String("port", cfg.Server.HTTPPort))
@@ -60,6 +179,7 @@ func main() {
 		}
 	}()
 	go notifier.Run(ctx)
+	go digest.Run(ctx)
 	go cleaner.Run(ctx)
 
 	<-ctx.Done()
@@ -0,0 +1,148 @@
+package archive
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"go.uber.org/zap"
+
+	"github.com/avraam311/improved-calendar-service/internal/models"
+	"github.com/avraam311/improved-calendar-service/internal/pkg/validator"
+	archiveR "github.com/avraam311/improved-calendar-service/internal/repository/archive"
+)
+
+// Handler serves the events the cleaner moved to the archive.
+type Handler struct {
+	LogsCh         chan *models.Log
+	validator      *validator.GoValidator
+	archiveService archiveService
+}
+
+func NewHandler(logsCh chan *models.Log, v *validator.GoValidator, s archiveService) *Handler {
+	return &Handler{
+		LogsCh:         logsCh,
+		validator:      v,
+		archiveService: s,
+	}
+}
+
+// GetArchivedEvents lists the archived events of the user, optionally
+// searching by title and date range.
+func (h *Handler) GetArchivedEvents(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		h.sendLog("not allowed methods", "warn", zap.String("method", r.Method))
+		h.handleError(w, http.StatusBadRequest, "only method GET allowed")
+		return
+	}
+
+	var get *models.ArchiveGet
+	err := json.NewDecoder(r.Body).Decode(&get)
+	if err != nil {
+		h.sendLog("failed to decode JSON", "warn", zap.Error(err))
+		h.handleError(w, http.StatusBadRequest, "invalid json")
+		return
+	}
+
+	err = h.validator.Validate(get)
+	if err != nil {
+		h.sendLog("validation error", "warn", zap.Error(err))
+		h.handleError(w, http.StatusBadRequest, "validation error")
+		return
+	}
+
+	events, err := h.archiveService.GetArchivedEvents(r.Context(), get)
+	if err != nil {
+		h.sendLog("failed to get archived events", "error", zap.Error(err))
+		h.handleError(w, http.StatusInternalServerError, "internal error")
+		return
+	}
+
+	response := map[string][]*models.ArchivedEvent{
+		"result": events,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	err = json.NewEncoder(w).Encode(response)
+	if err != nil {
+		h.sendLog("failed to encode error response", "error", zap.Error(err))
+		http.Error(w, "error response encoding error", http.StatusInternalServerError)
+	}
+}
+
+func (h *Handler) RestoreEvent(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		h.sendLog("not allowed methods", "warn", zap.String("method", r.Method))
+		h.handleError(w, http.StatusBadRequest, "only method POST allowed")
+		return
+	}
+
+	var restore *models.ArchiveRestore
+	err := json.NewDecoder(r.Body).Decode(&restore)
+	if err != nil {
+		h.sendLog("failed to decode JSON", "warn", zap.Error(err))
+		h.handleError(w, http.StatusBadRequest, "invalid json")
+		return
+	}
+
+	err = h.validator.Validate(restore)
+	if err != nil {
+		h.sendLog("validation error", "warn", zap.Error(err))
+		h.handleError(w, http.StatusBadRequest, "validation error")
+		return
+	}
+
+	ID, err := h.archiveService.RestoreEvent(r.Context(), restore)
+	if errors.Is(err, archiveR.ErrArchivedEventNotFound) {
+		h.sendLog("archived event not found", "warn", zap.Uint("id", restore.ID))
+		h.handleError(w, http.StatusNotFound, "archived event not found")
+		return
+	}
+	if errors.Is(err, archiveR.ErrRestoreConflict) {
+		h.sendLog("archived event conflicts with an existing event", "warn", zap.Uint("id", restore.ID))
+		h.handleError(w, http.StatusConflict, "event conflicts with an existing event")
+		return
+	}
+	if err != nil {
+		h.sendLog("failed to restore event", "error", zap.Error(err))
+		h.handleError(w, http.StatusInternalServerError, "internal error")
+		return
+	}
+
+	h.sendLog("event restored", "info", zap.Uint("id", ID))
+
+	response := map[string]uint{
+		"result": ID,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	err = json.NewEncoder(w).Encode(response)
+	if err != nil {
+		h.sendLog("failed to encode error response", "error", zap.Error(err))
+		http.Error(w, "error response encoding error", http.StatusInternalServerError)
+	}
+}
+
+func (h *Handler) handleError(w http.ResponseWriter, code int, msg string) {
+	errorResponse := map[string]string{
+		"error": msg,
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	err := json.NewEncoder(w).Encode(errorResponse)
+	if err != nil {
+		h.sendLog("failed to encode error response", "error", zap.Error(err))
+		http.Error(w, "error response encoding error", http.StatusInternalServerError)
+	}
+}
+
+func (h *Handler) sendLog(msg, level string, field zap.Field) {
+	logEntry := &models.Log{
+		Msg:   msg,
+		Level: level,
+		Field: field,
+	}
+	h.LogsCh <- logEntry
+}
@@ -0,0 +1,13 @@
+package archive
+
+import (
+	"context"
+
+	"github.com/avraam311/improved-calendar-service/internal/models"
+)
+
+//go:generate mockgen -source=interface.go -destination=../../../mocks/mock_archive_handlers.go -package=mocks
+type archiveService interface {
+	GetArchivedEvents(ctx context.Context, get *models.ArchiveGet) ([]*models.ArchivedEvent, error)
+	RestoreEvent(ctx context.Context, restore *models.ArchiveRestore) (uint, error)
+}
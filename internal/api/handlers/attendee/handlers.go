@@ -0,0 +1,244 @@
+package attendee
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"go.uber.org/zap"
+
+	"github.com/avraam311/improved-calendar-service/internal/models"
+	"github.com/avraam311/improved-calendar-service/internal/pkg/validator"
+	attendeeR "github.com/avraam311/improved-calendar-service/internal/repository/attendee"
+	attendeeS "github.com/avraam311/improved-calendar-service/internal/service/attendee"
+)
+
+type Handler struct {
+	LogsCh          chan *models.Log
+	validator       *validator.GoValidator
+	attendeeService attendeeService
+}
+
+func NewHandler(logsCh chan *models.Log, v *validator.GoValidator, s attendeeService) *Handler {
+	return &Handler{
+		LogsCh:          logsCh,
+		validator:       v,
+		attendeeService: s,
+	}
+}
+
+func (h *Handler) Invite(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		h.sendLog("not allowed methods", "warn", zap.String("method", r.Method))
+		h.handleError(w, http.StatusBadRequest, "only method POST allowed")
+		return
+	}
+
+	var invite *models.AttendeeInvite
+	err := json.NewDecoder(r.Body).Decode(&invite)
+	if err != nil {
+		h.sendLog("failed to decode JSON", "warn", zap.Error(err))
+		h.handleError(w, http.StatusBadRequest, "invalid json")
+		return
+	}
+
+	err = h.validator.Validate(invite)
+	if err != nil {
+		h.sendLog("validation error", "warn", zap.Error(err))
+		h.handleError(w, http.StatusBadRequest, "validation error")
+		return
+	}
+
+	attendees, err := h.attendeeService.Invite(r.Context(), invite)
+	if err != nil && !errors.Is(err, attendeeS.ErrNotSent) {
+		if code, msg, ok := attendeeError(err); ok {
+			h.sendLog(msg, "warn", zap.Error(err))
+			h.handleError(w, code, msg)
+			return
+		}
+
+		h.sendLog("failed to invite attendees", "error", zap.Error(err))
+		h.handleError(w, http.StatusInternalServerError, "internal error")
+		return
+	}
+
+	h.sendLog("attendees invited", "info", zap.Any("attendees", attendees))
+	h.sendResult(w, http.StatusOK, attendees, err)
+}
+
+func (h *Handler) Uninvite(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodDelete {
+		h.sendLog("not allowed methods", "warn", zap.String("method", r.Method))
+		h.handleError(w, http.StatusBadRequest, "only method DELETE allowed")
+		return
+	}
+
+	var remove *models.AttendeeRemove
+	err := json.NewDecoder(r.Body).Decode(&remove)
+	if err != nil {
+		h.sendLog("failed to decode JSON", "warn", zap.Error(err))
+		h.handleError(w, http.StatusBadRequest, "invalid json")
+		return
+	}
+
+	err = h.validator.Validate(remove)
+	if err != nil {
+		h.sendLog("validation error", "warn", zap.Error(err))
+		h.handleError(w, http.StatusBadRequest, "validation error")
+		return
+	}
+
+	attendee, err := h.attendeeService.Uninvite(r.Context(), remove)
+	if err != nil && !errors.Is(err, attendeeS.ErrNotSent) {
+		if code, msg, ok := attendeeError(err); ok {
+			h.sendLog(msg, "warn", zap.Error(err))
+			h.handleError(w, code, msg)
+			return
+		}
+
+		h.sendLog("failed to uninvite attendee", "error", zap.Error(err))
+		h.handleError(w, http.StatusInternalServerError, "internal error")
+		return
+	}
+
+	h.sendLog("attendee uninvited", "info", zap.Any("attendee", attendee))
+	h.sendResult(w, http.StatusOK, attendee, err)
+}
+
+func (h *Handler) Respond(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		h.sendLog("not allowed methods", "warn", zap.String("method", r.Method))
+		h.handleError(w, http.StatusBadRequest, "only method POST allowed")
+		return
+	}
+
+	var response *models.AttendeeResponse
+	err := json.NewDecoder(r.Body).Decode(&response)
+	if err != nil {
+		h.sendLog("failed to decode JSON", "warn", zap.Error(err))
+		h.handleError(w, http.StatusBadRequest, "invalid json")
+		return
+	}
+
+	err = h.validator.Validate(response)
+	if err != nil {
+		h.sendLog("validation error", "warn", zap.Error(err))
+		h.handleError(w, http.StatusBadRequest, "validation error")
+		return
+	}
+
+	attendee, err := h.attendeeService.Respond(r.Context(), response)
+	if err != nil && !errors.Is(err, attendeeS.ErrNotSent) {
+		if code, msg, ok := attendeeError(err); ok {
+			h.sendLog(msg, "warn", zap.Error(err))
+			h.handleError(w, code, msg)
+			return
+		}
+
+		h.sendLog("failed to save response", "error", zap.Error(err))
+		h.handleError(w, http.StatusInternalServerError, "internal error")
+		return
+	}
+
+	h.sendLog("attendee responded", "info", zap.Any("attendee", attendee))
+	h.sendResult(w, http.StatusOK, attendee, err)
+}
+
+func (h *Handler) GetAttendees(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		h.sendLog("not allowed methods", "warn", zap.String("method", r.Method))
+		h.handleError(w, http.StatusBadRequest, "only method GET allowed")
+		return
+	}
+
+	var get *models.AttendeesGet
+	err := json.NewDecoder(r.Body).Decode(&get)
+	if err != nil {
+		h.sendLog("failed to decode JSON", "warn", zap.Error(err))
+		h.handleError(w, http.StatusBadRequest, "invalid json")
+		return
+	}
+
+	err = h.validator.Validate(get)
+	if err != nil {
+		h.sendLog("validation error", "warn", zap.Error(err))
+		h.handleError(w, http.StatusBadRequest, "validation error")
+		return
+	}
+
+	attendees, err := h.attendeeService.GetAttendees(r.Context(), get)
+	if err != nil {
+		if code, msg, ok := attendeeError(err); ok {
+			h.sendLog(msg, "warn", zap.Error(err))
+			h.handleError(w, code, msg)
+			return
+		}
+
+		h.sendLog("failed to get attendees", "error", zap.Error(err))
+		h.handleError(w, http.StatusInternalServerError, "internal error")
+		return
+	}
+
+	h.sendResult(w, http.StatusOK, attendees, nil)
+}
+
+// attendeeError maps errors about missing events, attendees and permissions
+// to a response.
+func attendeeError(err error) (int, string, bool) {
+	switch {
+	case errors.Is(err, attendeeR.ErrEventNotFound):
+		return http.StatusNotFound, "event not found", true
+	case errors.Is(err, attendeeR.ErrAttendeeNotFound):
+		return http.StatusNotFound, "attendee not found", true
+	case errors.Is(err, attendeeR.ErrAttendeeAmbiguous):
+		return http.StatusConflict, "user is invited under several emails, respond by email", true
+	case errors.Is(err, attendeeS.ErrNotOrganizer):
+		return http.StatusForbidden, "only the organiser can do this", true
+	case errors.Is(err, attendeeS.ErrNotInvited):
+		return http.StatusForbidden, "user is not invited to the event", true
+	}
+
+	return 0, "", false
+}
+
+// sendResult writes the result. Emails that could not be sent don't undo the
+// change, they are reported in the warning field.
+func (h *Handler) sendResult(w http.ResponseWriter, code int, result any, sendErr error) {
+	response := map[string]any{
+		"result": result,
+	}
+	if sendErr != nil {
+		h.sendLog("failed to send emails", "warn", zap.Error(sendErr))
+		response["warning"] = "some emails were not sent"
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	err := json.NewEncoder(w).Encode(response)
+	if err != nil {
+		h.sendLog("failed to encode error response", "error", zap.Error(err))
+		http.Error(w, "error response encoding error", http.StatusInternalServerError)
+	}
+}
+
+func (h *Handler) handleError(w http.ResponseWriter, code int, msg string) {
+	errorResponse := map[string]string{
+		"error": msg,
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	err := json.NewEncoder(w).Encode(errorResponse)
+	if err != nil {
+		h.sendLog("failed to encode error response", "error", zap.Error(err))
+		http.Error(w, "error response encoding error", http.StatusInternalServerError)
+	}
+}
+
+func (h *Handler) sendLog(msg, level string, field zap.Field) {
+	logEntry := &models.Log{
+		Msg:   msg,
+		Level: level,
+		Field: field,
+	}
+	h.LogsCh <- logEntry
+}
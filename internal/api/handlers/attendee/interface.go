@@ -0,0 +1,15 @@
+package attendee
+
+import (
+	"context"
+
+	"github.com/avraam311/improved-calendar-service/internal/models"
+)
+
+//go:generate mockgen -source=interface.go -destination=../../../mocks/mock_attendee_handlers.go -package=mocks
+type attendeeService interface {
+	Invite(ctx context.Context, invite *models.AttendeeInvite) ([]*models.Attendee, error)
+	Uninvite(ctx context.Context, remove *models.AttendeeRemove) (*models.Attendee, error)
+	Respond(ctx context.Context, response *models.AttendeeResponse) (*models.Attendee, error)
+	GetAttendees(ctx context.Context, get *models.AttendeesGet) ([]*models.Attendee, error)
+}
@@ -0,0 +1,143 @@
+package caldav
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"go.uber.org/zap"
+
+	"github.com/avraam311/improved-calendar-service/internal/models"
+	caldavR "github.com/avraam311/improved-calendar-service/internal/repository/caldav"
+)
+
+// Authenticate lets through the requests authenticated with HTTP Basic auth
+// as the user of the {userID} path segment: the user name is the user ID and
+// the password one issued by RotatePassword.
+func (h *Handler) Authenticate(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		userID, ok := h.userID(w, r)
+		if !ok {
+			return
+		}
+
+		username, password, ok := r.BasicAuth()
+		if !ok || username != strconv.Itoa(userID) {
+			h.unauthorized(w)
+			return
+		}
+
+		owner, err := h.passwords.ResolvePassword(r.Context(), password)
+		if err != nil && !errors.Is(err, caldavR.ErrPasswordNotFound) {
+			h.sendLog("failed to resolve caldav password", "error", zap.Error(err))
+			h.handleError(w, http.StatusInternalServerError, "internal error")
+			return
+		}
+		if err != nil || owner != userID {
+			h.sendLog("caldav authentication failed", "warn", zap.Int("user_id", userID))
+			h.unauthorized(w)
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+func (h *Handler) unauthorized(w http.ResponseWriter) {
+	w.Header().Set("WWW-Authenticate", `Basic realm="calendar", charset="UTF-8"`)
+	h.handleError(w, http.StatusUnauthorized, "unauthorized")
+}
+
+// RotatePassword issues a new CalDAV password for the user and invalidates the
+// old one.
+func (h *Handler) RotatePassword(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		h.sendLog("not allowed methods", "warn", zap.String("method", r.Method))
+		h.handleError(w, http.StatusBadRequest, "only method POST allowed")
+		return
+	}
+
+	UserID, ok := h.decodeUserID(w, r)
+	if !ok {
+		return
+	}
+
+	password, err := h.passwords.RotatePassword(r.Context(), UserID.UserID)
+	if err != nil {
+		h.sendLog("failed to rotate caldav password", "error", zap.Error(err))
+		h.handleError(w, http.StatusInternalServerError, "internal error")
+		return
+	}
+
+	h.sendLog("caldav password rotated", "info", zap.Int("user_id", UserID.UserID))
+
+	h.sendJSON(w, map[string]map[string]string{
+		"result": {
+			"username": strconv.Itoa(UserID.UserID),
+			"password": password,
+			"url":      Prefix + "/" + strconv.Itoa(UserID.UserID) + "/",
+		},
+	})
+}
+
+func (h *Handler) RevokePassword(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodDelete {
+		h.sendLog("not allowed methods", "warn", zap.String("method", r.Method))
+		h.handleError(w, http.StatusBadRequest, "only method DELETE allowed")
+		return
+	}
+
+	UserID, ok := h.decodeUserID(w, r)
+	if !ok {
+		return
+	}
+
+	err := h.passwords.RevokePassword(r.Context(), UserID.UserID)
+	if err != nil {
+		if errors.Is(err, caldavR.ErrPasswordNotFound) {
+			h.sendLog("caldav password not found", "warn", zap.Int("user_id", UserID.UserID))
+			h.handleError(w, http.StatusNotFound, "caldav password not found")
+			return
+		}
+
+		h.sendLog("failed to revoke caldav password", "error", zap.Error(err))
+		h.handleError(w, http.StatusInternalServerError, "internal error")
+		return
+	}
+
+	h.sendLog("caldav password revoked", "info", zap.Int("user_id", UserID.UserID))
+
+	h.sendJSON(w, map[string]int{
+		"result": UserID.UserID,
+	})
+}
+
+func (h *Handler) decodeUserID(w http.ResponseWriter, r *http.Request) (*models.EventGetUserID, bool) {
+	var UserID *models.EventGetUserID
+	err := json.NewDecoder(r.Body).Decode(&UserID)
+	if err != nil {
+		h.sendLog("failed to decode JSON", "warn", zap.Error(err))
+		h.handleError(w, http.StatusBadRequest, "invalid json")
+		return nil, false
+	}
+
+	err = h.validator.Validate(UserID)
+	if err != nil {
+		h.sendLog("validation error", "warn", zap.Error(err))
+		h.handleError(w, http.StatusBadRequest, "validation error")
+		return nil, false
+	}
+
+	return UserID, true
+}
+
+func (h *Handler) sendJSON(w http.ResponseWriter, response any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	err := json.NewEncoder(w).Encode(response)
+	if err != nil {
+		h.sendLog("failed to encode error response", "error", zap.Error(err))
+		http.Error(w, "error response encoding error", http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,63 @@
+package caldav
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/avraam311/improved-calendar-service/internal/mocks"
+	"github.com/avraam311/improved-calendar-service/internal/models"
+	caldavR "github.com/avraam311/improved-calendar-service/internal/repository/caldav"
+)
+
+func TestAuthenticate(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	passwords := mocks.NewMockcaldavPasswordService(ctrl)
+	passwords.EXPECT().ResolvePassword(gomock.Any(), "secret-1").Return(1, nil).AnyTimes()
+	passwords.EXPECT().ResolvePassword(gomock.Any(), "secret-2").Return(2, nil).AnyTimes()
+	passwords.EXPECT().ResolvePassword(gomock.Any(), "wrong").Return(0, caldavR.ErrPasswordNotFound).AnyTimes()
+
+	h := NewHandler(make(chan *models.Log, 10), nil, nil, passwords)
+	r := chi.NewRouter()
+	r.Route("/caldav/{userID}", func(r chi.Router) {
+		r.Use(h.Authenticate)
+		r.Get("/", func(w http.ResponseWriter, _ *http.Request) {
+			w.WriteHeader(http.StatusOK)
+		})
+	})
+
+	tests := []struct {
+		name     string
+		user     string
+		password string
+		want     int
+	}{
+		{name: "owner", user: "1", password: "secret-1", want: http.StatusOK},
+		{name: "no credentials", want: http.StatusUnauthorized},
+		{name: "wrong password", user: "1", password: "wrong", want: http.StatusUnauthorized},
+		{name: "password of another user", user: "1", password: "secret-2", want: http.StatusUnauthorized},
+		{name: "user name of another user", user: "2", password: "secret-2", want: http.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/caldav/1/", nil)
+			if tt.user != "" {
+				req.SetBasicAuth(tt.user, tt.password)
+			}
+			rec := httptest.NewRecorder()
+
+			r.ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.want, rec.Code)
+			if tt.want == http.StatusUnauthorized {
+				assert.Contains(t, rec.Header().Get("WWW-Authenticate"), "Basic")
+			}
+		})
+	}
+}
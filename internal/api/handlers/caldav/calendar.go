@@ -0,0 +1,116 @@
+package caldav
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/avraam311/improved-calendar-service/internal/models"
+	"github.com/avraam311/improved-calendar-service/internal/pkg/ical"
+)
+
+// calendarObjects groups the stored rows of a user into calendar objects:
+// each series or single event followed by its overrides.
+func calendarObjects(events []*models.Event) [][]*models.Event {
+	index := make(map[uint]int)
+	var objects [][]*models.Event
+	for _, e := range events {
+		if e.ParentID == nil {
+			index[e.ID] = len(objects)
+			objects = append(objects, []*models.Event{e})
+		}
+	}
+	for _, e := range events {
+		if e.ParentID == nil {
+			continue
+		}
+		if i, ok := index[*e.ParentID]; ok {
+			objects[i] = append(objects[i], e)
+		}
+	}
+
+	return objects
+}
+
+// calendarObject renders a calendar object. Cancelled occurrences become
+// EXDATEs of the series, modified ones are written as overriding VEVENTs.
+func calendarObject(object []*models.Event) *ical.Component {
+	master := *object[0]
+	master.ExDates = append([]time.Time(nil), master.ExDates...)
+	stamp := lastModified(object)
+
+	var overrides []*ical.Component
+	for _, e := range object[1:] {
+		if e.Cancelled {
+			master.ExDates = append(master.ExDates, *e.RecurrenceID)
+			continue
+		}
+
+		override := *e
+		override.UID = ical.EventUID(object[0])
+		overrides = append(overrides, ical.NewEvent(&override, stamp))
+	}
+
+	cal := ical.NewCalendar()
+	cal.Components = append(cal.Components, ical.NewEvent(&master, stamp))
+	cal.Components = append(cal.Components, overrides...)
+
+	return cal
+}
+
+func lastModified(object []*models.Event) time.Time {
+	var last time.Time
+	for _, e := range object {
+		if e.UpdatedAt.After(last) {
+			last = e.UpdatedAt
+		}
+	}
+
+	return last
+}
+
+// objectETag is a strong entity tag: the rendered object only depends on the
+// stored rows and their modification time.
+func objectETag(object []*models.Event) string {
+	h := sha256.New()
+	for _, e := range object {
+		data, _ := json.Marshal(e)
+		h.Write(data)
+		h.Write([]byte(e.UpdatedAt.UTC().Format(time.RFC3339Nano)))
+	}
+
+	return `"` + hex.EncodeToString(h.Sum(nil)[:16]) + `"`
+}
+
+// collectionTag changes whenever any object of the calendar changes.
+func collectionTag(objects [][]*models.Event) string {
+	etags := make([]string, 0, len(objects))
+	for _, object := range objects {
+		etags = append(etags, objectETag(object))
+	}
+	sort.Strings(etags)
+
+	sum := sha256.Sum256([]byte(strings.Join(etags, ",")))
+	return hex.EncodeToString(sum[:16])
+}
+
+func objectName(object []*models.Event) string {
+	return url.PathEscape(ical.EventUID(object[0])) + ".ics"
+}
+
+func objectUID(name string) (string, bool) {
+	if !strings.HasSuffix(name, ".ics") {
+		return "", false
+	}
+
+	uid, err := url.PathUnescape(strings.TrimSuffix(name, ".ics"))
+	if err != nil || uid == "" {
+		return "", false
+	}
+
+	return uid, true
+}
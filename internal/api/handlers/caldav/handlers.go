@@ -0,0 +1,556 @@
+package caldav
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"net/http"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"go.uber.org/zap"
+
+	"github.com/avraam311/improved-calendar-service/internal/models"
+	"github.com/avraam311/improved-calendar-service/internal/pkg/ical"
+	"github.com/avraam311/improved-calendar-service/internal/pkg/rrule"
+	"github.com/avraam311/improved-calendar-service/internal/pkg/validator"
+	eventR "github.com/avraam311/improved-calendar-service/internal/repository/event"
+	attendeeS "github.com/avraam311/improved-calendar-service/internal/service/attendee"
+	eventS "github.com/avraam311/improved-calendar-service/internal/service/event"
+)
+
+const (
+	// Prefix is the path the CalDAV tree is mounted on.
+	Prefix = "/caldav"
+
+	calendarName  = "calendar"
+	maxObjectSize = 1 << 20
+	contentType   = "text/calendar; charset=utf-8; component=vevent"
+)
+
+// Handler serves a CalDAV subset on top of the event service: one calendar
+// per user at /caldav/{userID}/calendar/ with a VEVENT resource per event.
+type Handler struct {
+	LogsCh       chan *models.Log
+	validator    *validator.GoValidator
+	eventService eventService
+	passwords    passwordService
+}
+
+func NewHandler(logsCh chan *models.Log, v *validator.GoValidator, s eventService, ps passwordService) *Handler {
+	return &Handler{
+		LogsCh:       logsCh,
+		validator:    v,
+		eventService: s,
+		passwords:    ps,
+	}
+}
+
+func (h *Handler) Options(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("DAV", "1, 3, calendar-access")
+	w.Header().Set("Allow", "OPTIONS, GET, PUT, DELETE, PROPFIND, REPORT")
+	w.WriteHeader(http.StatusOK)
+}
+
+// PropfindHome answers on the user's principal, which also is the calendar home.
+func (h *Handler) PropfindHome(w http.ResponseWriter, r *http.Request) {
+	userID, ok := h.userID(w, r)
+	if !ok {
+		return
+	}
+
+	req, ok := h.parseRequest(w, r)
+	if !ok {
+		return
+	}
+
+	responses := []davResponse{h.homeResponse(userID)}
+	if r.Header.Get("Depth") == "1" {
+		objects, err := h.objects(r, userID)
+		if err != nil {
+			h.sendLog("failed to get events", "error", zap.Error(err))
+			h.handleError(w, http.StatusInternalServerError, "internal error")
+			return
+		}
+		responses = append(responses, h.calendarResponse(userID, objects))
+	}
+
+	h.writeMultistatus(w, req, responses)
+}
+
+func (h *Handler) PropfindCalendar(w http.ResponseWriter, r *http.Request) {
+	userID, ok := h.userID(w, r)
+	if !ok {
+		return
+	}
+
+	req, ok := h.parseRequest(w, r)
+	if !ok {
+		return
+	}
+
+	objects, err := h.objects(r, userID)
+	if err != nil {
+		h.sendLog("failed to get events", "error", zap.Error(err))
+		h.handleError(w, http.StatusInternalServerError, "internal error")
+		return
+	}
+
+	responses := []davResponse{h.calendarResponse(userID, objects)}
+	if r.Header.Get("Depth") == "1" {
+		for _, object := range objects {
+			responses = append(responses, h.objectResponse(userID, object, false))
+		}
+	}
+
+	h.writeMultistatus(w, req, responses)
+}
+
+func (h *Handler) PropfindObject(w http.ResponseWriter, r *http.Request) {
+	userID, object, ok := h.object(w, r)
+	if !ok {
+		return
+	}
+
+	req, ok := h.parseRequest(w, r)
+	if !ok {
+		return
+	}
+
+	h.writeMultistatus(w, req, []davResponse{h.objectResponse(userID, object, false)})
+}
+
+// Report handles calendar-multiget and calendar-query. A calendar-query
+// returns the objects with at least one occurrence in its time range.
+func (h *Handler) Report(w http.ResponseWriter, r *http.Request) {
+	userID, ok := h.userID(w, r)
+	if !ok {
+		return
+	}
+
+	req, ok := h.parseRequest(w, r)
+	if !ok {
+		return
+	}
+
+	objects, err := h.objects(r, userID)
+	if err != nil {
+		h.sendLog("failed to get events", "error", zap.Error(err))
+		h.handleError(w, http.StatusInternalServerError, "internal error")
+		return
+	}
+	withData := containsName(req.Props, propCalendarData)
+
+	var responses []davResponse
+	switch req.Name {
+	case reportMultiget:
+		byName := make(map[string][]*models.Event, len(objects))
+		for _, object := range objects {
+			byName[objectName(object)] = object
+		}
+		for _, href := range req.Hrefs {
+			object, found := byName[path.Base(href)]
+			if !found {
+				responses = append(responses, davResponse{Href: href})
+				continue
+			}
+			responses = append(responses, h.objectResponse(userID, object, withData))
+		}
+	case reportQuery:
+		inRange, err := h.objectsInRange(r, userID, req)
+		if err != nil {
+			h.sendLog("failed to get events", "error", zap.Error(err))
+			h.handleError(w, http.StatusInternalServerError, "internal error")
+			return
+		}
+		for _, object := range objects {
+			if inRange == nil || inRange[object[0].ID] {
+				responses = append(responses, h.objectResponse(userID, object, withData))
+			}
+		}
+	default:
+		h.sendLog("unsupported report", "warn", zap.String("report", req.Name.Local))
+		h.handleError(w, http.StatusForbidden, "unsupported report")
+		return
+	}
+
+	h.writeMultistatus(w, req, responses)
+}
+
+func (h *Handler) GetObject(w http.ResponseWriter, r *http.Request) {
+	_, object, ok := h.object(w, r)
+	if !ok {
+		return
+	}
+
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("ETag", objectETag(object))
+	w.WriteHeader(http.StatusOK)
+	err := calendarObject(object).Encode(w)
+	if err != nil {
+		h.sendLog("failed to encode calendar", "error", zap.Error(err))
+	}
+}
+
+// PutObject creates or replaces the event. Overriding VEVENTs in the body are
+// stored as modified single occurrences of the series and replace all of its
+// previous overrides, together with the series in one transaction.
+func (h *Handler) PutObject(w http.ResponseWriter, r *http.Request) {
+	userID, ok := h.userID(w, r)
+	if !ok {
+		return
+	}
+
+	uid, ok := objectUID(chi.URLParam(r, "name"))
+	if !ok {
+		h.handleError(w, http.StatusNotFound, "not found")
+		return
+	}
+
+	cal, err := ical.Decode(http.MaxBytesReader(w, r.Body, maxObjectSize))
+	if err != nil {
+		h.sendLog("invalid calendar", "warn", zap.Error(err))
+		h.handleError(w, http.StatusBadRequest, "invalid calendar")
+		return
+	}
+
+	master, overrides, err := splitObject(cal, uid)
+	if err != nil {
+		h.sendLog("unsupported calendar object", "warn", zap.Error(err))
+		h.handleError(w, http.StatusForbidden, err.Error())
+		return
+	}
+
+	existing, err := h.eventService.GetEventByUID(r.Context(), userID, uid)
+	if err != nil && !errors.Is(err, eventR.ErrEventNotFound) {
+		h.sendLog("failed to get event", "error", zap.Error(err))
+		h.handleError(w, http.StatusInternalServerError, "internal error")
+		return
+	}
+	if !preconditionsMet(r, existing) {
+		h.handleError(w, http.StatusPreconditionFailed, "precondition failed")
+		return
+	}
+
+	var ID uint
+	if existing == nil {
+		master.UserID = userID
+		ID, _, err = h.eventService.CreateSeries(r.Context(), master, overrides)
+	} else {
+		ID, _, err = h.eventService.ReplaceSeries(r.Context(), &models.Event{
+			ID:      existing[0].ID,
+			UserID:  userID,
+			Event:   master.Event,
+			Date:    master.Date,
+			End:     master.End,
+			AllDay:  master.AllDay,
+			TZ:      master.TZ,
+			RRule:   master.RRule,
+			ExDates: master.ExDates,
+		}, overrides)
+		err = h.notified(err)
+	}
+	if err != nil {
+		if errors.Is(err, rrule.ErrInvalidRule) || errors.Is(err, eventS.ErrNotRecurring) ||
+			errors.Is(err, eventS.ErrOccurrenceNotFound) || errors.Is(err, eventS.ErrInvalidEnd) ||
+			errors.Is(err, eventS.ErrInvalidTimeZone) {
+			h.sendLog("unsupported calendar object", "warn", zap.Error(err))
+			h.handleError(w, http.StatusForbidden, "unsupported calendar object")
+			return
+		}
+		if errors.Is(err, eventS.ErrConflict) || errors.Is(err, eventR.ErrEventConflict) {
+			h.sendLog("event conflicts with other events", "warn", zap.Error(err))
+			h.handleError(w, http.StatusConflict, "event conflicts with other events")
+			return
+		}
+
+		h.sendLog("failed to store event", "error", zap.Error(err))
+		h.handleError(w, http.StatusInternalServerError, "internal error")
+		return
+	}
+
+	stored, err := h.eventService.GetEventByUID(r.Context(), userID, uid)
+	if err != nil {
+		h.sendLog("failed to get event", "error", zap.Error(err))
+		h.handleError(w, http.StatusInternalServerError, "internal error")
+		return
+	}
+
+	h.sendLog("event stored via caldav", "info", zap.Uint("id", ID))
+
+	w.Header().Set("ETag", objectETag(stored))
+	if existing == nil {
+		w.WriteHeader(http.StatusCreated)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *Handler) DeleteObject(w http.ResponseWriter, r *http.Request) {
+	_, object, ok := h.object(w, r)
+	if !ok {
+		return
+	}
+	if !preconditionsMet(r, object) {
+		h.handleError(w, http.StatusPreconditionFailed, "precondition failed")
+		return
+	}
+
+	_, err := h.eventService.DeleteEvent(r.Context(), &models.EventDelete{ID: object[0].ID})
+	err = h.notified(err)
+	if err != nil && !errors.Is(err, eventR.ErrEventNotFound) {
+		h.sendLog("failed to delete event", "error", zap.Error(err))
+		h.handleError(w, http.StatusInternalServerError, "internal error")
+		return
+	}
+
+	h.sendLog("event deleted via caldav", "info", zap.Uint("id", object[0].ID))
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// notified drops the error of attendee emails that could not be sent, CalDAV
+// has no way to report them and the change itself is stored.
+func (h *Handler) notified(err error) error {
+	if errors.Is(err, attendeeS.ErrNotSent) {
+		h.sendLog("failed to notify attendees", "warn", zap.Error(err))
+		return nil
+	}
+
+	return err
+}
+
+// splitObject returns the master VEVENT of the object and its overrides.
+func splitObject(cal *ical.Component, uid string) (*models.EventCreate, []*models.Event, error) {
+	var (
+		master    *models.EventCreate
+		overrides []*models.Event
+	)
+	for _, c := range cal.Components {
+		if c.Name != "VEVENT" {
+			continue
+		}
+
+		event, err := ical.ParseEvent(c)
+		if err != nil {
+			return nil, nil, err
+		}
+		if event.UID != uid {
+			return nil, nil, fmt.Errorf("UID %q does not match the resource name", event.UID)
+		}
+
+		recurrenceID := c.Prop("RECURRENCE-ID")
+		if recurrenceID == nil {
+			if master != nil {
+				return nil, nil, errors.New("more than one master VEVENT")
+			}
+			if organizer := c.Prop("ORGANIZER"); organizer != nil {
+				event.Mail = strings.TrimPrefix(strings.ToLower(organizer.Value), "mailto:")
+			}
+			master = event
+			continue
+		}
+
+		at, err := ical.ParseTime(recurrenceID)
+		if err != nil {
+			return nil, nil, fmt.Errorf("RECURRENCE-ID: %w", err)
+		}
+		overrides = append(overrides, &models.Event{
+			Event:        event.Event,
+			Date:         event.Date,
+			End:          event.End,
+			AllDay:       event.AllDay,
+			TZ:           event.TZ,
+			RecurrenceID: &at,
+		})
+	}
+
+	if master == nil {
+		return nil, nil, errors.New("VEVENT without RECURRENCE-ID is required")
+	}
+
+	return master, overrides, nil
+}
+
+func preconditionsMet(r *http.Request, object []*models.Event) bool {
+	if match := r.Header.Get("If-Match"); match != "" {
+		if object == nil || (match != "*" && match != objectETag(object)) {
+			return false
+		}
+	}
+	if r.Header.Get("If-None-Match") == "*" && object != nil {
+		return false
+	}
+
+	return true
+}
+
+func (h *Handler) objects(r *http.Request, userID int) ([][]*models.Event, error) {
+	events, err := h.eventService.GetUserEvents(r.Context(), userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return calendarObjects(events), nil
+}
+
+// objectsInRange returns the IDs of events with occurrences inside the time
+// range of the query, or nil when the query has no time range.
+func (h *Handler) objectsInRange(r *http.Request, userID int, req *davRequest) (map[uint]bool, error) {
+	if req.TimeStart.IsZero() && req.TimeEnd.IsZero() {
+		return nil, nil
+	}
+
+	getEvent := &models.EventGet{UserID: userID, DateFrom: req.TimeStart, DateTo: req.TimeEnd}
+	if getEvent.DateTo.IsZero() {
+		getEvent.DateTo = getEvent.DateFrom.AddDate(100, 0, 0)
+	}
+
+	events, err := h.eventService.GetEvents(r.Context(), getEvent)
+	if err != nil {
+		return nil, err
+	}
+
+	IDs := make(map[uint]bool, len(events))
+	for _, e := range events {
+		IDs[e.ID] = true
+	}
+
+	return IDs, nil
+}
+
+func (h *Handler) object(w http.ResponseWriter, r *http.Request) (int, []*models.Event, bool) {
+	userID, ok := h.userID(w, r)
+	if !ok {
+		return 0, nil, false
+	}
+
+	uid, ok := objectUID(chi.URLParam(r, "name"))
+	if !ok {
+		h.handleError(w, http.StatusNotFound, "not found")
+		return 0, nil, false
+	}
+
+	object, err := h.eventService.GetEventByUID(r.Context(), userID, uid)
+	if err != nil {
+		if errors.Is(err, eventR.ErrEventNotFound) {
+			h.handleError(w, http.StatusNotFound, "not found")
+			return 0, nil, false
+		}
+
+		h.sendLog("failed to get event", "error", zap.Error(err))
+		h.handleError(w, http.StatusInternalServerError, "internal error")
+		return 0, nil, false
+	}
+
+	return userID, object, true
+}
+
+func (h *Handler) homeResponse(userID int) davResponse {
+	home := homeHref(userID)
+	return davResponse{
+		Href: home,
+		Props: map[xml.Name]string{
+			propResourceType:    "<d:collection/><d:principal/>",
+			propDisplayName:     escapeText("User " + strconv.Itoa(userID)),
+			propCurrentUser:     hrefXML(home),
+			propPrincipalURL:    hrefXML(home),
+			propCalendarHomeSet: hrefXML(home),
+		},
+	}
+}
+
+func (h *Handler) calendarResponse(userID int, objects [][]*models.Event) davResponse {
+	return davResponse{
+		Href: calendarHref(userID),
+		Props: map[xml.Name]string{
+			propResourceType:       "<d:collection/><c:calendar/>",
+			propDisplayName:        "Calendar",
+			propCurrentUser:        hrefXML(homeHref(userID)),
+			propOwner:              hrefXML(homeHref(userID)),
+			propSupportedComponent: `<c:comp name="VEVENT"/>`,
+			propGetCTag:            collectionTag(objects),
+		},
+	}
+}
+
+func (h *Handler) objectResponse(userID int, object []*models.Event, withData bool) davResponse {
+	resp := davResponse{
+		Href: calendarHref(userID) + objectName(object),
+		Props: map[xml.Name]string{
+			propResourceType:   "",
+			propGetETag:        escapeText(objectETag(object)),
+			propGetContentType: contentType,
+		},
+	}
+
+	if withData {
+		var buf bytes.Buffer
+		if err := calendarObject(object).Encode(&buf); err != nil {
+			h.sendLog("failed to encode calendar", "error", zap.Error(err))
+		}
+		resp.Props[propCalendarData] = escapeText(buf.String())
+	}
+
+	return resp
+}
+
+func (h *Handler) userID(w http.ResponseWriter, r *http.Request) (int, bool) {
+	userID, err := strconv.Atoi(chi.URLParam(r, "userID"))
+	if err != nil || userID <= 0 {
+		h.handleError(w, http.StatusNotFound, "not found")
+		return 0, false
+	}
+
+	return userID, true
+}
+
+func (h *Handler) parseRequest(w http.ResponseWriter, r *http.Request) (*davRequest, bool) {
+	req, err := parseRequest(http.MaxBytesReader(w, r.Body, maxObjectSize))
+	if err != nil {
+		h.sendLog("failed to parse xml", "warn", zap.Error(err))
+		h.handleError(w, http.StatusBadRequest, "invalid xml")
+		return nil, false
+	}
+
+	return req, true
+}
+
+func (h *Handler) writeMultistatus(w http.ResponseWriter, req *davRequest, responses []davResponse) {
+	if err := writeMultistatus(w, req, responses); err != nil {
+		h.sendLog("failed to write multistatus", "error", zap.Error(err))
+	}
+}
+
+func homeHref(userID int) string {
+	return Prefix + "/" + strconv.Itoa(userID) + "/"
+}
+
+func calendarHref(userID int) string {
+	return homeHref(userID) + calendarName + "/"
+}
+
+func containsName(names []xml.Name, name xml.Name) bool {
+	for _, n := range names {
+		if n == name {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (h *Handler) handleError(w http.ResponseWriter, code int, msg string) {
+	http.Error(w, msg, code)
+}
+
+func (h *Handler) sendLog(msg, level string, field zap.Field) {
+	logEntry := &models.Log{
+		Msg:   msg,
+		Level: level,
+		Field: field,
+	}
+	h.LogsCh <- logEntry
+}
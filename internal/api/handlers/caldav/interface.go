@@ -0,0 +1,24 @@
+package caldav
+
+import (
+	"context"
+
+	"github.com/avraam311/improved-calendar-service/internal/models"
+)
+
+//go:generate mockgen -source=interface.go -destination=../../../mocks/mock_caldav_handlers.go -package=mocks -mock_names=eventService=MockcaldavEventService,passwordService=MockcaldavPasswordService
+type eventService interface {
+	GetEvents(ctx context.Context, eventGet *models.EventGet) ([]*models.Event, error)
+	GetUserEvents(ctx context.Context, userID int) ([]*models.Event, error)
+	GetEventByUID(ctx context.Context, userID int, uid string) ([]*models.Event, error)
+	CreateSeries(ctx context.Context, event *models.EventCreate, overrides []*models.Event) (uint, []*models.Event, error)
+	ReplaceSeries(ctx context.Context, event *models.Event, overrides []*models.Event) (uint, []*models.Event, error)
+	DeleteEvent(ctx context.Context, event *models.EventDelete) (uint, error)
+}
+
+// passwordService issues the CalDAV passwords and resolves them to their owner.
+type passwordService interface {
+	RotatePassword(ctx context.Context, userID int) (string, error)
+	RevokePassword(ctx context.Context, userID int) error
+	ResolvePassword(ctx context.Context, password string) (int, error)
+}
@@ -0,0 +1,213 @@
+package caldav
+
+import (
+	"encoding/xml"
+	"errors"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	nsDAV            = "DAV:"
+	nsCalDAV         = "urn:ietf:params:xml:ns:caldav"
+	nsCalendarServer = "http://calendarserver.org/ns/"
+)
+
+var prefixes = map[string]string{
+	nsDAV:            "d",
+	nsCalDAV:         "c",
+	nsCalendarServer: "cs",
+}
+
+var (
+	propResourceType       = xml.Name{Space: nsDAV, Local: "resourcetype"}
+	propDisplayName        = xml.Name{Space: nsDAV, Local: "displayname"}
+	propGetETag            = xml.Name{Space: nsDAV, Local: "getetag"}
+	propGetContentType     = xml.Name{Space: nsDAV, Local: "getcontenttype"}
+	propCurrentUser        = xml.Name{Space: nsDAV, Local: "current-user-principal"}
+	propPrincipalURL       = xml.Name{Space: nsDAV, Local: "principal-URL"}
+	propOwner              = xml.Name{Space: nsDAV, Local: "owner"}
+	propCalendarHomeSet    = xml.Name{Space: nsCalDAV, Local: "calendar-home-set"}
+	propCalendarData       = xml.Name{Space: nsCalDAV, Local: "calendar-data"}
+	propSupportedComponent = xml.Name{Space: nsCalDAV, Local: "supported-calendar-component-set"}
+	propGetCTag            = xml.Name{Space: nsCalendarServer, Local: "getctag"}
+)
+
+var (
+	reportMultiget = xml.Name{Space: nsCalDAV, Local: "calendar-multiget"}
+	reportQuery    = xml.Name{Space: nsCalDAV, Local: "calendar-query"}
+)
+
+// davRequest is the part of a PROPFIND or REPORT body the server acts upon.
+type davRequest struct {
+	Name      xml.Name
+	Props     []xml.Name
+	AllProps  bool
+	Hrefs     []string
+	TimeStart time.Time
+	TimeEnd   time.Time
+}
+
+// parseRequest walks the XML body collecting the requested properties, the
+// hrefs of a multiget and the time range of a calendar-query. An empty body
+// requests all properties.
+func parseRequest(r io.Reader) (*davRequest, error) {
+	req := &davRequest{}
+	dec := xml.NewDecoder(r)
+
+	var stack []xml.Name
+	for {
+		tok, err := dec.Token()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		switch t := tok.(type) {
+		case xml.StartElement:
+			if len(stack) == 0 {
+				req.Name = t.Name
+			}
+			if len(stack) > 0 && stack[len(stack)-1] == (xml.Name{Space: nsDAV, Local: "prop"}) {
+				req.Props = append(req.Props, t.Name)
+			}
+			switch t.Name {
+			case xml.Name{Space: nsDAV, Local: "allprop"}:
+				req.AllProps = true
+			case xml.Name{Space: nsCalDAV, Local: "time-range"}:
+				for _, attr := range t.Attr {
+					value, err := time.Parse("20060102T150405Z", attr.Value)
+					if err != nil {
+						return nil, err
+					}
+					switch attr.Name.Local {
+					case "start":
+						req.TimeStart = value
+					case "end":
+						req.TimeEnd = value
+					}
+				}
+			}
+			stack = append(stack, t.Name)
+		case xml.EndElement:
+			stack = stack[:len(stack)-1]
+		case xml.CharData:
+			if len(stack) > 0 && stack[len(stack)-1] == (xml.Name{Space: nsDAV, Local: "href"}) {
+				req.Hrefs = append(req.Hrefs, strings.TrimSpace(string(t)))
+			}
+		}
+	}
+
+	if req.Name.Local == "" {
+		req.AllProps = true
+	}
+
+	return req, nil
+}
+
+// davResponse holds the properties of one resource as inner XML. A response
+// without properties reports a missing resource.
+type davResponse struct {
+	Href  string
+	Props map[xml.Name]string
+}
+
+// writeMultistatus writes a 207 response. Properties the resource doesn't
+// have are reported with 404 in a separate propstat.
+func writeMultistatus(w http.ResponseWriter, req *davRequest, responses []davResponse) error {
+	var sb strings.Builder
+	sb.WriteString(xml.Header)
+	sb.WriteString(`<d:multistatus xmlns:d="DAV:" xmlns:c="urn:ietf:params:xml:ns:caldav" xmlns:cs="http://calendarserver.org/ns/">`)
+	for _, resp := range responses {
+		sb.WriteString("<d:response><d:href>")
+		_ = xml.EscapeText(&sb, []byte(resp.Href))
+		sb.WriteString("</d:href>")
+		if resp.Props == nil {
+			sb.WriteString("<d:status>HTTP/1.1 404 Not Found</d:status></d:response>")
+			continue
+		}
+
+		requested := req.Props
+		if req.AllProps {
+			requested = sortedNames(resp.Props)
+		}
+
+		var found, missing []xml.Name
+		for _, name := range requested {
+			if _, ok := resp.Props[name]; ok {
+				found = append(found, name)
+			} else {
+				missing = append(missing, name)
+			}
+		}
+
+		writePropstat(&sb, found, resp.Props, "HTTP/1.1 200 OK")
+		writePropstat(&sb, missing, nil, "HTTP/1.1 404 Not Found")
+		sb.WriteString("</d:response>")
+	}
+	sb.WriteString("</d:multistatus>")
+
+	w.Header().Set("Content-Type", "application/xml; charset=utf-8")
+	w.WriteHeader(http.StatusMultiStatus)
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+func writePropstat(sb *strings.Builder, names []xml.Name, values map[xml.Name]string, status string) {
+	if len(names) == 0 {
+		return
+	}
+
+	sb.WriteString("<d:propstat><d:prop>")
+	for _, name := range names {
+		tag, nsAttr := name.Local, ""
+		if prefix, ok := prefixes[name.Space]; ok {
+			tag = prefix + ":" + name.Local
+		} else if name.Space != "" {
+			tag = "x:" + name.Local
+			nsAttr = ` xmlns:x="` + escapeAttr(name.Space) + `"`
+		}
+
+		value := values[name]
+		if value == "" {
+			sb.WriteString("<" + tag + nsAttr + "/>")
+			continue
+		}
+		sb.WriteString("<" + tag + nsAttr + ">" + value + "</" + tag + ">")
+	}
+	sb.WriteString("</d:prop><d:status>" + status + "</d:status></d:propstat>")
+}
+
+func sortedNames(props map[xml.Name]string) []xml.Name {
+	names := make([]xml.Name, 0, len(props))
+	for name := range props {
+		if name == propCalendarData {
+			continue
+		}
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		return names[i].Space+names[i].Local < names[j].Space+names[j].Local
+	})
+
+	return names
+}
+
+func escapeText(s string) string {
+	var sb strings.Builder
+	_ = xml.EscapeText(&sb, []byte(s))
+	return sb.String()
+}
+
+func escapeAttr(s string) string {
+	return strings.ReplaceAll(escapeText(s), `"`, "&quot;")
+}
+
+func hrefXML(href string) string {
+	return "<d:href>" + escapeText(href) + "</d:href>"
+}
@@ -0,0 +1,49 @@
+package caldav
+
+import (
+	"encoding/xml"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseRequestQuery(t *testing.T) {
+	body := `<?xml version="1.0" encoding="utf-8"?>
+<c:calendar-query xmlns:d="DAV:" xmlns:c="urn:ietf:params:xml:ns:caldav">
+  <d:prop><d:getetag/><c:calendar-data/></d:prop>
+  <c:filter>
+    <c:comp-filter name="VCALENDAR">
+      <c:comp-filter name="VEVENT">
+        <c:time-range start="20250901T000000Z" end="20251001T000000Z"/>
+      </c:comp-filter>
+    </c:comp-filter>
+  </c:filter>
+</c:calendar-query>`
+
+	req, err := parseRequest(strings.NewReader(body))
+	require.NoError(t, err)
+	assert.Equal(t, reportQuery, req.Name)
+	assert.Equal(t, []xml.Name{propGetETag, propCalendarData}, req.Props)
+	assert.Equal(t, time.Date(2025, time.September, 1, 0, 0, 0, 0, time.UTC), req.TimeStart)
+	assert.Equal(t, time.Date(2025, time.October, 1, 0, 0, 0, 0, time.UTC), req.TimeEnd)
+}
+
+func TestParseRequestMultigetAndEmptyBody(t *testing.T) {
+	body := `<c:calendar-multiget xmlns:d="DAV:" xmlns:c="urn:ietf:params:xml:ns:caldav">
+  <d:prop><d:getetag/></d:prop>
+  <d:href>/caldav/1/calendar/a.ics</d:href>
+  <d:href> /caldav/1/calendar/b.ics </d:href>
+</c:calendar-multiget>`
+
+	req, err := parseRequest(strings.NewReader(body))
+	require.NoError(t, err)
+	assert.Equal(t, reportMultiget, req.Name)
+	assert.Equal(t, []string{"/caldav/1/calendar/a.ics", "/caldav/1/calendar/b.ics"}, req.Hrefs)
+
+	req, err = parseRequest(strings.NewReader(""))
+	require.NoError(t, err)
+	assert.True(t, req.AllProps)
+}
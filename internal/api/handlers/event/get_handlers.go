@@ -2,30 +2,58 @@ package event
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
 	"time"
 
 	"go.uber.org/zap"
 
 	"github.com/avraam311/improved-calendar-service/internal/models"
+	"github.com/avraam311/improved-calendar-service/internal/pkg/ical"
+	"github.com/avraam311/improved-calendar-service/internal/pkg/timerange"
 	"github.com/avraam311/improved-calendar-service/internal/pkg/validator"
+	eventS "github.com/avraam311/improved-calendar-service/internal/service/event"
 )
 
 type GetHandler struct {
 	LogsCh       chan *models.Log
 	validator    *validator.GoValidator
 	eventService eventService
+	weekStart    time.Weekday
 }
 
-func NewGetHandler(logsCh chan *models.Log, v *validator.GoValidator, s eventService) *GetHandler {
+// NewGetHandler returns the handlers of the get-requests. Weeks start on
+// weekStart unless a request asks for another day.
+func NewGetHandler(logsCh chan *models.Log, v *validator.GoValidator, s eventService, weekStart time.Weekday) *GetHandler {
 	return &GetHandler{
 		LogsCh:       logsCh,
 		eventService: s,
 		validator:    v,
+		weekStart:    weekStart,
 	}
 }
 
 func (h *GetHandler) GetEventsForDay(w http.ResponseWriter, r *http.Request) {
+	h.getEventsForPeriod(w, r, func(date time.Time, loc *time.Location, _ time.Weekday) (time.Time, time.Time) {
+		return timerange.Day(date, loc)
+	})
+}
+
+func (h *GetHandler) GetEventsForWeek(w http.ResponseWriter, r *http.Request) {
+	h.getEventsForPeriod(w, r, timerange.Week)
+}
+
+func (h *GetHandler) GetEventsForMonth(w http.ResponseWriter, r *http.Request) {
+	h.getEventsForPeriod(w, r, func(date time.Time, loc *time.Location, _ time.Weekday) (time.Time, time.Time) {
+		return timerange.Month(date, loc)
+	})
+}
+
+// getEventsForPeriod returns the events of the calendar period containing the
+// date from the query string. The period is computed in the time zone from
+// the tz parameter, UTC by default, and the events are returned in it.
+func (h *GetHandler) getEventsForPeriod(w http.ResponseWriter, r *http.Request,
+	period func(date time.Time, loc *time.Location, weekStart time.Weekday) (time.Time, time.Time)) {
 	if r.Method != http.MethodGet {
 		h.sendLog("not allowed methods", "warn", zap.String("method", r.Method))
 		h.handleError(w, http.StatusBadRequest, "only method GET allowed")
@@ -47,6 +75,21 @@ func (h *GetHandler) GetEventsForDay(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	loc, ok := h.queryLocation(w, r)
+	if !ok {
+		return
+	}
+
+	weekStart := h.weekStart
+	if weekStartStr := r.URL.Query().Get("week_start"); weekStartStr != "" {
+		weekStart, err = timerange.ParseWeekday(weekStartStr)
+		if err != nil {
+			h.sendLog("invalid week start", "warn", zap.String("week_start", weekStartStr))
+			h.handleError(w, http.StatusBadRequest, "query string \"week_start\" must be monday or sunday")
+			return
+		}
+	}
+
 	dateStr := r.URL.Query().Get("date")
 	if dateStr == "" {
 		h.sendLog("missing date", "warn", zap.String("date", dateStr))
@@ -54,19 +97,18 @@ func (h *GetHandler) GetEventsForDay(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	layout := "2006-01-02T15:04:05Z"
-	dateFrom, err := time.Parse(layout, dateStr)
+	date, err := timerange.ParseDate(dateStr, loc)
 	if err != nil {
 		h.sendLog("failed to parse date", "warn", zap.Error(err))
 		h.handleError(w, http.StatusBadRequest, "invalid data in query string")
 		return
 	}
 
-	dateTo := dateFrom.Add(time.Hour * 24)
+	dateFrom, dateTo := period(date, loc, weekStart)
 	getEvent := &models.EventGet{
 		UserID:   UserID.UserID,
 		DateFrom: dateFrom,
-		DateTo:   dateTo,
+		DateTo:   dateTo.Add(-time.Nanosecond),
 	}
 
 	events, err := h.eventService.GetEvents(r.Context(), getEvent)
@@ -75,6 +117,7 @@ func (h *GetHandler) GetEventsForDay(w http.ResponseWriter, r *http.Request) {
 		h.handleError(w, http.StatusInternalServerError, "internal error")
 		return
 	}
+	inLocation(events, loc)
 
 	h.sendLog("events got", "info", zap.Any("events", events))
 
@@ -91,7 +134,36 @@ func (h *GetHandler) GetEventsForDay(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
-func (h *GetHandler) GetEventsForWeek(w http.ResponseWriter, r *http.Request) {
+// queryLocation returns the time zone from the tz query parameter.
+func (h *GetHandler) queryLocation(w http.ResponseWriter, r *http.Request) (*time.Location, bool) {
+	tz := r.URL.Query().Get("tz")
+	if tz == "" {
+		return time.UTC, true
+	}
+
+	loc, err := time.LoadLocation(tz)
+	if err != nil || tz == "Local" {
+		h.sendLog("unknown time zone", "warn", zap.String("tz", tz))
+		h.handleError(w, http.StatusBadRequest, "unknown time zone")
+		return nil, false
+	}
+
+	return loc, true
+}
+
+// inLocation converts the times of the events into loc.
+func inLocation(events []*models.Event, loc *time.Location) {
+	for _, e := range events {
+		e.Date = e.Date.In(loc)
+		e.End = e.End.In(loc)
+		if e.RecurrenceID != nil {
+			recurrenceID := e.RecurrenceID.In(loc)
+			e.RecurrenceID = &recurrenceID
+		}
+	}
+}
+
+func (h *GetHandler) ExportEvents(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
 		h.sendLog("not allowed methods", "warn", zap.String("method", r.Method))
 		h.handleError(w, http.StatusBadRequest, "only method GET allowed")
@@ -113,26 +185,37 @@ func (h *GetHandler) GetEventsForWeek(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	dateStr := r.URL.Query().Get("date")
-	if dateStr == "" {
-		h.sendLog("missing date", "warn", zap.String("date", dateStr))
-		h.handleError(w, http.StatusBadRequest, "query string \"date\" is empty")
+	dateFromStr := r.URL.Query().Get("date_from")
+	dateToStr := r.URL.Query().Get("date_to")
+	if dateFromStr == "" || dateToStr == "" {
+		h.sendLog("missing date range", "warn", zap.String("date_from", dateFromStr))
+		h.handleError(w, http.StatusBadRequest, "query strings \"date_from\" and \"date_to\" are required")
 		return
 	}
 
-	layout := "2006-01-02T15:04:05Z"
-	dateFrom, err := time.Parse(layout, dateStr)
+	loc, ok := h.queryLocation(w, r)
+	if !ok {
+		return
+	}
+
+	dateFrom, err := timerange.ParseDate(dateFromStr, loc)
 	if err != nil {
 		h.sendLog("failed to parse date", "warn", zap.Error(err))
 		h.handleError(w, http.StatusBadRequest, "invalid data in query string")
 		return
 	}
 
-	dateTo := dateFrom.Add(time.Hour * 24 * 7)
+	dateTo, err := timerange.ParseEnd(dateToStr, loc)
+	if err != nil || !dateTo.After(dateFrom) {
+		h.sendLog("failed to parse date", "warn", zap.String("date_to", dateToStr))
+		h.handleError(w, http.StatusBadRequest, "invalid data in query string")
+		return
+	}
+
 	getEvent := &models.EventGet{
 		UserID:   UserID.UserID,
 		DateFrom: dateFrom,
-		DateTo:   dateTo,
+		DateTo:   dateTo.Add(-time.Nanosecond),
 	}
 
 	events, err := h.eventService.GetEvents(r.Context(), getEvent)
@@ -142,10 +225,103 @@ func (h *GetHandler) GetEventsForWeek(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	h.sendLog("events got", "info", zap.Any("events", events))
+	h.sendLog("events exported", "info", zap.Int("count", len(events)))
 
-	response := map[string][]*models.Event{
-		"result": events,
+	w.Header().Set("Content-Type", "text/calendar; charset=utf-8")
+	w.Header().Set("Content-Disposition", `attachment; filename="events.ics"`)
+	w.WriteHeader(http.StatusOK)
+	err = ical.NewEventsCalendar(events, time.Now()).Encode(w)
+	if err != nil {
+		h.sendLog("failed to encode calendar", "error", zap.Error(err))
+	}
+}
+
+// GetFreeBusy returns the merged busy intervals of the users from the body in
+// the window from the query string, as JSON or, with format=ics, as VFREEBUSY.
+func (h *GetHandler) GetFreeBusy(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		h.sendLog("not allowed methods", "warn", zap.String("method", r.Method))
+		h.handleError(w, http.StatusBadRequest, "only method GET allowed")
+		return
+	}
+
+	var query *models.FreeBusyGet
+	err := json.NewDecoder(r.Body).Decode(&query)
+	if err != nil {
+		h.sendLog("failed to decode JSON", "warn", zap.Error(err))
+		h.handleError(w, http.StatusBadRequest, "invalid json")
+		return
+	}
+
+	err = h.validator.Validate(query)
+	if err != nil {
+		h.sendLog("validation error", "warn", zap.Error(err))
+		h.handleError(w, http.StatusBadRequest, "validation error")
+		return
+	}
+
+	dateFromStr := r.URL.Query().Get("date_from")
+	dateToStr := r.URL.Query().Get("date_to")
+	if dateFromStr == "" || dateToStr == "" {
+		h.sendLog("missing date range", "warn", zap.String("date_from", dateFromStr))
+		h.handleError(w, http.StatusBadRequest, "query strings \"date_from\" and \"date_to\" are required")
+		return
+	}
+
+	format := r.URL.Query().Get("format")
+	if format != "" && format != "json" && format != "ics" {
+		h.sendLog("unknown format", "warn", zap.String("format", format))
+		h.handleError(w, http.StatusBadRequest, "query string \"format\" must be json or ics")
+		return
+	}
+
+	loc, ok := h.queryLocation(w, r)
+	if !ok {
+		return
+	}
+
+	query.DateFrom, err = timerange.ParseDate(dateFromStr, loc)
+	if err != nil {
+		h.sendLog("failed to parse date", "warn", zap.Error(err))
+		h.handleError(w, http.StatusBadRequest, "invalid data in query string")
+		return
+	}
+
+	query.DateTo, err = timerange.ParseDate(dateToStr, loc)
+	if err != nil || !query.DateTo.After(query.DateFrom) {
+		h.sendLog("failed to parse date", "warn", zap.String("date_to", dateToStr))
+		h.handleError(w, http.StatusBadRequest, "invalid data in query string")
+		return
+	}
+
+	freeBusy, err := h.eventService.GetFreeBusy(r.Context(), query)
+	if err != nil {
+		h.sendLog("failed to get free/busy", "error", zap.Error(err))
+		h.handleError(w, http.StatusInternalServerError, "internal error")
+		return
+	}
+
+	h.sendLog("free/busy got", "info", zap.Ints("user_ids", query.UserIDs))
+
+	if format == "ics" {
+		w.Header().Set("Content-Type", "text/calendar; charset=utf-8")
+		w.WriteHeader(http.StatusOK)
+		err = ical.NewFreeBusyCalendar(freeBusy, query.DateFrom, query.DateTo, time.Now()).Encode(w)
+		if err != nil {
+			h.sendLog("failed to encode calendar", "error", zap.Error(err))
+		}
+		return
+	}
+
+	for _, fb := range freeBusy {
+		for i := range fb.Busy {
+			fb.Busy[i].Start = fb.Busy[i].Start.In(loc)
+			fb.Busy[i].End = fb.Busy[i].End.In(loc)
+		}
+	}
+
+	response := map[string][]*models.FreeBusy{
+		"result": freeBusy,
 	}
 
 	w.Header().Set("Content-Type", "application/json")
@@ -157,61 +333,85 @@ func (h *GetHandler) GetEventsForWeek(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
-func (h *GetHandler) GetEventsForMonth(w http.ResponseWriter, r *http.Request) {
+// FindSlots returns ranked slots in the window from the query string when all
+// users from the body are free for the requested duration.
+func (h *GetHandler) FindSlots(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
 		h.sendLog("not allowed methods", "warn", zap.String("method", r.Method))
 		h.handleError(w, http.StatusBadRequest, "only method GET allowed")
 		return
 	}
 
-	var UserID *models.EventGetUserID
-	err := json.NewDecoder(r.Body).Decode(&UserID)
+	var query *models.SlotQuery
+	err := json.NewDecoder(r.Body).Decode(&query)
 	if err != nil {
 		h.sendLog("failed to decode JSON", "warn", zap.Error(err))
 		h.handleError(w, http.StatusBadRequest, "invalid json")
 		return
 	}
 
-	err = h.validator.Validate(UserID)
+	err = h.validator.Validate(query)
 	if err != nil {
 		h.sendLog("validation error", "warn", zap.Error(err))
 		h.handleError(w, http.StatusBadRequest, "validation error")
 		return
 	}
 
-	dateStr := r.URL.Query().Get("date")
-	if dateStr == "" {
-		h.sendLog("missing date", "warn", zap.String("date", dateStr))
-		h.handleError(w, http.StatusBadRequest, "query string \"date\" is empty")
+	dateFromStr := r.URL.Query().Get("date_from")
+	dateToStr := r.URL.Query().Get("date_to")
+	if dateFromStr == "" || dateToStr == "" {
+		h.sendLog("missing date range", "warn", zap.String("date_from", dateFromStr))
+		h.handleError(w, http.StatusBadRequest, "query strings \"date_from\" and \"date_to\" are required")
+		return
+	}
+
+	loc, ok := h.queryLocation(w, r)
+	if !ok {
 		return
 	}
+	query.TZ = loc.String()
 
-	layout := "2006-01-02T15:04:05Z"
-	dateFrom, err := time.Parse(layout, dateStr)
+	query.DateFrom, err = timerange.ParseDate(dateFromStr, loc)
 	if err != nil {
 		h.sendLog("failed to parse date", "warn", zap.Error(err))
 		h.handleError(w, http.StatusBadRequest, "invalid data in query string")
 		return
 	}
 
-	dateTo := dateFrom.Add(time.Hour * 24 * 30)
-	getEvent := &models.EventGet{
-		UserID:   UserID.UserID,
-		DateFrom: dateFrom,
-		DateTo:   dateTo,
+	query.DateTo, err = timerange.ParseDate(dateToStr, loc)
+	if err != nil || !query.DateTo.After(query.DateFrom) {
+		h.sendLog("failed to parse date", "warn", zap.String("date_to", dateToStr))
+		h.handleError(w, http.StatusBadRequest, "invalid data in query string")
+		return
 	}
 
-	events, err := h.eventService.GetEvents(r.Context(), getEvent)
+	slots, err := h.eventService.FindSlots(r.Context(), query)
 	if err != nil {
-		h.sendLog("failed to get events", "error", zap.Error(err))
+		if errors.Is(err, eventS.ErrInvalidWorkingHours) {
+			h.sendLog("invalid working hours", "warn", zap.Error(err))
+			h.handleError(w, http.StatusBadRequest, "working hours must end after they start")
+			return
+		}
+		if errors.Is(err, eventS.ErrSlotWindowTooLong) {
+			h.sendLog("slot search window too long", "warn", zap.Error(err))
+			h.handleError(w, http.StatusBadRequest, "the period must not be longer than 31 days")
+			return
+		}
+
+		h.sendLog("failed to find slots", "error", zap.Error(err))
 		h.handleError(w, http.StatusInternalServerError, "internal error")
 		return
 	}
 
-	h.sendLog("events got", "info", zap.Any("events", events))
+	for i := range slots {
+		slots[i].Start = slots[i].Start.In(loc)
+		slots[i].End = slots[i].End.In(loc)
+	}
 
-	response := map[string][]*models.Event{
-		"result": events,
+	h.sendLog("slots found", "info", zap.Int("count", len(slots)))
+
+	response := map[string][]models.Interval{
+		"result": slots,
 	}
 
 	w.Header().Set("Content-Type", "application/json")
@@ -2,13 +2,18 @@ package event
 
 import (
 	"context"
+	"io"
+
 	"github.com/avraam311/improved-calendar-service/internal/models"
 )
 
 //go:generate mockgen -source=interface.go -destination=../../../mocks/mock_handlers.go -package=mocks
 type eventService interface {
 	GetEvents(ctx context.Context, eventGet *models.EventGet) ([]*models.Event, error)
-	CreateEvent(ctx context.Context, event *models.EventCreate) (uint, error)
-	UpdateEvent(ctx context.Context, event *models.Event) (uint, error)
-	DeleteEvent(ctx context.Context, ID uint) (uint, error)
+	CreateEvent(ctx context.Context, event *models.EventCreate) (uint, []*models.Event, error)
+	UpdateEvent(ctx context.Context, event *models.Event) (uint, []*models.Event, error)
+	DeleteEvent(ctx context.Context, event *models.EventDelete) (uint, error)
+	ImportEvents(ctx context.Context, imp *models.EventImport, r io.Reader) (*models.ImportReport, error)
+	GetFreeBusy(ctx context.Context, query *models.FreeBusyGet) ([]*models.FreeBusy, error)
+	FindSlots(ctx context.Context, query *models.SlotQuery) ([]models.Interval, error)
 }
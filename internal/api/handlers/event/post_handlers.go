@@ -9,8 +9,12 @@ import (
 	"go.uber.org/zap"
 
 	"github.com/avraam311/improved-calendar-service/internal/models"
+	"github.com/avraam311/improved-calendar-service/internal/pkg/ical"
+	"github.com/avraam311/improved-calendar-service/internal/pkg/rrule"
 	"github.com/avraam311/improved-calendar-service/internal/pkg/validator"
 	eventR "github.com/avraam311/improved-calendar-service/internal/repository/event"
+	attendeeS "github.com/avraam311/improved-calendar-service/internal/service/attendee"
+	eventS "github.com/avraam311/improved-calendar-service/internal/service/event"
 )
 
 type PostHandler struct {
@@ -49,8 +53,18 @@ func (h *PostHandler) CreateEvent(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	ID, err := h.eventService.CreateEvent(r.Context(), event)
+	ID, conflicts, err := h.eventService.CreateEvent(r.Context(), event)
 	if err != nil {
+		if h.handleConflict(w, err) {
+			return
+		}
+
+		if code, msg, ok := eventError(err); ok {
+			h.sendLog(msg, "warn", zap.Error(err))
+			h.handleError(w, code, msg)
+			return
+		}
+
 		h.sendLog("failed to create event", "error", zap.Error(err))
 		h.handleError(w, http.StatusInternalServerError, "internal error")
 		return
@@ -58,9 +72,12 @@ func (h *PostHandler) CreateEvent(w http.ResponseWriter, r *http.Request) {
 
 	h.sendLog("event created", "info", zap.Any("event", event))
 
-	response := map[string]uint{
+	response := map[string]any{
 		"result": ID,
 	}
+	if len(conflicts) > 0 {
+		response["conflicts"] = conflicts
+	}
 
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusCreated)
@@ -93,8 +110,18 @@ func (h *PostHandler) UpdateEvent(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	ID, err := h.eventService.UpdateEvent(r.Context(), event)
-	if err != nil {
+	ID, conflicts, err := h.eventService.UpdateEvent(r.Context(), event)
+	if err != nil && !errors.Is(err, attendeeS.ErrNotSent) {
+		if h.handleConflict(w, err) {
+			return
+		}
+
+		if code, msg, ok := eventError(err); ok {
+			h.sendLog(msg, "warn", zap.Error(err))
+			h.handleError(w, code, msg)
+			return
+		}
+
 		if errors.Is(err, eventR.ErrEventNotFound) {
 			h.sendLog("event not found", "warn", zap.String("ID", strconv.FormatUint(uint64(event.ID), 10)))
 			h.handleError(w, http.StatusNotFound, "event not found")
@@ -108,9 +135,16 @@ func (h *PostHandler) UpdateEvent(w http.ResponseWriter, r *http.Request) {
 
 	h.sendLog("event updated", "info", zap.Any("event", event))
 
-	response := map[string]uint{
+	response := map[string]any{
 		"result": ID,
 	}
+	if len(conflicts) > 0 {
+		response["conflicts"] = conflicts
+	}
+	if err != nil {
+		h.sendLog("failed to notify attendees", "warn", zap.Error(err))
+		response["warning"] = "some emails were not sent"
+	}
 
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
@@ -143,8 +177,13 @@ func (h *PostHandler) DeleteEvent(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	ID, err := h.eventService.DeleteEvent(r.Context(), eventID.ID)
-	if err != nil {
+	ID, err := h.eventService.DeleteEvent(r.Context(), &eventID)
+	if err != nil && !errors.Is(err, attendeeS.ErrNotSent) {
+		if code, msg, ok := eventError(err); ok {
+			h.sendLog(msg, "warn", zap.Error(err))
+			h.handleError(w, code, msg)
+			return
+		}
 		if errors.Is(err, eventR.ErrEventNotFound) {
 			h.sendLog("event not found", "warn", zap.String("ID", strconv.FormatUint(uint64(ID), 10)))
 			h.handleError(w, http.StatusNotFound, "event not found")
@@ -158,9 +197,13 @@ func (h *PostHandler) DeleteEvent(w http.ResponseWriter, r *http.Request) {
 
 	h.sendLog("event deleted", "info", zap.Any("event", ID))
 
-	response := map[string]uint{
+	response := map[string]any{
 		"result": ID,
 	}
+	if err != nil {
+		h.sendLog("failed to notify attendees", "warn", zap.Error(err))
+		response["warning"] = "some emails were not sent"
+	}
 
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
@@ -171,6 +214,132 @@ func (h *PostHandler) DeleteEvent(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// maxImportSize limits the size of uploaded calendar files.
+const maxImportSize = 10 << 20
+
+func (h *PostHandler) ImportEvents(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		h.sendLog("not allowed methods", "warn", zap.String("method", r.Method))
+		h.handleError(w, http.StatusBadRequest, "only method POST allowed")
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, maxImportSize)
+	err := r.ParseMultipartForm(maxImportSize)
+	if err != nil {
+		h.sendLog("failed to parse multipart form", "warn", zap.Error(err))
+		h.handleError(w, http.StatusBadRequest, "invalid multipart form")
+		return
+	}
+
+	userID, err := strconv.Atoi(r.FormValue("user_id"))
+	if err != nil {
+		h.sendLog("failed to parse user_id", "warn", zap.Error(err))
+		h.handleError(w, http.StatusBadRequest, "invalid user_id")
+		return
+	}
+
+	imp := &models.EventImport{
+		UserID: userID,
+		Mail:   r.FormValue("mail"),
+	}
+	err = h.validator.Validate(imp)
+	if err != nil {
+		h.sendLog("validation error", "warn", zap.Error(err))
+		h.handleError(w, http.StatusBadRequest, "validation error")
+		return
+	}
+
+	file, _, err := r.FormFile("file")
+	if err != nil {
+		h.sendLog("missing calendar file", "warn", zap.Error(err))
+		h.handleError(w, http.StatusBadRequest, "form field \"file\" is required")
+		return
+	}
+	defer file.Close()
+
+	report, err := h.eventService.ImportEvents(r.Context(), imp, file)
+	if err != nil && report != nil {
+		// The events are stored, only their reminders are missing.
+		h.sendLog("failed to schedule reminders of imported events", "error", zap.Error(err))
+	} else if err != nil {
+		if errors.Is(err, ical.ErrInvalidCalendar) {
+			h.sendLog("invalid calendar", "warn", zap.Error(err))
+			h.handleError(w, http.StatusBadRequest, "invalid calendar")
+			return
+		}
+
+		h.sendLog("failed to import events", "error", zap.Error(err))
+		h.handleError(w, http.StatusInternalServerError, "internal error")
+		return
+	}
+
+	h.sendLog("events imported", "info", zap.Any("report", report))
+
+	response := map[string]*models.ImportReport{
+		"result": report,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	err = json.NewEncoder(w).Encode(response)
+	if err != nil {
+		h.sendLog("failed to encode error response", "error", zap.Error(err))
+		http.Error(w, "error response encoding error", http.StatusInternalServerError)
+	}
+}
+
+// eventError maps errors about invalid event data, recurrence rules and
+// occurrence scopes to a response.
+func eventError(err error) (int, string, bool) {
+	switch {
+	case errors.Is(err, rrule.ErrInvalidRule):
+		return http.StatusBadRequest, "invalid recurrence rule", true
+	case errors.Is(err, eventS.ErrRecurrenceIDRequired):
+		return http.StatusBadRequest, "recurrence_id is required for this scope", true
+	case errors.Is(err, eventS.ErrNotRecurring):
+		return http.StatusBadRequest, "event is not recurring", true
+	case errors.Is(err, eventS.ErrOccurrenceNotFound):
+		return http.StatusNotFound, "occurrence not found", true
+	case errors.Is(err, eventS.ErrInvalidEnd):
+		return http.StatusBadRequest, "event end must not be before its start", true
+	case errors.Is(err, eventS.ErrInvalidTimeZone):
+		return http.StatusBadRequest, "unknown time zone", true
+	}
+
+	return 0, "", false
+}
+
+// handleConflict answers 409 with the conflicting events when the event was
+// rejected for overlapping other events.
+func (h *PostHandler) handleConflict(w http.ResponseWriter, err error) bool {
+	conflicts := []*models.Event{}
+	var conflictErr *eventS.ConflictError
+	switch {
+	case errors.As(err, &conflictErr):
+		conflicts = conflictErr.Conflicts
+	case errors.Is(err, eventR.ErrEventConflict):
+	default:
+		return false
+	}
+
+	h.sendLog("event conflicts with other events", "warn", zap.Error(err))
+
+	errorResponse := map[string]any{
+		"error":     "event conflicts with other events",
+		"conflicts": conflicts,
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusConflict)
+	err = json.NewEncoder(w).Encode(errorResponse)
+	if err != nil {
+		h.sendLog("failed to encode error response", "error", zap.Error(err))
+		http.Error(w, "error response encoding error", http.StatusInternalServerError)
+	}
+
+	return true
+}
+
 func (h *PostHandler) handleError(w http.ResponseWriter, code int, msg string) {
 	errorResponse := map[string]string{
 		"error": msg,
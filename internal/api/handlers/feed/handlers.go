@@ -0,0 +1,244 @@
+package feed
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"go.uber.org/zap"
+
+	"github.com/avraam311/improved-calendar-service/internal/models"
+	"github.com/avraam311/improved-calendar-service/internal/pkg/ical"
+	"github.com/avraam311/improved-calendar-service/internal/pkg/validator"
+	feedR "github.com/avraam311/improved-calendar-service/internal/repository/feed"
+)
+
+type Handler struct {
+	LogsCh       chan *models.Log
+	validator    *validator.GoValidator
+	feedService  feedService
+	eventService eventService
+	pastDays     int
+	futureDays   int
+}
+
+// NewHandler returns handlers for the subscribable feeds. Feeds cover the
+// window from pastDays before today to futureDays after it.
+func NewHandler(logsCh chan *models.Log, v *validator.GoValidator, fs feedService, es eventService, pastDays, futureDays int) *Handler {
+	return &Handler{
+		LogsCh:       logsCh,
+		validator:    v,
+		feedService:  fs,
+		eventService: es,
+		pastDays:     pastDays,
+		futureDays:   futureDays,
+	}
+}
+
+func (h *Handler) GetFeed(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		h.sendLog("not allowed methods", "warn", zap.String("method", r.Method))
+		h.handleError(w, http.StatusBadRequest, "only method GET allowed")
+		return
+	}
+
+	token := chi.URLParam(r, "token")
+	userID, err := h.feedService.ResolveToken(r.Context(), token)
+	if err != nil {
+		if errors.Is(err, feedR.ErrTokenNotFound) {
+			h.sendLog("unknown feed token", "warn", zap.String("path", r.URL.Path))
+			h.handleError(w, http.StatusNotFound, "feed not found")
+			return
+		}
+
+		h.sendLog("failed to resolve feed token", "error", zap.Error(err))
+		h.handleError(w, http.StatusInternalServerError, "internal error")
+		return
+	}
+
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	getEvent := &models.EventGet{
+		UserID:   userID,
+		DateFrom: today.AddDate(0, 0, -h.pastDays),
+		DateTo:   today.AddDate(0, 0, h.futureDays),
+	}
+
+	events, err := h.eventService.GetEvents(r.Context(), getEvent)
+	if err != nil {
+		h.sendLog("failed to get events", "error", zap.Error(err))
+		h.handleError(w, http.StatusInternalServerError, "internal error")
+		return
+	}
+
+	etag, err := eventsETag(events)
+	if err != nil {
+		h.sendLog("failed to compute etag", "error", zap.Error(err))
+		h.handleError(w, http.StatusInternalServerError, "internal error")
+		return
+	}
+
+	w.Header().Set("ETag", etag)
+	w.Header().Set("Cache-Control", "private, no-cache")
+	if matchETag(r.Header.Get("If-None-Match"), etag) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/calendar; charset=utf-8")
+	w.WriteHeader(http.StatusOK)
+	err = ical.NewEventsCalendar(events, time.Now()).Encode(w)
+	if err != nil {
+		h.sendLog("failed to encode calendar", "error", zap.Error(err))
+	}
+}
+
+func (h *Handler) RotateToken(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		h.sendLog("not allowed methods", "warn", zap.String("method", r.Method))
+		h.handleError(w, http.StatusBadRequest, "only method POST allowed")
+		return
+	}
+
+	var UserID *models.EventGetUserID
+	err := json.NewDecoder(r.Body).Decode(&UserID)
+	if err != nil {
+		h.sendLog("failed to decode JSON", "warn", zap.Error(err))
+		h.handleError(w, http.StatusBadRequest, "invalid json")
+		return
+	}
+
+	err = h.validator.Validate(UserID)
+	if err != nil {
+		h.sendLog("validation error", "warn", zap.Error(err))
+		h.handleError(w, http.StatusBadRequest, "validation error")
+		return
+	}
+
+	token, err := h.feedService.RotateToken(r.Context(), UserID.UserID)
+	if err != nil {
+		h.sendLog("failed to rotate feed token", "error", zap.Error(err))
+		h.handleError(w, http.StatusInternalServerError, "internal error")
+		return
+	}
+
+	h.sendLog("feed token rotated", "info", zap.Int("user_id", UserID.UserID))
+
+	response := map[string]map[string]string{
+		"result": {
+			"token": token,
+			"url":   "/feeds/" + token + ".ics",
+		},
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	err = json.NewEncoder(w).Encode(response)
+	if err != nil {
+		h.sendLog("failed to encode error response", "error", zap.Error(err))
+		http.Error(w, "error response encoding error", http.StatusInternalServerError)
+	}
+}
+
+func (h *Handler) RevokeToken(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodDelete {
+		h.sendLog("not allowed methods", "warn", zap.String("method", r.Method))
+		h.handleError(w, http.StatusBadRequest, "only method DELETE allowed")
+		return
+	}
+
+	var UserID *models.EventGetUserID
+	err := json.NewDecoder(r.Body).Decode(&UserID)
+	if err != nil {
+		h.sendLog("failed to decode JSON", "warn", zap.Error(err))
+		h.handleError(w, http.StatusBadRequest, "invalid json")
+		return
+	}
+
+	err = h.validator.Validate(UserID)
+	if err != nil {
+		h.sendLog("validation error", "warn", zap.Error(err))
+		h.handleError(w, http.StatusBadRequest, "validation error")
+		return
+	}
+
+	err = h.feedService.RevokeToken(r.Context(), UserID.UserID)
+	if err != nil {
+		if errors.Is(err, feedR.ErrTokenNotFound) {
+			h.sendLog("feed token not found", "warn", zap.Int("user_id", UserID.UserID))
+			h.handleError(w, http.StatusNotFound, "feed token not found")
+			return
+		}
+
+		h.sendLog("failed to revoke feed token", "error", zap.Error(err))
+		h.handleError(w, http.StatusInternalServerError, "internal error")
+		return
+	}
+
+	h.sendLog("feed token revoked", "info", zap.Int("user_id", UserID.UserID))
+
+	response := map[string]int{
+		"result": UserID.UserID,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	err = json.NewEncoder(w).Encode(response)
+	if err != nil {
+		h.sendLog("failed to encode error response", "error", zap.Error(err))
+		http.Error(w, "error response encoding error", http.StatusInternalServerError)
+	}
+}
+
+// eventsETag returns a weak validator of the feed: the body differs between
+// requests only in DTSTAMP, so equal events mean an equivalent feed.
+func eventsETag(events []*models.Event) (string, error) {
+	data, err := json.Marshal(events)
+	if err != nil {
+		return "", err
+	}
+
+	sum := sha256.Sum256(data)
+	return `W/"` + hex.EncodeToString(sum[:16]) + `"`, nil
+}
+
+func matchETag(ifNoneMatch, etag string) bool {
+	if ifNoneMatch == "" {
+		return false
+	}
+
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == strings.TrimPrefix(etag, "W/") {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (h *Handler) handleError(w http.ResponseWriter, code int, msg string) {
+	errorResponse := map[string]string{
+		"error": msg,
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	err := json.NewEncoder(w).Encode(errorResponse)
+	if err != nil {
+		h.sendLog("failed to encode error response", "error", zap.Error(err))
+		http.Error(w, "error response encoding error", http.StatusInternalServerError)
+	}
+}
+
+func (h *Handler) sendLog(msg, level string, field zap.Field) {
+	logEntry := &models.Log{
+		Msg:   msg,
+		Level: level,
+		Field: field,
+	}
+	h.LogsCh <- logEntry
+}
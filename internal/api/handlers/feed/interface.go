@@ -0,0 +1,18 @@
+package feed
+
+import (
+	"context"
+
+	"github.com/avraam311/improved-calendar-service/internal/models"
+)
+
+//go:generate mockgen -source=interface.go -destination=../../../mocks/mock_feed_handlers.go -package=mocks -mock_names=eventService=MockfeedEventService
+type feedService interface {
+	RotateToken(ctx context.Context, userID int) (string, error)
+	RevokeToken(ctx context.Context, userID int) error
+	ResolveToken(ctx context.Context, token string) (int, error)
+}
+
+type eventService interface {
+	GetEvents(ctx context.Context, eventGet *models.EventGet) ([]*models.Event, error)
+}
@@ -0,0 +1,145 @@
+package reminder
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+
+	"go.uber.org/zap"
+
+	"github.com/avraam311/improved-calendar-service/internal/models"
+	"github.com/avraam311/improved-calendar-service/internal/pkg/validator"
+	reminderR "github.com/avraam311/improved-calendar-service/internal/repository/reminder"
+)
+
+// Handler serves the admin API over the reminders that could not be
+// delivered.
+type Handler struct {
+	LogsCh          chan *models.Log
+	validator       *validator.GoValidator
+	reminderService reminderService
+}
+
+func NewHandler(logsCh chan *models.Log, v *validator.GoValidator, s reminderService) *Handler {
+	return &Handler{
+		LogsCh:          logsCh,
+		validator:       v,
+		reminderService: s,
+	}
+}
+
+// ListDeadLetters accepts an empty body to list the dead letters not replayed
+// yet.
+func (h *Handler) ListDeadLetters(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		h.sendLog("not allowed methods", "warn", zap.String("method", r.Method))
+		h.handleError(w, http.StatusBadRequest, "only method GET allowed")
+		return
+	}
+
+	get := &models.DeadLettersGet{}
+	err := json.NewDecoder(r.Body).Decode(get)
+	if err != nil && !errors.Is(err, io.EOF) {
+		h.sendLog("failed to decode JSON", "warn", zap.Error(err))
+		h.handleError(w, http.StatusBadRequest, "invalid json")
+		return
+	}
+
+	err = h.validator.Validate(get)
+	if err != nil {
+		h.sendLog("validation error", "warn", zap.Error(err))
+		h.handleError(w, http.StatusBadRequest, "validation error")
+		return
+	}
+
+	letters, err := h.reminderService.ListDeadLetters(r.Context(), get)
+	if err != nil {
+		h.sendLog("failed to list dead letters", "error", zap.Error(err))
+		h.handleError(w, http.StatusInternalServerError, "internal error")
+		return
+	}
+
+	response := map[string][]*models.DeadLetter{
+		"result": letters,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	err = json.NewEncoder(w).Encode(response)
+	if err != nil {
+		h.sendLog("failed to encode error response", "error", zap.Error(err))
+		http.Error(w, "error response encoding error", http.StatusInternalServerError)
+	}
+}
+
+func (h *Handler) ReplayDeadLetter(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		h.sendLog("not allowed methods", "warn", zap.String("method", r.Method))
+		h.handleError(w, http.StatusBadRequest, "only method POST allowed")
+		return
+	}
+
+	var replay *models.DeadLetterReplay
+	err := json.NewDecoder(r.Body).Decode(&replay)
+	if err != nil {
+		h.sendLog("failed to decode JSON", "warn", zap.Error(err))
+		h.handleError(w, http.StatusBadRequest, "invalid json")
+		return
+	}
+
+	err = h.validator.Validate(replay)
+	if err != nil {
+		h.sendLog("validation error", "warn", zap.Error(err))
+		h.handleError(w, http.StatusBadRequest, "validation error")
+		return
+	}
+
+	reminderID, err := h.reminderService.ReplayDeadLetter(r.Context(), replay.ID)
+	if errors.Is(err, reminderR.ErrDeadLetterNotFound) {
+		h.sendLog("dead letter not found", "warn", zap.Uint("id", replay.ID))
+		h.handleError(w, http.StatusNotFound, "dead letter not found or already replayed")
+		return
+	}
+	if err != nil {
+		h.sendLog("failed to replay dead letter", "error", zap.Error(err))
+		h.handleError(w, http.StatusInternalServerError, "internal error")
+		return
+	}
+
+	h.sendLog("dead letter replayed", "info", zap.Uint("id", replay.ID))
+
+	response := map[string]uint{
+		"result": reminderID,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	err = json.NewEncoder(w).Encode(response)
+	if err != nil {
+		h.sendLog("failed to encode error response", "error", zap.Error(err))
+		http.Error(w, "error response encoding error", http.StatusInternalServerError)
+	}
+}
+
+func (h *Handler) handleError(w http.ResponseWriter, code int, msg string) {
+	errorResponse := map[string]string{
+		"error": msg,
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	err := json.NewEncoder(w).Encode(errorResponse)
+	if err != nil {
+		h.sendLog("failed to encode error response", "error", zap.Error(err))
+		http.Error(w, "error response encoding error", http.StatusInternalServerError)
+	}
+}
+
+func (h *Handler) sendLog(msg, level string, field zap.Field) {
+	logEntry := &models.Log{
+		Msg:   msg,
+		Level: level,
+		Field: field,
+	}
+	h.LogsCh <- logEntry
+}
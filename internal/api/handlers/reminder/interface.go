@@ -0,0 +1,13 @@
+package reminder
+
+import (
+	"context"
+
+	"github.com/avraam311/improved-calendar-service/internal/models"
+)
+
+//go:generate mockgen -source=interface.go -destination=../../../mocks/mock_reminder_handlers.go -package=mocks
+type reminderService interface {
+	ListDeadLetters(ctx context.Context, get *models.DeadLettersGet) ([]*models.DeadLetter, error)
+	ReplayDeadLetter(ctx context.Context, ID uint) (uint, error)
+}
@@ -0,0 +1,13 @@
+package settings
+
+import (
+	"context"
+
+	"github.com/avraam311/improved-calendar-service/internal/models"
+)
+
+//go:generate mockgen -source=interface.go -destination=../../../mocks/mock_settings_handlers.go -package=mocks
+type settingsService interface {
+	GetSettings(ctx context.Context, userID int) (*models.UserSettings, error)
+	SaveSettings(ctx context.Context, settings *models.UserSettings) error
+}
@@ -9,11 +9,32 @@ import (
 	"github.com/go-chi/cors"
 	"go.uber.org/zap"
 
+	"github.com/avraam311/improved-calendar-service/internal/api/handlers/archive"
+	"github.com/avraam311/improved-calendar-service/internal/api/handlers/attendee"
+	"github.com/avraam311/improved-calendar-service/internal/api/handlers/caldav"
 	"github.com/avraam311/improved-calendar-service/internal/api/handlers/event"
+	"github.com/avraam311/improved-calendar-service/internal/api/handlers/feed"
+	"github.com/avraam311/improved-calendar-service/internal/api/handlers/reminder"
+	"github.com/avraam311/improved-calendar-service/internal/api/handlers/settings"
 	"github.com/avraam311/improved-calendar-service/internal/middlewares"
 )
 
-func NewRouter(eventPostHandler *event.PostHandler, eventGetHandler *event.GetHandler, logger *zap.Logger) http.Handler {
+// Handlers are the HTTP handlers mounted by NewRouter.
+type Handlers struct {
+	EventPost *event.PostHandler
+	EventGet  *event.GetHandler
+	Feed      *feed.Handler
+	CalDAV    *caldav.Handler
+	Settings  *settings.Handler
+	Attendee  *attendee.Handler
+	Reminder  *reminder.Handler
+	Archive   *archive.Handler
+}
+
+func NewRouter(h *Handlers, adminToken string, logger *zap.Logger) http.Handler {
+	chi.RegisterMethod("PROPFIND")
+	chi.RegisterMethod("REPORT")
+
 	r := chi.NewRouter()
 
 	r.Use(middleware.RequestID)
@@ -31,12 +52,51 @@ func NewRouter(eventPostHandler *event.PostHandler, eventGetHandler *event.GetHa
 	r.Use(middlewares.Logger(logger))
 
 	r.Route("/api", func(r chi.Router) {
-		r.Post("/create_event", eventPostHandler.CreateEvent)
-		r.Put("/update_event", eventPostHandler.UpdateEvent)
-		r.Delete("/delete_event", eventPostHandler.DeleteEvent)
-		r.Get("/events_for_day", eventGetHandler.GetEventsForDay)
-		r.Get("/events_for_week", eventGetHandler.GetEventsForWeek)
-		r.Get("/events_for_month", eventGetHandler.GetEventsForMonth)
+		r.Post("/create_event", h.EventPost.CreateEvent)
+		r.Put("/update_event", h.EventPost.UpdateEvent)
+		r.Delete("/delete_event", h.EventPost.DeleteEvent)
+		r.Post("/import_events", h.EventPost.ImportEvents)
+		r.Get("/events_for_day", h.EventGet.GetEventsForDay)
+		r.Get("/events_for_week", h.EventGet.GetEventsForWeek)
+		r.Get("/events_for_month", h.EventGet.GetEventsForMonth)
+		r.Get("/export_events", h.EventGet.ExportEvents)
+		r.Get("/freebusy", h.EventGet.GetFreeBusy)
+		r.Get("/find_slots", h.EventGet.FindSlots)
+		r.Post("/feed_token", h.Feed.RotateToken)
+		r.Delete("/feed_token", h.Feed.RevokeToken)
+		r.Post("/caldav_password", h.CalDAV.RotatePassword)
+		r.Delete("/caldav_password", h.CalDAV.RevokePassword)
+		r.Get("/settings", h.Settings.GetSettings)
+		r.Put("/settings", h.Settings.SaveSettings)
+		r.Post("/invite", h.Attendee.Invite)
+		r.Post("/respond", h.Attendee.Respond)
+		r.Get("/attendees", h.Attendee.GetAttendees)
+		r.Delete("/attendees", h.Attendee.Uninvite)
+		r.Get("/archived_events", h.Archive.GetArchivedEvents)
+		r.Post("/restore_event", h.Archive.RestoreEvent)
+
+		r.Route("/admin", func(r chi.Router) {
+			r.Use(middlewares.AdminToken(adminToken))
+			r.Get("/dead_letters", h.Reminder.ListDeadLetters)
+			r.Post("/dead_letters/replay", h.Reminder.ReplayDeadLetter)
+		})
+	})
+
+	r.Get("/feeds/{token}.ics", h.Feed.GetFeed)
+
+	r.Handle("/.well-known/caldav", http.RedirectHandler(caldav.Prefix+"/", http.StatusMovedPermanently))
+	r.Route(caldav.Prefix, func(r chi.Router) {
+		r.Options("/*", h.CalDAV.Options)
+		r.Route("/{userID}", func(r chi.Router) {
+			r.Use(h.CalDAV.Authenticate)
+			r.MethodFunc("PROPFIND", "/", h.CalDAV.PropfindHome)
+			r.MethodFunc("PROPFIND", "/calendar/", h.CalDAV.PropfindCalendar)
+			r.MethodFunc("REPORT", "/calendar/", h.CalDAV.Report)
+			r.MethodFunc("PROPFIND", "/calendar/{name}", h.CalDAV.PropfindObject)
+			r.Get("/calendar/{name}", h.CalDAV.GetObject)
+			r.Put("/calendar/{name}", h.CalDAV.PutObject)
+			r.Delete("/calendar/{name}", h.CalDAV.DeleteObject)
+		})
 	})
 
 	return r
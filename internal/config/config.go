@@ -4,15 +4,23 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"time"
 
 	"github.com/spf13/viper"
 )
 
 type Config struct {
-	Server   Server   `yaml:"server"`
-	Logger   Logger   `yaml:"logger"`
-	Database Database `yaml:"database"`
-	Mail     Mail     `yaml:"mail"`
+	Server    Server    `yaml:"server"`
+	Logger    Logger    `yaml:"logger"`
+	Database  Database  `yaml:"database"`
+	Mail      Mail      `yaml:"mail"`
+	Feed      Feed      `yaml:"feed"`
+	Calendar  Calendar  `yaml:"calendar"`
+	Notifier  Notifier  `yaml:"notifier"`
+	Channels  Channels  `yaml:"channels"`
+	Templates Templates `yaml:"templates"`
+	Cleaner   Cleaner   `yaml:"cleaner"`
+	Admin     Admin     `yaml:"admin"`
 }
 
 type Server struct {
@@ -42,6 +50,68 @@ type Mail struct {
 	From     string
 }
 
+type Feed struct {
+	PastDays   int `yaml:"pastDays"`
+	FutureDays int `yaml:"futureDays"`
+}
+
+type Calendar struct {
+	WeekStart      string `yaml:"weekStart"`
+	ConflictPolicy string `yaml:"conflictPolicy"`
+}
+
+type Notifier struct {
+	MaxAttempts int           `yaml:"maxAttempts"`
+	RetryBase   time.Duration `yaml:"retryBase"`
+	RetryMax    time.Duration `yaml:"retryMax"`
+}
+
+// Channels configures the notification channels besides email. Telegram and
+// SMS are enabled by their token and gateway URL.
+type Channels struct {
+	Timeout       time.Duration `yaml:"timeout"`
+	WebhookSecret string
+	TelegramURL   string `yaml:"telegramURL"`
+	TelegramToken string
+	SMSURL        string `yaml:"smsURL"`
+	SMSAPIKey     string
+	SMSFrom       string `yaml:"smsFrom"`
+}
+
+// Templates points to a directory with notification templates replacing the
+// built-in ones, laid out as <locale>/<type>.txt.tmpl and .html.tmpl.
+type Templates struct {
+	Dir string `yaml:"dir"`
+}
+
+// Cleaner configures the retention of events. Events are archived on the
+// cron Schedule once they ended MaxAge ago, or the max age of the override
+// for their owner. The events of the users on LegalHold are never archived.
+// In the DryRun the cleaner only logs the events it would archive. Events
+// are archived BatchSize at a time with BatchPause between the batches.
+// Events kept in the archive for ColdAfter are moved to the files in ColdDir,
+// unless it is empty.
+type Cleaner struct {
+	Schedule   string              `yaml:"schedule"`
+	MaxAge     time.Duration       `yaml:"maxAge"`
+	Overrides  []RetentionOverride `yaml:"overrides"`
+	LegalHold  []int               `yaml:"legalHold"`
+	DryRun     bool                `yaml:"dryRun"`
+	BatchSize  int                 `yaml:"batchSize"`
+	BatchPause time.Duration       `yaml:"batchPause"`
+	ColdDir    string              `yaml:"coldDir"`
+	ColdAfter  time.Duration       `yaml:"coldAfter"`
+}
+
+type RetentionOverride struct {
+	UserID int           `yaml:"userID"`
+	MaxAge time.Duration `yaml:"maxAge"`
+}
+
+type Admin struct {
+	Token string
+}
+
 func (c *Config) DatabaseURL() string {
 	return fmt.Sprintf("postgres://%s:%s@%s:%s/%s?sslmode=%s",
 		c.Database.User,
@@ -77,5 +147,11 @@ func MustLoad() *Config {
 	cfg.Mail.User = os.Getenv("SMTP_USER")
 	cfg.Mail.Password = os.Getenv("SMTP_PASSWORD")
 	cfg.Mail.From = os.Getenv("SMTP_FROM")
+
+	cfg.Channels.WebhookSecret = os.Getenv("WEBHOOK_SECRET")
+	cfg.Channels.TelegramToken = os.Getenv("TELEGRAM_TOKEN")
+	cfg.Channels.SMSAPIKey = os.Getenv("SMS_API_KEY")
+
+	cfg.Admin.Token = os.Getenv("ADMIN_TOKEN")
 	return &cfg
 }
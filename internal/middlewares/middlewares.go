@@ -1,7 +1,9 @@
 package middlewares
 
 import (
+	"crypto/subtle"
 	"net/http"
+	"strings"
 	"time"
 
 	"go.uber.org/zap"
@@ -19,3 +21,24 @@ func Logger(logger *zap.Logger) func(handler http.Handler) http.Handler {
 		})
 	}
 }
+
+// AdminToken lets through the requests carrying the token as a bearer
+// token. Without a configured token the admin API is disabled.
+func AdminToken(token string) func(handler http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if token == "" {
+				http.Error(w, "admin api disabled", http.StatusNotFound)
+				return
+			}
+
+			given, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+			if !ok || subtle.ConstantTimeCompare([]byte(given), []byte(token)) != 1 {
+				http.Error(w, "unauthorized", http.StatusUnauthorized)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
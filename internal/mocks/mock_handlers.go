@@ -6,6 +6,7 @@ package mocks
 
 import (
 	context "context"
+	io "io"
 	reflect "reflect"
 
 	models "github.com/avraam311/improved-calendar-service/internal/models"
@@ -36,12 +37,13 @@ func (m *MockeventService) EXPECT() *MockeventServiceMockRecorder {
 }
 
 // CreateEvent mocks base method.
-func (m *MockeventService) CreateEvent(ctx context.Context, event *models.EventCreate) (uint, error) {
+func (m *MockeventService) CreateEvent(ctx context.Context, event *models.EventCreate) (uint, []*models.Event, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "CreateEvent", ctx, event)
 	ret0, _ := ret[0].(uint)
-	ret1, _ := ret[1].(error)
-	return ret0, ret1
+	ret1, _ := ret[1].([]*models.Event)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
 }
 
 // CreateEvent indicates an expected call of CreateEvent.
@@ -51,18 +53,33 @@ func (mr *MockeventServiceMockRecorder) CreateEvent(ctx, event interface{}) *gom
 }
 
 // DeleteEvent mocks base method.
-func (m *MockeventService) DeleteEvent(ctx context.Context, ID uint) (uint, error) {
+func (m *MockeventService) DeleteEvent(ctx context.Context, event *models.EventDelete) (uint, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "DeleteEvent", ctx, ID)
+	ret := m.ctrl.Call(m, "DeleteEvent", ctx, event)
 	ret0, _ := ret[0].(uint)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // DeleteEvent indicates an expected call of DeleteEvent.
-func (mr *MockeventServiceMockRecorder) DeleteEvent(ctx, ID interface{}) *gomock.Call {
+func (mr *MockeventServiceMockRecorder) DeleteEvent(ctx, event interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteEvent", reflect.TypeOf((*MockeventService)(nil).DeleteEvent), ctx, event)
+}
+
+// FindSlots mocks base method.
+func (m *MockeventService) FindSlots(ctx context.Context, query *models.SlotQuery) ([]models.Interval, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindSlots", ctx, query)
+	ret0, _ := ret[0].([]models.Interval)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindSlots indicates an expected call of FindSlots.
+func (mr *MockeventServiceMockRecorder) FindSlots(ctx, query interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteEvent", reflect.TypeOf((*MockeventService)(nil).DeleteEvent), ctx, ID)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindSlots", reflect.TypeOf((*MockeventService)(nil).FindSlots), ctx, query)
 }
 
 // GetEvents mocks base method.
@@ -80,13 +97,44 @@ func (mr *MockeventServiceMockRecorder) GetEvents(ctx, eventGet interface{}) *go
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetEvents", reflect.TypeOf((*MockeventService)(nil).GetEvents), ctx, eventGet)
 }
 
+// GetFreeBusy mocks base method.
+func (m *MockeventService) GetFreeBusy(ctx context.Context, query *models.FreeBusyGet) ([]*models.FreeBusy, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetFreeBusy", ctx, query)
+	ret0, _ := ret[0].([]*models.FreeBusy)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetFreeBusy indicates an expected call of GetFreeBusy.
+func (mr *MockeventServiceMockRecorder) GetFreeBusy(ctx, query interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetFreeBusy", reflect.TypeOf((*MockeventService)(nil).GetFreeBusy), ctx, query)
+}
+
+// ImportEvents mocks base method.
+func (m *MockeventService) ImportEvents(ctx context.Context, imp *models.EventImport, r io.Reader) (*models.ImportReport, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ImportEvents", ctx, imp, r)
+	ret0, _ := ret[0].(*models.ImportReport)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ImportEvents indicates an expected call of ImportEvents.
+func (mr *MockeventServiceMockRecorder) ImportEvents(ctx, imp, r interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ImportEvents", reflect.TypeOf((*MockeventService)(nil).ImportEvents), ctx, imp, r)
+}
+
 // UpdateEvent mocks base method.
-func (m *MockeventService) UpdateEvent(ctx context.Context, event *models.Event) (uint, error) {
+func (m *MockeventService) UpdateEvent(ctx context.Context, event *models.Event) (uint, []*models.Event, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "UpdateEvent", ctx, event)
 	ret0, _ := ret[0].(uint)
-	ret1, _ := ret[1].(error)
-	return ret0, ret1
+	ret1, _ := ret[1].([]*models.Event)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
 }
 
 // UpdateEvent indicates an expected call of UpdateEvent.
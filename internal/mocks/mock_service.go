@@ -7,6 +7,7 @@ package mocks
 import (
 	context "context"
 	reflect "reflect"
+	time "time"
 
 	models "github.com/avraam311/improved-calendar-service/internal/models"
 	gomock "github.com/golang/mock/gomock"
@@ -50,6 +51,21 @@ func (mr *MockeventRepoMockRecorder) CreateEvent(ctx, event interface{}) *gomock
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateEvent", reflect.TypeOf((*MockeventRepo)(nil).CreateEvent), ctx, event)
 }
 
+// CreateSeries mocks base method.
+func (m *MockeventRepo) CreateSeries(ctx context.Context, series *models.EventCreate, overrides []*models.Event) (uint, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateSeries", ctx, series, overrides)
+	ret0, _ := ret[0].(uint)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateSeries indicates an expected call of CreateSeries.
+func (mr *MockeventRepoMockRecorder) CreateSeries(ctx, series, overrides interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateSeries", reflect.TypeOf((*MockeventRepo)(nil).CreateSeries), ctx, series, overrides)
+}
+
 // DeleteEvent mocks base method.
 func (m *MockeventRepo) DeleteEvent(ctx context.Context, ID uint) (uint, error) {
 	m.ctrl.T.Helper()
@@ -65,6 +81,36 @@ func (mr *MockeventRepoMockRecorder) DeleteEvent(ctx, ID interface{}) *gomock.Ca
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteEvent", reflect.TypeOf((*MockeventRepo)(nil).DeleteEvent), ctx, ID)
 }
 
+// GetEvent mocks base method.
+func (m *MockeventRepo) GetEvent(ctx context.Context, ID uint) (*models.Event, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetEvent", ctx, ID)
+	ret0, _ := ret[0].(*models.Event)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetEvent indicates an expected call of GetEvent.
+func (mr *MockeventRepoMockRecorder) GetEvent(ctx, ID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetEvent", reflect.TypeOf((*MockeventRepo)(nil).GetEvent), ctx, ID)
+}
+
+// GetEventByUID mocks base method.
+func (m *MockeventRepo) GetEventByUID(ctx context.Context, userID int, uid string) ([]*models.Event, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetEventByUID", ctx, userID, uid)
+	ret0, _ := ret[0].([]*models.Event)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetEventByUID indicates an expected call of GetEventByUID.
+func (mr *MockeventRepoMockRecorder) GetEventByUID(ctx, userID, uid interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetEventByUID", reflect.TypeOf((*MockeventRepo)(nil).GetEventByUID), ctx, userID, uid)
+}
+
 // GetEvents mocks base method.
 func (m *MockeventRepo) GetEvents(ctx context.Context, eventGet *models.EventGet) ([]*models.Event, error) {
 	m.ctrl.T.Helper()
@@ -80,6 +126,81 @@ func (mr *MockeventRepoMockRecorder) GetEvents(ctx, eventGet interface{}) *gomoc
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetEvents", reflect.TypeOf((*MockeventRepo)(nil).GetEvents), ctx, eventGet)
 }
 
+// GetUserEvents mocks base method.
+func (m *MockeventRepo) GetUserEvents(ctx context.Context, userID int) ([]*models.Event, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserEvents", ctx, userID)
+	ret0, _ := ret[0].([]*models.Event)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserEvents indicates an expected call of GetUserEvents.
+func (mr *MockeventRepoMockRecorder) GetUserEvents(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserEvents", reflect.TypeOf((*MockeventRepo)(nil).GetUserEvents), ctx, userID)
+}
+
+// ImportEvents mocks base method.
+func (m *MockeventRepo) ImportEvents(ctx context.Context, events []*models.EventCreate) ([]*models.ImportItem, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ImportEvents", ctx, events)
+	ret0, _ := ret[0].([]*models.ImportItem)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ImportEvents indicates an expected call of ImportEvents.
+func (mr *MockeventRepoMockRecorder) ImportEvents(ctx, events interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ImportEvents", reflect.TypeOf((*MockeventRepo)(nil).ImportEvents), ctx, events)
+}
+
+// ReplaceSeries mocks base method.
+func (m *MockeventRepo) ReplaceSeries(ctx context.Context, series *models.Event, overrides []*models.Event) (uint, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReplaceSeries", ctx, series, overrides)
+	ret0, _ := ret[0].(uint)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReplaceSeries indicates an expected call of ReplaceSeries.
+func (mr *MockeventRepoMockRecorder) ReplaceSeries(ctx, series, overrides interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReplaceSeries", reflect.TypeOf((*MockeventRepo)(nil).ReplaceSeries), ctx, series, overrides)
+}
+
+// SaveOverride mocks base method.
+func (m *MockeventRepo) SaveOverride(ctx context.Context, override *models.Event) (uint, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveOverride", ctx, override)
+	ret0, _ := ret[0].(uint)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SaveOverride indicates an expected call of SaveOverride.
+func (mr *MockeventRepoMockRecorder) SaveOverride(ctx, override interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveOverride", reflect.TypeOf((*MockeventRepo)(nil).SaveOverride), ctx, override)
+}
+
+// SplitSeries mocks base method.
+func (m *MockeventRepo) SplitSeries(ctx context.Context, ID uint, rule string, from time.Time, next *models.Event) (uint, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SplitSeries", ctx, ID, rule, from, next)
+	ret0, _ := ret[0].(uint)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SplitSeries indicates an expected call of SplitSeries.
+func (mr *MockeventRepoMockRecorder) SplitSeries(ctx, ID, rule, from, next interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SplitSeries", reflect.TypeOf((*MockeventRepo)(nil).SplitSeries), ctx, ID, rule, from, next)
+}
+
 // UpdateEvent mocks base method.
 func (m *MockeventRepo) UpdateEvent(ctx context.Context, event *models.Event) (uint, error) {
 	m.ctrl.T.Helper()
@@ -94,3 +215,174 @@ func (mr *MockeventRepoMockRecorder) UpdateEvent(ctx, event interface{}) *gomock
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateEvent", reflect.TypeOf((*MockeventRepo)(nil).UpdateEvent), ctx, event)
 }
+
+// WithUserLock mocks base method.
+func (m *MockeventRepo) WithUserLock(ctx context.Context, userID int, fn func(context.Context) error) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WithUserLock", ctx, userID, fn)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// WithUserLock indicates an expected call of WithUserLock.
+func (mr *MockeventRepoMockRecorder) WithUserLock(ctx, userID, fn interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WithUserLock", reflect.TypeOf((*MockeventRepo)(nil).WithUserLock), ctx, userID, fn)
+}
+
+// MocksettingsRepo is a mock of settingsRepo interface.
+type MocksettingsRepo struct {
+	ctrl     *gomock.Controller
+	recorder *MocksettingsRepoMockRecorder
+}
+
+// MocksettingsRepoMockRecorder is the mock recorder for MocksettingsRepo.
+type MocksettingsRepoMockRecorder struct {
+	mock *MocksettingsRepo
+}
+
+// NewMocksettingsRepo creates a new mock instance.
+func NewMocksettingsRepo(ctrl *gomock.Controller) *MocksettingsRepo {
+	mock := &MocksettingsRepo{ctrl: ctrl}
+	mock.recorder = &MocksettingsRepoMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MocksettingsRepo) EXPECT() *MocksettingsRepoMockRecorder {
+	return m.recorder
+}
+
+// GetSettings mocks base method.
+func (m *MocksettingsRepo) GetSettings(ctx context.Context, userID int) (*models.UserSettings, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSettings", ctx, userID)
+	ret0, _ := ret[0].(*models.UserSettings)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSettings indicates an expected call of GetSettings.
+func (mr *MocksettingsRepoMockRecorder) GetSettings(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSettings", reflect.TypeOf((*MocksettingsRepo)(nil).GetSettings), ctx, userID)
+}
+
+// MockreminderRepo is a mock of reminderRepo interface.
+type MockreminderRepo struct {
+	ctrl     *gomock.Controller
+	recorder *MockreminderRepoMockRecorder
+}
+
+// MockreminderRepoMockRecorder is the mock recorder for MockreminderRepo.
+type MockreminderRepoMockRecorder struct {
+	mock *MockreminderRepo
+}
+
+// NewMockreminderRepo creates a new mock instance.
+func NewMockreminderRepo(ctrl *gomock.Controller) *MockreminderRepo {
+	mock := &MockreminderRepo{ctrl: ctrl}
+	mock.recorder = &MockreminderRepoMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockreminderRepo) EXPECT() *MockreminderRepoMockRecorder {
+	return m.recorder
+}
+
+// GetSent mocks base method.
+func (m *MockreminderRepo) GetSent(ctx context.Context, eventID uint, since time.Time) ([]*models.Reminder, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSent", ctx, eventID, since)
+	ret0, _ := ret[0].([]*models.Reminder)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSent indicates an expected call of GetSent.
+func (mr *MockreminderRepoMockRecorder) GetSent(ctx, eventID, since interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSent", reflect.TypeOf((*MockreminderRepo)(nil).GetSent), ctx, eventID, since)
+}
+
+// GetUnscheduledEvents mocks base method.
+func (m *MockreminderRepo) GetUnscheduledEvents(ctx context.Context, now time.Time) ([]uint, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUnscheduledEvents", ctx, now)
+	ret0, _ := ret[0].([]uint)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUnscheduledEvents indicates an expected call of GetUnscheduledEvents.
+func (mr *MockreminderRepoMockRecorder) GetUnscheduledEvents(ctx, now interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUnscheduledEvents", reflect.TypeOf((*MockreminderRepo)(nil).GetUnscheduledEvents), ctx, now)
+}
+
+// SaveReminders mocks base method.
+func (m *MockreminderRepo) SaveReminders(ctx context.Context, eventID uint, since time.Time, reminders []*models.Reminder) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveReminders", ctx, eventID, since, reminders)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveReminders indicates an expected call of SaveReminders.
+func (mr *MockreminderRepoMockRecorder) SaveReminders(ctx, eventID, since, reminders interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveReminders", reflect.TypeOf((*MockreminderRepo)(nil).SaveReminders), ctx, eventID, since, reminders)
+}
+
+// MockattendeeNotifier is a mock of attendeeNotifier interface.
+type MockattendeeNotifier struct {
+	ctrl     *gomock.Controller
+	recorder *MockattendeeNotifierMockRecorder
+}
+
+// MockattendeeNotifierMockRecorder is the mock recorder for MockattendeeNotifier.
+type MockattendeeNotifierMockRecorder struct {
+	mock *MockattendeeNotifier
+}
+
+// NewMockattendeeNotifier creates a new mock instance.
+func NewMockattendeeNotifier(ctrl *gomock.Controller) *MockattendeeNotifier {
+	mock := &MockattendeeNotifier{ctrl: ctrl}
+	mock.recorder = &MockattendeeNotifierMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockattendeeNotifier) EXPECT() *MockattendeeNotifierMockRecorder {
+	return m.recorder
+}
+
+// NotifyUpdated mocks base method.
+func (m *MockattendeeNotifier) NotifyUpdated(ctx context.Context, eventID uint) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "NotifyUpdated", ctx, eventID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// NotifyUpdated indicates an expected call of NotifyUpdated.
+func (mr *MockattendeeNotifierMockRecorder) NotifyUpdated(ctx, eventID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "NotifyUpdated", reflect.TypeOf((*MockattendeeNotifier)(nil).NotifyUpdated), ctx, eventID)
+}
+
+// PrepareCancel mocks base method.
+func (m *MockattendeeNotifier) PrepareCancel(ctx context.Context, eventID uint) (func() error, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PrepareCancel", ctx, eventID)
+	ret0, _ := ret[0].(func() error)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PrepareCancel indicates an expected call of PrepareCancel.
+func (mr *MockattendeeNotifierMockRecorder) PrepareCancel(ctx, eventID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PrepareCancel", reflect.TypeOf((*MockattendeeNotifier)(nil).PrepareCancel), ctx, eventID)
+}
@@ -1,27 +1,65 @@
 package models
 
 import (
+	"encoding/json"
 	"time"
 
 	"go.uber.org/zap"
 )
 
+const (
+	ScopeAll       = "all"
+	ScopeThis      = "this"
+	ScopeFollowing = "following"
+)
+
+const (
+	ConflictReject = "reject"
+	ConflictWarn   = "warn"
+)
+
 type EventDelete struct {
-	ID uint `json:"id" validate:"required"`
+	ID           uint       `json:"id" validate:"required"`
+	Scope        string     `json:"scope,omitempty" validate:"omitempty,oneof=all this following"`
+	RecurrenceID *time.Time `json:"recurrence_id,omitempty"`
 }
 
 type EventCreate struct {
-	UserID int       `json:"user_id" validate:"required"`
-	Event  string    `json:"event" validate:"required"`
-	Date   time.Time `json:"date" validate:"required"`
-	Mail   string    `json:"mail" validate:"required"`
+	UserID         int            `json:"user_id" validate:"required"`
+	Event          string         `json:"event" validate:"required"`
+	Date           time.Time      `json:"date" validate:"required"`
+	End            time.Time      `json:"end"`
+	AllDay         bool           `json:"all_day"`
+	TZ             string         `json:"tz,omitempty" validate:"omitempty,timezone"`
+	Mail           string         `json:"mail" validate:"required"`
+	RRule          string         `json:"rrule,omitempty"`
+	ExDates        []time.Time    `json:"exdates,omitempty"`
+	UID            string         `json:"uid,omitempty"`
+	ConflictPolicy string         `json:"conflict_policy,omitempty" validate:"omitempty,oneof=reject warn"`
+	Reminders      []ReminderSpec `json:"reminders,omitempty" validate:"omitempty,max=10,dive"`
+	Urgent         bool           `json:"urgent,omitempty"`
 }
 
 type Event struct {
-	ID     uint      `json:"id" validate:"required"`
-	UserID int       `json:"user_id" validate:"required"`
-	Event  string    `json:"event" validate:"required"`
-	Date   time.Time `json:"date" validate:"required"`
+	ID             uint           `json:"id" validate:"required"`
+	UserID         int            `json:"user_id" validate:"required"`
+	Event          string         `json:"event" validate:"required"`
+	Date           time.Time      `json:"date" validate:"required"`
+	End            time.Time      `json:"end"`
+	AllDay         bool           `json:"all_day"`
+	TZ             string         `json:"tz,omitempty" validate:"omitempty,timezone"`
+	RRule          string         `json:"rrule,omitempty"`
+	ExDates        []time.Time    `json:"exdates,omitempty"`
+	UID            string         `json:"uid,omitempty"`
+	RecurrenceID   *time.Time     `json:"recurrence_id,omitempty"`
+	Scope          string         `json:"scope,omitempty" validate:"omitempty,oneof=all this following"`
+	ConflictPolicy string         `json:"conflict_policy,omitempty" validate:"omitempty,oneof=reject warn"`
+	Reminders      []ReminderSpec `json:"reminders,omitempty" validate:"omitempty,max=10,dive"`
+	Urgent         bool           `json:"urgent,omitempty"`
+	ParentID       *uint          `json:"-"`
+	Cancelled      bool           `json:"-"`
+	UpdatedAt      time.Time      `json:"-"`
+	Mail           string         `json:"-"`
 }
 
 type EventToClean struct {
@@ -29,10 +67,191 @@ type EventToClean struct {
 	UserID    int       `json:"user_id" validate:"required"`
 	Event     string    `json:"event" validate:"required"`
 	Date      time.Time `json:"date" validate:"required"`
+	End       time.Time `json:"end" validate:"required"`
 	Mail      string    `json:"mail" validate:"required"`
 	CreatedAt time.Time `json:"created_at" validate:"required"`
 }
 
+// CountSeries is a series bounded by a COUNT. SeriesEnd is the end of its last
+// occurrence, which the cleaner computes from the rule.
+type CountSeries struct {
+	ID        uint
+	Date      time.Time
+	End       time.Time
+	TZ        string
+	RRule     string
+	SeriesEnd time.Time
+}
+
+// RetentionPolicy selects the events to clean: the ones that ended before
+// Cutoff, or before the cutoff of their owner in Overrides. The events of the
+// users on LegalHold are kept. SeriesEnds holds the ends of COUNT series by ID
+// for series without a stored end, as computed by a dry run that stores none.
+type RetentionPolicy struct {
+	Cutoff     time.Time
+	Overrides  map[int]time.Time
+	LegalHold  []int
+	SeriesEnds map[uint]time.Time
+}
+
+// ArchivedEvent is an event the cleaner moved to the archive. Modified and
+// cancelled occurrences of a series are archived and restored along with it.
+type ArchivedEvent struct {
+	ID         uint      `json:"id"`
+	UserID     int       `json:"user_id"`
+	Event      string    `json:"event"`
+	Date       time.Time `json:"date"`
+	End        time.Time `json:"end"`
+	AllDay     bool      `json:"all_day,omitempty"`
+	TZ         string    `json:"tz,omitempty"`
+	Mail       string    `json:"mail"`
+	RRule      string    `json:"rrule,omitempty"`
+	UID        string    `json:"uid,omitempty"`
+	CreatedAt  time.Time `json:"created_at"`
+	ArchivedAt time.Time `json:"archived_at"`
+}
+
+// ArchiveRecord is a row of the events archive as kept in the cold archive
+// files, with everything needed to import it back.
+type ArchiveRecord struct {
+	ID           uint            `json:"id"`
+	UserID       int             `json:"user_id"`
+	Event        string          `json:"event"`
+	Date         time.Time       `json:"date"`
+	End          time.Time       `json:"end"`
+	AllDay       bool            `json:"all_day,omitempty"`
+	TZ           string          `json:"tz"`
+	Mail         string          `json:"mail"`
+	RRule        string          `json:"rrule,omitempty"`
+	ExDates      []time.Time     `json:"exdates,omitempty"`
+	UID          string          `json:"uid,omitempty"`
+	ParentID     *uint           `json:"parent_id,omitempty"`
+	RecurrenceID *time.Time      `json:"recurrence_id,omitempty"`
+	Cancelled    bool            `json:"cancelled,omitempty"`
+	Exclusive    bool            `json:"exclusive,omitempty"`
+	Reminders    []ReminderSpec  `json:"reminders"`
+	Urgent       bool            `json:"urgent,omitempty"`
+	Attendees    json.RawMessage `json:"attendees,omitempty"`
+	CreatedAt    time.Time       `json:"created_at"`
+	UpdatedAt    time.Time       `json:"updated_at"`
+	ArchivedAt   time.Time       `json:"archived_at"`
+}
+
+// ArchiveKey identifies a row of the events archive. An event restored and
+// archived again keeps its ID, but not the time it was archived at.
+type ArchiveKey struct {
+	ID         uint      `json:"id"`
+	ArchivedAt time.Time `json:"archived_at"`
+}
+
+// ArchiveGet lists the archived events of a user, optionally those whose
+// title contains Query or that intersect the range from DateFrom to DateTo.
+type ArchiveGet struct {
+	UserID   int       `json:"user_id" validate:"required"`
+	Query    string    `json:"query,omitempty" validate:"max=200"`
+	DateFrom time.Time `json:"date_from"`
+	DateTo   time.Time `json:"date_to"`
+	Limit    int       `json:"limit,omitempty" validate:"omitempty,min=1,max=1000"`
+	Offset   int       `json:"offset,omitempty" validate:"min=0"`
+}
+
+// ArchiveRestore moves an archived event of the user back to the calendar.
+type ArchiveRestore struct {
+	ID     uint `json:"id" validate:"required"`
+	UserID int  `json:"user_id" validate:"required"`
+}
+
+const (
+	ReminderPending  = "pending"
+	ReminderSent     = "sent"
+	ReminderMissed   = "missed"
+	ReminderRetry    = "retry"
+	ReminderDead     = "dead"
+	ReminderDeferred = "deferred"
+)
+
+const (
+	ChannelEmail    = "email"
+	ChannelWebhook  = "webhook"
+	ChannelTelegram = "telegram"
+	ChannelSMS      = "sms"
+	ChannelLog      = "log"
+)
+
+// ReminderSpec asks for a reminder Offset minutes before the start of every
+// occurrence of an event. Zero reminds at the start. Without a channel the
+// default channel of the user is used.
+type ReminderSpec struct {
+	Offset  int    `json:"offset" validate:"min=0,max=40320"`
+	Channel string `json:"channel,omitempty" validate:"omitempty,oneof=email webhook telegram sms log"`
+}
+
+// ChannelSettings holds the channel preferences of a user: the channel of
+// the reminders that don't name one and the addresses on the channels other
+// than email, which goes to the mail of the event.
+type ChannelSettings struct {
+	Default  string `json:"default,omitempty" validate:"omitempty,oneof=email webhook telegram sms log"`
+	Webhook  string `json:"webhook,omitempty" validate:"omitempty,http_url"`
+	Telegram string `json:"telegram,omitempty"`
+	SMS      string `json:"sms,omitempty" validate:"omitempty,e164"`
+}
+
+// Notification is a reminder rendered for delivery to an address on a channel.
+type Notification struct {
+	Channel  string
+	To       string
+	Subject  string
+	Text     string
+	HTML     string
+	Reminder *Reminder
+}
+
+// Reminder is a queued notification about the start of an event occurrence,
+// due at FireAt. Failed deliveries and the ones falling into the quiet time
+// of the user are tried again at NextAttemptAt, except for urgent events,
+// which ignore the quiet time.
+type Reminder struct {
+	ID            uint      `json:"id"`
+	EventID       uint      `json:"event_id"`
+	UserID        int       `json:"user_id"`
+	Event         string    `json:"event"`
+	Date          time.Time `json:"date"`
+	Mail          string    `json:"mail"`
+	Offset        int       `json:"offset"`
+	FireAt        time.Time `json:"fire_at"`
+	Channel       string    `json:"channel"`
+	Status        string    `json:"status"`
+	Attempts      int       `json:"attempts"`
+	NextAttemptAt time.Time `json:"next_attempt_at"`
+	LastError     string    `json:"last_error,omitempty"`
+	Urgent        bool      `json:"urgent,omitempty"`
+	// TZ and AllDay come from the event when the reminder is claimed for
+	// sending.
+	TZ     string `json:"-"`
+	AllDay bool   `json:"-"`
+}
+
+// DeadLetter is a reminder whose delivery failed after all retries.
+type DeadLetter struct {
+	ID         uint       `json:"id"`
+	Reminder   *Reminder  `json:"reminder"`
+	Error      string     `json:"error"`
+	Attempts   int        `json:"attempts"`
+	CreatedAt  time.Time  `json:"created_at"`
+	ReplayedAt *time.Time `json:"replayed_at,omitempty"`
+}
+
+// DeadLettersGet lists the dead letters, by default only the ones not
+// replayed yet.
+type DeadLettersGet struct {
+	Limit           int  `json:"limit,omitempty" validate:"omitempty,min=1,max=1000"`
+	IncludeReplayed bool `json:"include_replayed,omitempty"`
+}
+
+type DeadLetterReplay struct {
+	ID uint `json:"id" validate:"required"`
+}
+
 type EventGetUserID struct {
 	UserID int `json:"user_id" validate:"required"`
 }
@@ -43,6 +262,152 @@ type EventGet struct {
 	DateTo   time.Time `json:"date_to"`
 }
 
+// FreeBusyGet asks for the busy time of several users in a window.
+type FreeBusyGet struct {
+	UserIDs  []int     `json:"user_ids" validate:"required,min=1,dive,required"`
+	DateFrom time.Time `json:"date_from"`
+	DateTo   time.Time `json:"date_to"`
+}
+
+type Interval struct {
+	Start time.Time `json:"start"`
+	End   time.Time `json:"end"`
+}
+
+// FreeBusy holds the merged busy intervals of a user without event details.
+type FreeBusy struct {
+	UserID int        `json:"user_id"`
+	Busy   []Interval `json:"busy"`
+}
+
+// SlotQuery asks for times of a meeting of Duration minutes when all users
+// are free. Slots lie within the working hours in TZ, by default 09:00-18:00
+// on weekdays, and start on a grid of Step minutes or right after a busy time.
+type SlotQuery struct {
+	UserIDs         []int     `json:"user_ids" validate:"required,min=1,dive,required"`
+	Duration        int       `json:"duration" validate:"required,min=1"`
+	DateFrom        time.Time `json:"date_from"`
+	DateTo          time.Time `json:"date_to"`
+	TZ              string    `json:"-"`
+	WorkStart       string    `json:"work_start,omitempty" validate:"omitempty,datetime=15:04"`
+	WorkEnd         string    `json:"work_end,omitempty" validate:"omitempty,datetime=15:04"`
+	IncludeWeekends bool      `json:"include_weekends,omitempty"`
+	Step            int       `json:"step,omitempty" validate:"omitempty,min=1"`
+	Limit           int       `json:"limit,omitempty" validate:"omitempty,min=1,max=100"`
+}
+
+const (
+	ImportCreated = "created"
+	ImportUpdated = "updated"
+	ImportSkipped = "skipped"
+	ImportFailed  = "failed"
+)
+
+type EventImport struct {
+	UserID int    `json:"user_id" validate:"required"`
+	Mail   string `json:"mail" validate:"required"`
+}
+
+type ImportItem struct {
+	UID    string `json:"uid,omitempty"`
+	ID     uint   `json:"id,omitempty"`
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+type ImportReport struct {
+	Created int           `json:"created"`
+	Updated int           `json:"updated"`
+	Skipped int           `json:"skipped"`
+	Failed  int           `json:"failed"`
+	Items   []*ImportItem `json:"items"`
+}
+
+const (
+	AttendeeNeedsAction = "needs-action"
+	AttendeeAccepted    = "accepted"
+	AttendeeDeclined    = "declined"
+	AttendeeTentative   = "tentative"
+)
+
+// Attendee is a user or an outside email invited to an event. Outside
+// attendees have no UserID.
+type Attendee struct {
+	ID        uint      `json:"id"`
+	EventID   uint      `json:"event_id"`
+	UserID    int       `json:"user_id,omitempty"`
+	Email     string    `json:"email" validate:"required,email"`
+	Status    string    `json:"status"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// AttendeeInvite is sent by the organiser, the owner of the event.
+type AttendeeInvite struct {
+	EventID   uint        `json:"event_id" validate:"required"`
+	UserID    int         `json:"user_id" validate:"required"`
+	Attendees []*Attendee `json:"attendees" validate:"required,min=1,dive"`
+}
+
+// AttendeeResponse is the answer of an attendee identified by user ID or email.
+type AttendeeResponse struct {
+	EventID uint   `json:"event_id" validate:"required"`
+	UserID  int    `json:"user_id,omitempty"`
+	Email   string `json:"email,omitempty" validate:"required_without=UserID,omitempty,email"`
+	Status  string `json:"status" validate:"required,oneof=needs-action accepted declined tentative"`
+}
+
+// AttendeeRemove is sent by the organiser to uninvite an attendee.
+type AttendeeRemove struct {
+	EventID uint   `json:"event_id" validate:"required"`
+	UserID  int    `json:"user_id" validate:"required"`
+	Email   string `json:"email" validate:"required,email"`
+}
+
+type AttendeesGet struct {
+	EventID uint `json:"event_id" validate:"required"`
+	UserID  int  `json:"user_id" validate:"required"`
+}
+
+const (
+	DigestDaily  = "daily"
+	DigestWeekly = "weekly"
+)
+
+// DigestSettings opts a user in to the agenda digests mailed to Mail at At
+// o'clock in TZ, by default 07:00 UTC. The daily digest lists the events of
+// the day, the weekly one goes out on the first day of the week and lists
+// the events of the week.
+type DigestSettings struct {
+	Daily  bool   `json:"daily,omitempty"`
+	Weekly bool   `json:"weekly,omitempty"`
+	Mail   string `json:"mail,omitempty" validate:"required_if=Daily true,required_if=Weekly true,omitempty,email"`
+	TZ     string `json:"tz,omitempty" validate:"omitempty,timezone"`
+	At     string `json:"at,omitempty" validate:"omitempty,datetime=15:04"`
+}
+
+// QuietSettings holds the times a user doesn't want to be reminded at: the
+// daily quiet hours from Start to End in TZ, which may span midnight, and
+// do-not-disturb periods.
+type QuietSettings struct {
+	TZ    string     `json:"tz,omitempty" validate:"omitempty,timezone"`
+	Start string     `json:"start,omitempty" validate:"required_with=End,omitempty,datetime=15:04"`
+	End   string     `json:"end,omitempty" validate:"required_with=Start,omitempty,datetime=15:04"`
+	DND   []Interval `json:"dnd,omitempty" validate:"omitempty,max=50"`
+}
+
+// UserSettings holds the preferences of a user. Empty fields fall back to the
+// service defaults. Reminders are used for the events created without any,
+// Locale is the language of the notifications sent to the user.
+type UserSettings struct {
+	UserID         int              `json:"user_id" validate:"required"`
+	ConflictPolicy string           `json:"conflict_policy,omitempty" validate:"omitempty,oneof=reject warn"`
+	Locale         string           `json:"locale,omitempty" validate:"omitempty,oneof=en ru"`
+	Reminders      []ReminderSpec   `json:"reminders,omitempty" validate:"omitempty,max=10,dive"`
+	Channels       *ChannelSettings `json:"channels,omitempty"`
+	Digest         *DigestSettings  `json:"digest,omitempty"`
+	Quiet          *QuietSettings   `json:"quiet,omitempty"`
+}
+
 type Log struct {
 	Msg   string
 	Level string
@@ -0,0 +1,327 @@
+// Package coldarchive keeps archived events in gzip-compressed JSON Lines
+// files on local disk, one file per month of archiving, listed in a manifest
+// with the SHA-256 checksum of every file.
+package coldarchive
+
+import (
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/avraam311/improved-calendar-service/internal/models"
+)
+
+// ManifestName is the name of the manifest in the archive directory.
+const ManifestName = "manifest.json"
+
+var (
+	ErrNotInManifest    = errors.New("file is not in the manifest")
+	ErrChecksumMismatch = errors.New("file checksum does not match the manifest")
+	ErrPendingWrite     = errors.New("previous write is not committed")
+)
+
+// Manifest lists the files of the archive. Pending holds the rows of the last
+// write until it is committed, that is until they are deleted from the
+// database.
+type Manifest struct {
+	Files   []*File             `json:"files"`
+	Pending []models.ArchiveKey `json:"pending,omitempty"`
+}
+
+// File describes an archive file. Records counts the events and overrides in
+// it.
+type File struct {
+	Name      string    `json:"name"`
+	Month     string    `json:"month"`
+	Records   int       `json:"records"`
+	Size      int64     `json:"size"`
+	SHA256    string    `json:"sha256"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Writer appends records to the archive files in a directory.
+type Writer struct {
+	dir string
+	mu  sync.Mutex
+}
+
+func NewWriter(dir string) *Writer {
+	return &Writer{
+		dir: dir,
+	}
+}
+
+// FileName returns the name of the archive file for the month.
+func FileName(month time.Time) string {
+	return "events-" + month.UTC().Format("2006-01") + ".jsonl.gz"
+}
+
+// Write appends the records to the files of the months they were archived in
+// and updates the manifest. Each write adds a gzip member to the file, which
+// gzip readers read as one stream. The files are synced before the manifest
+// is replaced, so the records are on disk once Write returns. Whatever a
+// failed write left past the size in the manifest is cut off first.
+//
+// The records stay pending in the manifest until Commit, and Write refuses to
+// write more before, so records left in the database by a crash after Write
+// aren't written twice.
+func (w *Writer) Write(records []*models.ArchiveRecord) error {
+	if len(records) == 0 {
+		return nil
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if err := os.MkdirAll(w.dir, 0o750); err != nil {
+		return fmt.Errorf("coldarchive/Write - %w", err)
+	}
+	manifest, err := ReadManifest(w.dir)
+	if err != nil {
+		return fmt.Errorf("coldarchive/Write - %w", err)
+	}
+	if len(manifest.Pending) > 0 {
+		return ErrPendingWrite
+	}
+
+	byFile := make(map[string][]*models.ArchiveRecord)
+	for _, record := range records {
+		name := FileName(record.ArchivedAt)
+		byFile[name] = append(byFile[name], record)
+	}
+
+	for name, fileRecords := range byFile {
+		file := manifest.file(name)
+		var size int64
+		if file != nil {
+			size = file.Size
+		}
+		if err = appendRecords(filepath.Join(w.dir, name), size, fileRecords); err != nil {
+			return fmt.Errorf("coldarchive/Write - %w", err)
+		}
+
+		if file == nil {
+			file = &File{Name: name, Month: fileRecords[0].ArchivedAt.UTC().Format("2006-01")}
+			manifest.Files = append(manifest.Files, file)
+		}
+		file.Records += len(fileRecords)
+		file.Size, file.SHA256, err = checksum(filepath.Join(w.dir, name))
+		if err != nil {
+			return fmt.Errorf("coldarchive/Write - %w", err)
+		}
+		file.UpdatedAt = time.Now().UTC()
+	}
+
+	manifest.Pending = make([]models.ArchiveKey, 0, len(records))
+	for _, record := range records {
+		manifest.Pending = append(manifest.Pending, models.ArchiveKey{ID: record.ID, ArchivedAt: record.ArchivedAt})
+	}
+	if err = writeManifest(w.dir, manifest); err != nil {
+		return fmt.Errorf("coldarchive/Write - %w", err)
+	}
+
+	return nil
+}
+
+// Pending returns the rows of the last write that isn't committed.
+func (w *Writer) Pending() ([]models.ArchiveKey, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	manifest, err := ReadManifest(w.dir)
+	if err != nil {
+		return nil, fmt.Errorf("coldarchive/Pending - %w", err)
+	}
+
+	return manifest.Pending, nil
+}
+
+// Commit marks the last write done once its rows are deleted from the
+// database.
+func (w *Writer) Commit() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	manifest, err := ReadManifest(w.dir)
+	if err != nil {
+		return fmt.Errorf("coldarchive/Commit - %w", err)
+	}
+	if len(manifest.Pending) == 0 {
+		return nil
+	}
+
+	manifest.Pending = nil
+	if err = writeManifest(w.dir, manifest); err != nil {
+		return fmt.Errorf("coldarchive/Commit - %w", err)
+	}
+
+	return nil
+}
+
+// appendRecords cuts the file to size and appends the records to it.
+func appendRecords(path string, size int64, records []*models.ArchiveRecord) error {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE, 0o640)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	if err = f.Truncate(size); err != nil {
+		return err
+	}
+	if _, err = f.Seek(size, io.SeekStart); err != nil {
+		return err
+	}
+
+	gz := gzip.NewWriter(f)
+	enc := json.NewEncoder(gz)
+	for _, record := range records {
+		if err = enc.Encode(record); err != nil {
+			return err
+		}
+	}
+	if err = gz.Close(); err != nil {
+		return err
+	}
+
+	return f.Sync()
+}
+
+// Read calls fn for every record of the archive file.
+func Read(path string, fn func(*models.ArchiveRecord) error) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("coldarchive/Read - %w", err)
+	}
+	defer f.Close()
+
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		return fmt.Errorf("coldarchive/Read - %w", err)
+	}
+	defer gz.Close()
+
+	dec := json.NewDecoder(gz)
+	for line := 1; ; line++ {
+		var record models.ArchiveRecord
+		err = dec.Decode(&record)
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("coldarchive/Read - record %d: %w", line, err)
+		}
+		if err = fn(&record); err != nil {
+			return err
+		}
+	}
+}
+
+// Verify checks the archive file against the checksum in the manifest of its
+// directory.
+func Verify(path string) error {
+	manifest, err := ReadManifest(filepath.Dir(path))
+	if err != nil {
+		return fmt.Errorf("coldarchive/Verify - %w", err)
+	}
+	file := manifest.file(filepath.Base(path))
+	if file == nil {
+		return ErrNotInManifest
+	}
+
+	_, sum, err := checksum(path)
+	if err != nil {
+		return fmt.Errorf("coldarchive/Verify - %w", err)
+	}
+	if sum != file.SHA256 {
+		return ErrChecksumMismatch
+	}
+
+	return nil
+}
+
+// ReadManifest reads the manifest of the archive directory. A directory
+// without one has an empty manifest.
+func ReadManifest(dir string) (*Manifest, error) {
+	data, err := os.ReadFile(filepath.Join(dir, ManifestName))
+	if errors.Is(err, os.ErrNotExist) {
+		return &Manifest{Files: []*File{}}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var manifest Manifest
+	if err = json.Unmarshal(data, &manifest); err != nil {
+		return nil, fmt.Errorf("invalid manifest: %w", err)
+	}
+
+	return &manifest, nil
+}
+
+// writeManifest replaces the manifest through a temporary file, so readers
+// never see a partly written one.
+func writeManifest(dir string, manifest *Manifest) error {
+	sort.Slice(manifest.Files, func(i, j int) bool {
+		return manifest.Files[i].Name < manifest.Files[j].Name
+	})
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(dir, ManifestName+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err = tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err = tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err = tmp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), filepath.Join(dir, ManifestName))
+}
+
+func (m *Manifest) file(name string) *File {
+	for _, file := range m.Files {
+		if file.Name == name {
+			return file
+		}
+	}
+
+	return nil
+}
+
+func checksum(path string) (int64, string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return 0, "", err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	size, err := io.Copy(h, f)
+	if err != nil {
+		return 0, "", err
+	}
+
+	return size, hex.EncodeToString(h.Sum(nil)), nil
+}
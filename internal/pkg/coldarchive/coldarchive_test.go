@@ -0,0 +1,107 @@
+package coldarchive
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/avraam311/improved-calendar-service/internal/models"
+)
+
+func record(ID uint, archivedAt time.Time) *models.ArchiveRecord {
+	date := time.Date(2025, 3, 1, 9, 0, 0, 0, time.UTC)
+	return &models.ArchiveRecord{ID: ID, UserID: 1, Event: "Review", Date: date, End: date.Add(time.Hour), TZ: "UTC",
+		Mail: "alice@example.com", CreatedAt: date, UpdatedAt: date, ArchivedAt: archivedAt}
+}
+
+func readAll(t *testing.T, path string) []uint {
+	var IDs []uint
+	require.NoError(t, Read(path, func(record *models.ArchiveRecord) error {
+		IDs = append(IDs, record.ID)
+		return nil
+	}))
+	return IDs
+}
+
+func TestWriteRotatesByMonthAndAppends(t *testing.T) {
+	dir := t.TempDir()
+	w := NewWriter(dir)
+	september := time.Date(2025, 9, 30, 23, 0, 0, 0, time.UTC)
+	october := time.Date(2025, 10, 1, 1, 0, 0, 0, time.UTC)
+
+	require.NoError(t, w.Write([]*models.ArchiveRecord{record(1, september), record(2, october)}))
+	require.NoError(t, w.Commit())
+	require.NoError(t, w.Write([]*models.ArchiveRecord{record(3, september)}))
+
+	path := filepath.Join(dir, "events-2025-09.jsonl.gz")
+	assert.Equal(t, []uint{1, 3}, readAll(t, path))
+	assert.Equal(t, []uint{2}, readAll(t, filepath.Join(dir, "events-2025-10.jsonl.gz")))
+
+	manifest, err := ReadManifest(dir)
+	require.NoError(t, err)
+	require.Len(t, manifest.Files, 2)
+	assert.Equal(t, "2025-09", manifest.Files[0].Month)
+	assert.Equal(t, 2, manifest.Files[0].Records)
+	assert.NoError(t, Verify(path))
+}
+
+func TestVerifyDetectsChanges(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, NewWriter(dir).Write([]*models.ArchiveRecord{record(1, time.Now())}))
+
+	path := filepath.Join(dir, FileName(time.Now()))
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0)
+	require.NoError(t, err)
+	_, err = f.Write([]byte("tampered"))
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+
+	assert.ErrorIs(t, Verify(path), ErrChecksumMismatch)
+	assert.ErrorIs(t, Verify(filepath.Join(dir, "events-2000-01.jsonl.gz")), ErrNotInManifest)
+}
+
+func TestWriteKeepsRecordsPendingUntilCommit(t *testing.T) {
+	dir := t.TempDir()
+	w := NewWriter(dir)
+	archivedAt := time.Date(2025, 9, 1, 3, 0, 0, 0, time.UTC)
+
+	require.NoError(t, w.Write([]*models.ArchiveRecord{record(1, archivedAt), record(2, archivedAt)}))
+	pending, err := w.Pending()
+	require.NoError(t, err)
+	assert.Equal(t, []models.ArchiveKey{{ID: 1, ArchivedAt: archivedAt}, {ID: 2, ArchivedAt: archivedAt}}, pending)
+
+	// The rows weren't deleted, the cleaner mustn't write them again.
+	assert.ErrorIs(t, w.Write([]*models.ArchiveRecord{record(1, archivedAt)}), ErrPendingWrite)
+
+	require.NoError(t, w.Commit())
+	pending, err = w.Pending()
+	require.NoError(t, err)
+	assert.Empty(t, pending)
+	require.NoError(t, w.Write([]*models.ArchiveRecord{record(3, archivedAt)}))
+	assert.Equal(t, []uint{1, 2, 3}, readAll(t, filepath.Join(dir, FileName(archivedAt))))
+}
+
+func TestWriteCutsOffFailedWrite(t *testing.T) {
+	dir := t.TempDir()
+	w := NewWriter(dir)
+	archivedAt := time.Date(2025, 9, 1, 3, 0, 0, 0, time.UTC)
+	path := filepath.Join(dir, FileName(archivedAt))
+
+	require.NoError(t, w.Write([]*models.ArchiveRecord{record(1, archivedAt)}))
+	require.NoError(t, w.Commit())
+
+	// A write that crashed before updating the manifest.
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0)
+	require.NoError(t, err)
+	_, err = f.Write([]byte("partial gzip member"))
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+
+	require.NoError(t, w.Write([]*models.ArchiveRecord{record(2, archivedAt)}))
+	assert.Equal(t, []uint{1, 2}, readAll(t, path))
+	assert.NoError(t, Verify(path))
+}
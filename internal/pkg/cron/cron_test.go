@@ -0,0 +1,61 @@
+package cron
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseInvalid(t *testing.T) {
+	for _, s := range []string{
+		"",
+		"* * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * * 13 *",
+		"* * * * 8",
+		"5-1 * * * *",
+		"*/0 * * * *",
+		"a * * * *",
+	} {
+		_, err := Parse(s)
+		assert.ErrorIs(t, err, ErrInvalidSpec, s)
+	}
+}
+
+func TestNext(t *testing.T) {
+	at := time.Date(2025, 9, 1, 9, 30, 15, 0, time.UTC) // Monday
+
+	for spec, want := range map[string]time.Time{
+		"0 3 * * *":       time.Date(2025, 9, 2, 3, 0, 0, 0, time.UTC),
+		"@hourly":         time.Date(2025, 9, 1, 10, 0, 0, 0, time.UTC),
+		"*/15 9-18 * * *": time.Date(2025, 9, 1, 9, 45, 0, 0, time.UTC),
+		"0 0 * * 7":       time.Date(2025, 9, 7, 0, 0, 0, 0, time.UTC),
+		"30 9 * * 1-5":    time.Date(2025, 9, 2, 9, 30, 0, 0, time.UTC),
+		"0 0 1 1 *":       time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC),
+		"0 12 15 * 5":     time.Date(2025, 9, 5, 12, 0, 0, 0, time.UTC),
+		"0 0 29 2 *":      time.Date(2028, 2, 29, 0, 0, 0, 0, time.UTC),
+	} {
+		s, err := Parse(spec)
+		require.NoError(t, err, spec)
+		assert.Equal(t, want, s.Next(at), spec)
+	}
+
+	s, err := Parse("0 0 30 2 *")
+	require.NoError(t, err)
+	assert.True(t, s.Next(at).IsZero())
+}
+
+func TestNextInLocation(t *testing.T) {
+	moscow, err := time.LoadLocation("Europe/Moscow")
+	if err != nil {
+		t.Skipf("no time zone data: %v", err)
+	}
+
+	s, err := Parse("@daily")
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2025, 9, 2, 0, 0, 0, 0, moscow), s.Next(time.Date(2025, 9, 1, 20, 0, 0, 0, time.UTC).In(moscow)))
+}
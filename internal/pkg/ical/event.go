@@ -0,0 +1,267 @@
+package ical
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/avraam311/improved-calendar-service/internal/models"
+)
+
+const (
+	dateTimeLocal = "20060102T150405"
+	dateOnly      = "20060102"
+)
+
+// NewUID returns a random globally unique identifier for a new event.
+func NewUID() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b) + "@" + UIDDomain
+}
+
+// EventUID returns the UID the event is exported with. Events stored before
+// UIDs were introduced fall back to one derived from their ID.
+func EventUID(e *models.Event) string {
+	if e.UID != "" {
+		return e.UID
+	}
+
+	return fmt.Sprintf("%d@%s", e.ID, UIDDomain)
+}
+
+// NewEvent maps an event onto a VEVENT. Expanded occurrences are written with
+// a RECURRENCE-ID instead of the rule of their series. Times of events outside
+// UTC carry the IANA name of their zone in TZID.
+func NewEvent(e *models.Event, stamp time.Time) *Component {
+	vevent := &Component{Name: "VEVENT"}
+	vevent.Add("UID", EventUID(e))
+	vevent.AddTime("DTSTAMP", stamp)
+	addEventTime(vevent, e, "DTSTART", e.Date)
+	if e.End.After(e.Date) {
+		addEventTime(vevent, e, "DTEND", e.End)
+	}
+	vevent.AddText("SUMMARY", e.Event)
+
+	if e.RecurrenceID != nil {
+		addEventTime(vevent, e, "RECURRENCE-ID", *e.RecurrenceID)
+		return vevent
+	}
+
+	if e.RRule != "" {
+		vevent.Add("RRULE", e.RRule)
+		if len(e.ExDates) > 0 {
+			addEventTime(vevent, e, "EXDATE", e.ExDates...)
+		}
+	}
+
+	return vevent
+}
+
+// addEventTime adds a DATE-TIME property, or a DATE one for all-day events,
+// in the time zone of the event.
+func addEventTime(c *Component, e *models.Event, name string, times ...time.Time) {
+	loc := time.UTC
+	if e.TZ != "" && e.TZ != "UTC" {
+		if l, err := time.LoadLocation(e.TZ); err == nil {
+			loc = l
+		}
+	}
+
+	values := make([]string, 0, len(times))
+	for _, t := range times {
+		switch {
+		case e.AllDay:
+			values = append(values, t.In(loc).Format(dateOnly))
+		case loc == time.UTC:
+			values = append(values, FormatTime(t))
+		default:
+			values = append(values, t.In(loc).Format(dateTimeLocal))
+		}
+	}
+
+	var params []Param
+	switch {
+	case e.AllDay:
+		params = append(params, Param{Name: "VALUE", Value: "DATE"})
+	case loc != time.UTC:
+		params = append(params, Param{Name: "TZID", Value: loc.String()})
+	}
+
+	c.Add(name, strings.Join(values, ","), params...)
+}
+
+// NewEventsCalendar wraps the events into a VCALENDAR.
+func NewEventsCalendar(events []*models.Event, stamp time.Time) *Component {
+	cal := NewCalendar()
+	for _, e := range events {
+		cal.Components = append(cal.Components, NewEvent(e, stamp))
+	}
+
+	return cal
+}
+
+// ParseEvent maps a VEVENT onto an event. Only the calendar data is filled,
+// the owner has to be set by the caller.
+func ParseEvent(vevent *Component) (*models.EventCreate, error) {
+	uid := vevent.Prop("UID")
+	if uid == nil || uid.Value == "" {
+		return nil, errors.New("missing UID")
+	}
+
+	dtstart := vevent.Prop("DTSTART")
+	if dtstart == nil {
+		return nil, errors.New("missing DTSTART")
+	}
+	date, err := ParseTime(dtstart)
+	if err != nil {
+		return nil, fmt.Errorf("DTSTART: %w", err)
+	}
+
+	event := &models.EventCreate{
+		UID:    uid.Value,
+		Date:   date,
+		AllDay: isDate(dtstart),
+	}
+	if tzid := dtstart.Param("TZID"); tzid != "" {
+		event.TZ = tzid
+	}
+
+	if dtend := vevent.Prop("DTEND"); dtend != nil {
+		event.End, err = ParseTime(dtend)
+		if err != nil {
+			return nil, fmt.Errorf("DTEND: %w", err)
+		}
+	} else if duration := vevent.Prop("DURATION"); duration != nil {
+		d, err := ParseDuration(duration.Value)
+		if err != nil {
+			return nil, fmt.Errorf("DURATION: %w", err)
+		}
+		event.End = date.Add(d)
+	} else if event.AllDay {
+		event.End = date.AddDate(0, 0, 1)
+	}
+
+	if summary := vevent.Prop("SUMMARY"); summary != nil {
+		event.Event = UnescapeText(summary.Value)
+	}
+	if event.Event == "" {
+		return nil, errors.New("missing SUMMARY")
+	}
+
+	if rules := vevent.PropsNamed("RRULE"); len(rules) > 1 {
+		return nil, errors.New("multiple RRULE properties are not supported")
+	} else if len(rules) == 1 {
+		event.RRule = rules[0].Value
+	}
+
+	for _, exdate := range vevent.PropsNamed("EXDATE") {
+		for _, value := range strings.Split(exdate.Value, ",") {
+			p := exdate
+			p.Value = value
+			t, err := ParseTime(&p)
+			if err != nil {
+				return nil, fmt.Errorf("EXDATE: %w", err)
+			}
+			event.ExDates = append(event.ExDates, t)
+		}
+	}
+
+	return event, nil
+}
+
+// ParseDuration parses a DURATION value such as PT1H30M, P1D or -P2W.
+func ParseDuration(value string) (time.Duration, error) {
+	s := value
+	sign := time.Duration(1)
+	switch {
+	case strings.HasPrefix(s, "-"):
+		sign = -1
+		s = s[1:]
+	case strings.HasPrefix(s, "+"):
+		s = s[1:]
+	}
+	if !strings.HasPrefix(s, "P") || len(s) < 3 {
+		return 0, fmt.Errorf("invalid duration %q", value)
+	}
+	s = s[1:]
+
+	var (
+		d      time.Duration
+		inTime bool
+		num    int
+		digits bool
+	)
+	for _, c := range s {
+		switch {
+		case c >= '0' && c <= '9':
+			num = num*10 + int(c-'0')
+			digits = true
+			continue
+		case c == 'T' && !inTime && !digits:
+			inTime = true
+			continue
+		}
+		if !digits {
+			return 0, fmt.Errorf("invalid duration %q", value)
+		}
+
+		var unit time.Duration
+		switch {
+		case c == 'W' && !inTime:
+			unit = 7 * 24 * time.Hour
+		case c == 'D' && !inTime:
+			unit = 24 * time.Hour
+		case c == 'H' && inTime:
+			unit = time.Hour
+		case c == 'M' && inTime:
+			unit = time.Minute
+		case c == 'S' && inTime:
+			unit = time.Second
+		default:
+			return 0, fmt.Errorf("invalid duration %q", value)
+		}
+		d += time.Duration(num) * unit
+		num, digits = 0, false
+	}
+	if digits {
+		return 0, fmt.Errorf("invalid duration %q", value)
+	}
+
+	return sign * d, nil
+}
+
+func isDate(p *Property) bool {
+	return strings.EqualFold(p.Param("VALUE"), "DATE") || len(p.Value) == len(dateOnly)
+}
+
+// ParseTime parses a DATE or DATE-TIME property value honoring its TZID.
+// Floating times are taken as UTC.
+func ParseTime(p *Property) (time.Time, error) {
+	if isDate(p) {
+		return time.Parse(dateOnly, p.Value)
+	}
+
+	if strings.HasSuffix(p.Value, "Z") {
+		return time.Parse(dateTimeUTC, p.Value)
+	}
+
+	loc := time.UTC
+	if tzid := p.Param("TZID"); tzid != "" {
+		var err error
+		loc, err = time.LoadLocation(tzid)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("unknown time zone %q", tzid)
+		}
+	}
+
+	t, err := time.ParseInLocation(dateTimeLocal, p.Value, loc)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	return t.UTC(), nil
+}
@@ -0,0 +1,29 @@
+package ical
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/avraam311/improved-calendar-service/internal/models"
+)
+
+// NewFreeBusyCalendar returns a VCALENDAR with one VFREEBUSY per user
+// covering [from, to]. Every busy period is written in UTC as its own
+// FREEBUSY property.
+func NewFreeBusyCalendar(freeBusy []*models.FreeBusy, from, to, stamp time.Time) *Component {
+	cal := NewCalendar()
+	for _, fb := range freeBusy {
+		vfreebusy := &Component{Name: "VFREEBUSY"}
+		vfreebusy.Add("UID", fmt.Sprintf("freebusy-%d-%s@%s", fb.UserID, FormatTime(stamp), UIDDomain))
+		vfreebusy.AddTime("DTSTAMP", stamp)
+		vfreebusy.AddTime("DTSTART", from)
+		vfreebusy.AddTime("DTEND", to)
+		vfreebusy.Add("X-USER-ID", fmt.Sprint(fb.UserID))
+		for _, busy := range fb.Busy {
+			vfreebusy.Add("FREEBUSY", FormatTime(busy.Start)+"/"+FormatTime(busy.End), Param{Name: "FBTYPE", Value: "BUSY"})
+		}
+		cal.Components = append(cal.Components, vfreebusy)
+	}
+
+	return cal
+}
@@ -0,0 +1,159 @@
+package ical
+
+import (
+	"bufio"
+	"io"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	ProdID    = "-//improved-calendar-service//EN"
+	UIDDomain = "improved-calendar-service"
+
+	dateTimeUTC = "20060102T150405Z"
+
+	// maxLineOctets is the RFC 5545 limit for a content line without the CRLF.
+	maxLineOctets = 75
+)
+
+type Param struct {
+	Name  string
+	Value string
+}
+
+type Property struct {
+	Name   string
+	Params []Param
+	Value  string
+}
+
+// Param returns the value of the named parameter or an empty string.
+func (p *Property) Param(name string) string {
+	for _, param := range p.Params {
+		if strings.EqualFold(param.Name, name) {
+			return param.Value
+		}
+	}
+
+	return ""
+}
+
+// Component is a calendar object such as VCALENDAR or VEVENT.
+type Component struct {
+	Name       string
+	Props      []Property
+	Components []*Component
+}
+
+// NewCalendar returns an empty VCALENDAR with the mandatory properties set.
+func NewCalendar() *Component {
+	return &Component{
+		Name: "VCALENDAR",
+		Props: []Property{
+			{Name: "VERSION", Value: "2.0"},
+			{Name: "PRODID", Value: ProdID},
+			{Name: "CALSCALE", Value: "GREGORIAN"},
+		},
+	}
+}
+
+func (c *Component) Add(name, value string, params ...Param) {
+	c.Props = append(c.Props, Property{Name: name, Params: params, Value: value})
+}
+
+// AddText adds a TEXT property, escaping its value.
+func (c *Component) AddText(name, value string) {
+	c.Add(name, EscapeText(value))
+}
+
+func (c *Component) AddTime(name string, t time.Time) {
+	c.Add(name, FormatTime(t))
+}
+
+// Prop returns the first property with the given name or nil.
+func (c *Component) Prop(name string) *Property {
+	for i := range c.Props {
+		if strings.EqualFold(c.Props[i].Name, name) {
+			return &c.Props[i]
+		}
+	}
+
+	return nil
+}
+
+// PropsNamed returns every property with the given name.
+func (c *Component) PropsNamed(name string) []Property {
+	var props []Property
+	for _, p := range c.Props {
+		if strings.EqualFold(p.Name, name) {
+			props = append(props, p)
+		}
+	}
+
+	return props
+}
+
+// Encode writes the component in RFC 5545 format with folded CRLF lines.
+func (c *Component) Encode(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	c.encode(bw)
+	return bw.Flush()
+}
+
+func (c *Component) encode(w *bufio.Writer) {
+	writeLine(w, "BEGIN:"+c.Name)
+	for _, p := range c.Props {
+		var sb strings.Builder
+		sb.WriteString(p.Name)
+		for _, param := range p.Params {
+			sb.WriteString(";" + param.Name + "=" + quoteParam(param.Value))
+		}
+		sb.WriteString(":" + p.Value)
+		writeLine(w, sb.String())
+	}
+	for _, child := range c.Components {
+		child.encode(w)
+	}
+	writeLine(w, "END:"+c.Name)
+}
+
+// writeLine folds the line at 75 octets without splitting UTF-8 sequences.
+func writeLine(w *bufio.Writer, line string) {
+	limit := maxLineOctets
+	for len(line) > limit {
+		cut := limit
+		for cut > 0 && !utf8.RuneStart(line[cut]) {
+			cut--
+		}
+		_, _ = w.WriteString(line[:cut] + "\r\n ")
+		line = line[cut:]
+		limit = maxLineOctets - 1
+	}
+	_, _ = w.WriteString(line + "\r\n")
+}
+
+func quoteParam(value string) string {
+	if strings.ContainsAny(value, ":;,") {
+		return `"` + value + `"`
+	}
+
+	return value
+}
+
+// EscapeText escapes a TEXT value.
+func EscapeText(s string) string {
+	return strings.NewReplacer(
+		`\`, `\\`,
+		";", `\;`,
+		",", `\,`,
+		"\r\n", `\n`,
+		"\n", `\n`,
+	).Replace(s)
+}
+
+// FormatTime formats t as a UTC DATE-TIME value.
+func FormatTime(t time.Time) string {
+	return t.UTC().Format(dateTimeUTC)
+}
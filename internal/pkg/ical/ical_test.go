@@ -0,0 +1,173 @@
+package ical
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/avraam311/improved-calendar-service/internal/models"
+)
+
+func TestEncodeEventsCalendar(t *testing.T) {
+	stamp := time.Date(2025, 9, 1, 8, 0, 0, 0, time.UTC)
+	occurrence := time.Date(2025, 9, 2, 9, 0, 0, 0, time.UTC)
+	events := []*models.Event{
+		{ID: 1, UserID: 1, Event: "Review; agenda, notes", Date: time.Date(2025, 9, 1, 12, 0, 0, 0, time.UTC)},
+		{ID: 2, UserID: 1, Event: "Standup", Date: occurrence, RRule: "FREQ=DAILY", RecurrenceID: &occurrence},
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, NewEventsCalendar(events, stamp).Encode(&buf))
+
+	out := buf.String()
+	assert.True(t, strings.HasPrefix(out, "BEGIN:VCALENDAR\r\nVERSION:2.0\r\n"))
+	assert.Contains(t, out, "UID:1@improved-calendar-service\r\n")
+	assert.Contains(t, out, "DTSTAMP:20250901T080000Z\r\n")
+	assert.Contains(t, out, "DTSTART:20250901T120000Z\r\n")
+	assert.Contains(t, out, `SUMMARY:Review\; agenda\, notes`+"\r\n")
+	assert.Contains(t, out, "RECURRENCE-ID:20250902T090000Z\r\n")
+	assert.NotContains(t, out, "RRULE")
+	assert.True(t, strings.HasSuffix(out, "END:VEVENT\r\nEND:VCALENDAR\r\n"))
+}
+
+func TestEncodeFoldsLongLines(t *testing.T) {
+	vevent := &Component{Name: "VEVENT"}
+	vevent.AddText("SUMMARY", strings.Repeat("ё", 60))
+
+	var buf bytes.Buffer
+	require.NoError(t, vevent.Encode(&buf))
+
+	for _, line := range strings.Split(strings.TrimSuffix(buf.String(), "\r\n"), "\r\n") {
+		assert.LessOrEqual(t, len(line), 75)
+	}
+	assert.Contains(t, buf.String(), "\r\n ")
+}
+
+func TestEncodeFreeBusyCalendar(t *testing.T) {
+	from := time.Date(2025, 9, 1, 0, 0, 0, 0, time.UTC)
+	freeBusy := []*models.FreeBusy{
+		{UserID: 1, Busy: []models.Interval{
+			{Start: from.Add(9 * time.Hour), End: from.Add(10 * time.Hour)},
+			{Start: from.Add(14 * time.Hour), End: from.Add(15 * time.Hour)},
+		}},
+		{UserID: 2},
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, NewFreeBusyCalendar(freeBusy, from, from.AddDate(0, 0, 1), from).Encode(&buf))
+
+	out := buf.String()
+	assert.Equal(t, 2, strings.Count(out, "BEGIN:VFREEBUSY\r\n"))
+	assert.Contains(t, out, "DTSTART:20250901T000000Z\r\nDTEND:20250902T000000Z\r\n")
+	assert.Contains(t, out, "X-USER-ID:1\r\n")
+	assert.Contains(t, out, "FREEBUSY;FBTYPE=BUSY:20250901T090000Z/20250901T100000Z\r\n")
+	assert.Contains(t, out, "FREEBUSY;FBTYPE=BUSY:20250901T140000Z/20250901T150000Z\r\n")
+	assert.Equal(t, 2, strings.Count(out, "FREEBUSY;"))
+}
+
+func TestDecodeEvents(t *testing.T) {
+	data := "BEGIN:VCALENDAR\r\n" +
+		"VERSION:2.0\r\n" +
+		"BEGIN:VEVENT\r\n" +
+		"UID:abc@example.com\r\n" +
+		"DTSTART;TZID=Europe/Moscow:20250901T090000\r\n" +
+		"SUMMARY:Planning\\, Q4\r\n" +
+		" review\r\n" +
+		"RRULE:FREQ=WEEKLY;BYDAY=MO\r\n" +
+		"EXDATE;VALUE=DATE:20250908,20250915\r\n" +
+		"END:VEVENT\r\n" +
+		"END:VCALENDAR\r\n"
+
+	cal, err := Decode(strings.NewReader(data))
+	require.NoError(t, err)
+	require.Len(t, cal.Components, 1)
+
+	event, err := ParseEvent(cal.Components[0])
+	require.NoError(t, err)
+	assert.Equal(t, "abc@example.com", event.UID)
+	assert.Equal(t, "Planning, Q4review", event.Event)
+	assert.Equal(t, time.Date(2025, 9, 1, 6, 0, 0, 0, time.UTC), event.Date)
+	assert.Equal(t, "FREQ=WEEKLY;BYDAY=MO", event.RRule)
+	assert.Len(t, event.ExDates, 2)
+}
+
+func TestEventSpan(t *testing.T) {
+	data := "BEGIN:VCALENDAR\r\n" +
+		"BEGIN:VEVENT\r\n" +
+		"UID:meeting@example.com\r\n" +
+		"DTSTART:20250901T090000Z\r\n" +
+		"DURATION:PT1H30M\r\n" +
+		"SUMMARY:Meeting\r\n" +
+		"END:VEVENT\r\n" +
+		"BEGIN:VEVENT\r\n" +
+		"UID:holiday@example.com\r\n" +
+		"DTSTART;VALUE=DATE:20251231\r\n" +
+		"DTEND;VALUE=DATE:20260102\r\n" +
+		"SUMMARY:Holidays\r\n" +
+		"END:VEVENT\r\n" +
+		"END:VCALENDAR\r\n"
+
+	cal, err := Decode(strings.NewReader(data))
+	require.NoError(t, err)
+	require.Len(t, cal.Components, 2)
+
+	meeting, err := ParseEvent(cal.Components[0])
+	require.NoError(t, err)
+	assert.False(t, meeting.AllDay)
+	assert.Equal(t, time.Date(2025, 9, 1, 10, 30, 0, 0, time.UTC), meeting.End)
+
+	holiday, err := ParseEvent(cal.Components[1])
+	require.NoError(t, err)
+	assert.True(t, holiday.AllDay)
+	assert.Equal(t, time.Date(2026, 1, 2, 0, 0, 0, 0, time.UTC), holiday.End)
+
+	var buf bytes.Buffer
+	event := &models.Event{ID: 1, Event: holiday.Event, Date: holiday.Date, End: holiday.End, AllDay: true}
+	require.NoError(t, NewEvent(event, holiday.Date).Encode(&buf))
+	assert.Contains(t, buf.String(), "DTSTART;VALUE=DATE:20251231\r\n")
+	assert.Contains(t, buf.String(), "DTEND;VALUE=DATE:20260102\r\n")
+
+	_, err = ParseDuration("PT1H2D")
+	assert.Error(t, err)
+}
+
+func TestDecodeInvalid(t *testing.T) {
+	for _, data := range []string{
+		"",
+		"BEGIN:VCALENDAR\r\nBEGIN:VEVENT\r\nEND:VCALENDAR\r\n",
+		"SUMMARY:outside\r\n",
+	} {
+		_, err := Decode(strings.NewReader(data))
+		assert.ErrorIs(t, err, ErrInvalidCalendar)
+	}
+}
+
+func TestNewInvitation(t *testing.T) {
+	stamp := time.Date(2025, 9, 1, 8, 0, 0, 0, time.UTC)
+	event := &models.Event{ID: 7, UserID: 1, Event: "Planning", Date: time.Date(2025, 9, 3, 10, 0, 0, 0, time.UTC),
+		UpdatedAt: time.Unix(1756713600, 0)}
+	attendees := []*models.Attendee{{Email: "bob@example.com", Status: models.AttendeeNeedsAction}}
+
+	var buf bytes.Buffer
+	require.NoError(t, NewInvitation(MethodRequest, event, "alice@example.com", attendees, stamp).Encode(&buf))
+
+	out := strings.ReplaceAll(buf.String(), "\r\n ", "")
+	assert.Contains(t, out, "METHOD:REQUEST\r\n")
+	assert.Contains(t, out, "UID:7@improved-calendar-service\r\n")
+	assert.Contains(t, out, "SEQUENCE:1756713600\r\n")
+	assert.Contains(t, out, "ORGANIZER:mailto:alice@example.com\r\n")
+	assert.Contains(t, out, "ATTENDEE;ROLE=REQ-PARTICIPANT;PARTSTAT=NEEDS-ACTION;RSVP=TRUE:mailto:bob@example.com\r\n")
+	assert.NotContains(t, out, "STATUS:CANCELLED")
+
+	buf.Reset()
+	require.NoError(t, NewCancellation(event, "alice@example.com", attendees, stamp).Encode(&buf))
+
+	out = strings.ReplaceAll(buf.String(), "\r\n ", "")
+	assert.Contains(t, out, "METHOD:CANCEL\r\n")
+	assert.Contains(t, out, "STATUS:CANCELLED\r\n")
+	assert.NotContains(t, out, "RSVP=TRUE")
+}
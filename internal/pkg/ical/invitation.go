@@ -0,0 +1,60 @@
+package ical
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/avraam311/improved-calendar-service/internal/models"
+)
+
+// iTIP methods of the invitation emails.
+const (
+	MethodRequest = "REQUEST"
+	MethodReply   = "REPLY"
+	MethodCancel  = "CANCEL"
+)
+
+// NewInvitation returns the iTIP calendar of an invitation to the event with
+// its organiser and attendees. SEQUENCE grows with every update of the event,
+// so clients replace older copies of the invitation. A CANCEL built by
+// NewInvitation uninvites the listed attendees only.
+func NewInvitation(method string, e *models.Event, organizer string, attendees []*models.Attendee, stamp time.Time) *Component {
+	cal := NewCalendar()
+	cal.Add("METHOD", method)
+
+	vevent := NewEvent(e, stamp)
+	vevent.Add("SEQUENCE", fmt.Sprint(sequence(e)))
+	vevent.Add("ORGANIZER", "mailto:"+organizer)
+	for _, a := range attendees {
+		params := []Param{
+			{Name: "ROLE", Value: "REQ-PARTICIPANT"},
+			{Name: "PARTSTAT", Value: strings.ToUpper(a.Status)},
+		}
+		if method == MethodRequest {
+			params = append(params, Param{Name: "RSVP", Value: "TRUE"})
+		}
+		vevent.Add("ATTENDEE", "mailto:"+a.Email, params...)
+	}
+	cal.Components = append(cal.Components, vevent)
+
+	return cal
+}
+
+// NewCancellation returns the iTIP calendar cancelling the whole event.
+func NewCancellation(e *models.Event, organizer string, attendees []*models.Attendee, stamp time.Time) *Component {
+	cal := NewInvitation(MethodCancel, e, organizer, attendees, stamp)
+	cal.Components[0].Add("STATUS", "CANCELLED")
+
+	return cal
+}
+
+// sequence derives the revision of the event from its last update, in
+// seconds so that it fits the 32-bit integer clients expect.
+func sequence(e *models.Event) int64 {
+	if e.UpdatedAt.IsZero() {
+		return 0
+	}
+
+	return e.UpdatedAt.Unix()
+}
@@ -0,0 +1,82 @@
+package sender
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/avraam311/improved-calendar-service/internal/models"
+)
+
+var (
+	ErrUnknownChannel = errors.New("unknown notification channel")
+	ErrNoAddress      = errors.New("no address for notification channel")
+)
+
+// Channel delivers notifications to the addresses of one kind, e.g. email
+// addresses or webhook URLs.
+type Channel interface {
+	Notify(ctx context.Context, n *models.Notification) error
+}
+
+// Registry dispatches notifications to the channel they are meant for.
+type Registry struct {
+	channels map[string]Channel
+}
+
+func NewRegistry() *Registry {
+	return &Registry{
+		channels: make(map[string]Channel),
+	}
+}
+
+// Register adds the channel under the name, replacing a channel registered
+// before.
+func (r *Registry) Register(name string, c Channel) {
+	r.channels[name] = c
+}
+
+// Send delivers the notification over its channel.
+func (r *Registry) Send(ctx context.Context, n *models.Notification) error {
+	c, ok := r.channels[n.Channel]
+	if !ok {
+		return fmt.Errorf("%w: %q", ErrUnknownChannel, n.Channel)
+	}
+
+	return c.Notify(ctx, n)
+}
+
+// postJSON posts the body as JSON and fails on a non-2xx response, quoting
+// the start of the response body.
+func postJSON(ctx context.Context, client *http.Client, url string, body any, header http.Header) error {
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	for key, values := range header {
+		req.Header[key] = values
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("unexpected status %s: %s", resp.Status, bytes.TrimSpace(msg))
+	}
+
+	return nil
+}
@@ -0,0 +1,150 @@
+package sender
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+
+	"github.com/avraam311/improved-calendar-service/internal/models"
+)
+
+func testNotification(channel, to string) *models.Notification {
+	return &models.Notification{
+		Channel: channel,
+		To:      to,
+		Subject: "Notifying about event",
+		Text:    "You have event planned at 2025-09-01 10:00 UTC: Review",
+		Reminder: &models.Reminder{EventID: 7, UserID: 1, Event: "Review",
+			Date: time.Date(2025, 9, 1, 10, 0, 0, 0, time.UTC), Offset: 60},
+	}
+}
+
+func TestWebhookSignsPayload(t *testing.T) {
+	var body []byte
+	var signature string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ = io.ReadAll(r.Body)
+		signature = r.Header.Get(SignatureHeader)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+
+	err := NewWebhook(srv.Client(), "secret").Notify(context.Background(), testNotification(models.ChannelWebhook, srv.URL))
+	require.NoError(t, err)
+
+	var payload map[string]any
+	require.NoError(t, json.Unmarshal(body, &payload))
+	assert.Equal(t, "Review", payload["event"])
+	assert.Equal(t, float64(7), payload["event_id"])
+
+	mac := hmac.New(sha256.New, []byte("secret"))
+	mac.Write(body)
+	assert.Equal(t, hex.EncodeToString(mac.Sum(nil)), signature)
+}
+
+func TestWebhookFailsOnErrorStatus(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "boom", http.StatusBadGateway)
+	}))
+	defer srv.Close()
+
+	err := NewWebhook(srv.Client(), "").Notify(context.Background(), testNotification(models.ChannelWebhook, srv.URL))
+	assert.ErrorContains(t, err, "502")
+	assert.ErrorContains(t, err, "boom")
+
+	err = NewWebhook(srv.Client(), "").Notify(context.Background(), testNotification(models.ChannelWebhook, ""))
+	assert.ErrorIs(t, err, ErrNoAddress)
+}
+
+func TestTelegramSendsMessage(t *testing.T) {
+	var path string
+	var payload map[string]string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.Path
+		_ = json.NewDecoder(r.Body).Decode(&payload)
+		if payload["chat_id"] != "42" {
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = io.WriteString(w, `{"ok":false,"description":"Bad Request: chat not found"}`)
+			return
+		}
+		_, _ = io.WriteString(w, `{"ok":true,"result":{}}`)
+	}))
+	defer srv.Close()
+
+	telegram := NewTelegram(srv.Client(), srv.URL+"/", "token")
+
+	require.NoError(t, telegram.Notify(context.Background(), testNotification(models.ChannelTelegram, "42")))
+	assert.Equal(t, "/bottoken/sendMessage", path)
+	assert.Contains(t, payload["text"], "Review")
+
+	err := telegram.Notify(context.Background(), testNotification(models.ChannelTelegram, "43"))
+	assert.ErrorContains(t, err, "chat not found")
+}
+
+func TestTelegramHidesTokenInErrors(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	srv.Close()
+
+	err := NewTelegram(http.DefaultClient, srv.URL, "secret-token").
+		Notify(context.Background(), testNotification(models.ChannelTelegram, "42"))
+	require.Error(t, err)
+	assert.NotContains(t, err.Error(), "secret-token")
+}
+
+func TestSMSSendsThroughGateway(t *testing.T) {
+	var auth string
+	var payload map[string]string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth = r.Header.Get("Authorization")
+		_ = json.NewDecoder(r.Body).Decode(&payload)
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer srv.Close()
+
+	err := NewSMS(srv.Client(), srv.URL, "key", "Calendar").
+		Notify(context.Background(), testNotification(models.ChannelSMS, "+15550100"))
+	require.NoError(t, err)
+	assert.Equal(t, "Bearer key", auth)
+	assert.Equal(t, map[string]string{
+		"from": "Calendar",
+		"to":   "+15550100",
+		"text": "You have event planned at 2025-09-01 10:00 UTC: Review",
+	}, payload)
+}
+
+func TestLogWritesNotification(t *testing.T) {
+	core, logs := observer.New(zapcore.InfoLevel)
+
+	err := NewLog(zap.New(core)).Notify(context.Background(), testNotification(models.ChannelLog, ""))
+	require.NoError(t, err)
+	require.Equal(t, 1, logs.Len())
+	entry := logs.All()[0]
+	assert.Equal(t, "notification", entry.Message)
+	assert.Equal(t, uint64(7), entry.ContextMap()["event_id"])
+	assert.Contains(t, entry.ContextMap()["text"], "Review")
+}
+
+func TestRegistryDispatchesByChannel(t *testing.T) {
+	core, logs := observer.New(zapcore.InfoLevel)
+	registry := NewRegistry()
+	registry.Register(models.ChannelLog, NewLog(zap.New(core)))
+
+	require.NoError(t, registry.Send(context.Background(), testNotification(models.ChannelLog, "")))
+	assert.Equal(t, 1, logs.Len())
+
+	err := registry.Send(context.Background(), testNotification(models.ChannelSMS, "+15550100"))
+	assert.ErrorIs(t, err, ErrUnknownChannel)
+}
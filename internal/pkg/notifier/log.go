@@ -0,0 +1,31 @@
+package sender
+
+import (
+	"context"
+
+	"go.uber.org/zap"
+
+	"github.com/avraam311/improved-calendar-service/internal/models"
+)
+
+// Log writes notifications to the log instead of delivering them, e.g. for
+// local development.
+type Log struct {
+	logger *zap.Logger
+}
+
+func NewLog(logger *zap.Logger) *Log {
+	return &Log{
+		logger: logger,
+	}
+}
+
+func (l *Log) Notify(_ context.Context, n *models.Notification) error {
+	fields := []zap.Field{zap.String("subject", n.Subject), zap.String("text", n.Text)}
+	if n.Reminder != nil {
+		fields = append(fields, zap.Uint("event_id", n.Reminder.EventID), zap.Int("user_id", n.Reminder.UserID))
+	}
+	l.logger.Info("notification", fields...)
+
+	return nil
+}
@@ -1,7 +1,7 @@
 package sender
 
 import (
-	"encoding/json"
+	"context"
 	"net/smtp"
 
 	"github.com/avraam311/improved-calendar-service/internal/models"
@@ -26,20 +26,27 @@ func NewMail(host, port, user, from, password string) *Mail {
 	}
 }
 
-func (m *Mail) SendMessage(msg []byte) error {
-	ev := &models.EventCreate{}
-	err := json.Unmarshal(msg, ev)
-	if err != nil {
-		return err
+// Notify mails the notification to the address of the reminder.
+func (m *Mail) Notify(_ context.Context, n *models.Notification) error {
+	if n.To == "" {
+		return ErrNoAddress
 	}
-	to := []string{ev.Mail}
-	msgToSend := []byte("Subject: Notifying about event\r\n" +
-		"\r\n" +
-		"You have event planned in an hour: " + ev.Event + "\r\n")
 
-	err = smtp.SendMail(m.host+":"+m.port, m.auth, m.from, to, msgToSend)
+	return m.Send(&Message{
+		To:      []string{n.To},
+		Subject: n.Subject,
+		Text:    n.Text,
+		HTML:    n.HTML,
+	})
+}
+
+// Send mails the message from the configured sender address.
+func (m *Mail) Send(msg *Message) error {
+	msg.From = m.from
+	msgToSend, err := msg.Bytes()
 	if err != nil {
 		return err
 	}
-	return nil
+
+	return smtp.SendMail(m.host+":"+m.port, m.auth, m.from, msg.To, msgToSend)
 }
@@ -0,0 +1,186 @@
+package sender
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/textproto"
+	"strings"
+	"time"
+)
+
+// base64LineLength is the RFC 2045 limit for encoded lines.
+const base64LineLength = 76
+
+// Message is an email with a plain text body and an optional HTML version of
+// it, sent together as multipart/alternative. A message carrying an iMIP
+// calendar object is built as multipart/mixed: the bodies and the calendar as
+// multipart/alternative, so that mail clients show the invitation buttons,
+// followed by the same calendar as an .ics attachment.
+type Message struct {
+	From     string
+	To       []string
+	Subject  string
+	Text     string
+	HTML     string
+	Date     time.Time
+	Calendar []byte
+	// Method is the iTIP method of the calendar: REQUEST, REPLY or CANCEL.
+	Method string
+}
+
+// Bytes renders the message with its headers in RFC 5322 format.
+func (m *Message) Bytes() ([]byte, error) {
+	date := m.Date
+	if date.IsZero() {
+		date = time.Now()
+	}
+
+	var buf bytes.Buffer
+	writeHeader(&buf, "From", m.From)
+	writeHeader(&buf, "To", strings.Join(m.To, ", "))
+	writeHeader(&buf, "Subject", mime.QEncoding.Encode("UTF-8", m.Subject))
+	writeHeader(&buf, "Date", date.Format(time.RFC1123Z))
+	writeHeader(&buf, "Message-ID", messageID(m.From))
+	writeHeader(&buf, "MIME-Version", "1.0")
+
+	if len(m.Calendar) == 0 && m.HTML == "" {
+		writeHeader(&buf, "Content-Type", `text/plain; charset="UTF-8"`)
+		writeHeader(&buf, "Content-Transfer-Encoding", "quoted-printable")
+		buf.WriteString("\r\n")
+		if err := writeQuotedPrintable(&buf, m.Text); err != nil {
+			return nil, err
+		}
+		return buf.Bytes(), nil
+	}
+
+	alternative, boundary, err := m.alternative()
+	if err != nil {
+		return nil, err
+	}
+
+	if len(m.Calendar) == 0 {
+		writeHeader(&buf, "Content-Type", fmt.Sprintf(`multipart/alternative; boundary="%s"`, boundary))
+		buf.WriteString("\r\n")
+		buf.Write(alternative)
+		return buf.Bytes(), nil
+	}
+
+	mw := multipart.NewWriter(&buf)
+	writeHeader(&buf, "Content-Type", fmt.Sprintf(`multipart/mixed; boundary="%s"`, mw.Boundary()))
+	buf.WriteString("\r\n")
+
+	part, err := mw.CreatePart(textproto.MIMEHeader{
+		"Content-Type": {fmt.Sprintf(`multipart/alternative; boundary="%s"`, boundary)},
+	})
+	if err != nil {
+		return nil, err
+	}
+	if _, err = part.Write(alternative); err != nil {
+		return nil, err
+	}
+
+	part, err = mw.CreatePart(textproto.MIMEHeader{
+		"Content-Type":              {`application/ics; name="invite.ics"`},
+		"Content-Disposition":       {`attachment; filename="invite.ics"`},
+		"Content-Transfer-Encoding": {"base64"},
+	})
+	if err != nil {
+		return nil, err
+	}
+	writeBase64(part, m.Calendar)
+	if err = mw.Close(); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// alternative renders the text, the HTML and the calendar of the message as
+// the parts of a multipart/alternative body, from the plainest to the
+// richest, and returns it with its boundary.
+func (m *Message) alternative() ([]byte, string, error) {
+	var buf bytes.Buffer
+	aw := multipart.NewWriter(&buf)
+
+	part, err := aw.CreatePart(textproto.MIMEHeader{
+		"Content-Type":              {`text/plain; charset="UTF-8"`},
+		"Content-Transfer-Encoding": {"quoted-printable"},
+	})
+	if err != nil {
+		return nil, "", err
+	}
+	if err = writeQuotedPrintable(part, m.Text); err != nil {
+		return nil, "", err
+	}
+
+	if m.HTML != "" {
+		part, err = aw.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {`text/html; charset="UTF-8"`},
+			"Content-Transfer-Encoding": {"quoted-printable"},
+		})
+		if err != nil {
+			return nil, "", err
+		}
+		if err = writeQuotedPrintable(part, m.HTML); err != nil {
+			return nil, "", err
+		}
+	}
+
+	if len(m.Calendar) > 0 {
+		part, err = aw.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {fmt.Sprintf(`text/calendar; charset="UTF-8"; method=%s`, m.Method)},
+			"Content-Transfer-Encoding": {"base64"},
+		})
+		if err != nil {
+			return nil, "", err
+		}
+		writeBase64(part, m.Calendar)
+	}
+
+	if err = aw.Close(); err != nil {
+		return nil, "", err
+	}
+
+	return buf.Bytes(), aw.Boundary(), nil
+}
+
+func writeHeader(buf *bytes.Buffer, name, value string) {
+	buf.WriteString(name + ": " + value + "\r\n")
+}
+
+func writeQuotedPrintable(w io.Writer, text string) error {
+	qp := quotedprintable.NewWriter(w)
+	if _, err := qp.Write([]byte(strings.ReplaceAll(text, "\n", "\r\n"))); err != nil {
+		return err
+	}
+
+	return qp.Close()
+}
+
+func writeBase64(w io.Writer, data []byte) {
+	encoded := base64.StdEncoding.EncodeToString(data)
+	for len(encoded) > base64LineLength {
+		_, _ = w.Write([]byte(encoded[:base64LineLength] + "\r\n"))
+		encoded = encoded[base64LineLength:]
+	}
+	_, _ = w.Write([]byte(encoded + "\r\n"))
+}
+
+// messageID returns a random Message-ID in the domain of the sender.
+func messageID(from string) string {
+	domain := "localhost"
+	if at := strings.LastIndex(from, "@"); at >= 0 {
+		domain = strings.Trim(from[at+1:], "> ")
+	}
+
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return "<" + hex.EncodeToString(b) + "@" + domain + ">"
+}
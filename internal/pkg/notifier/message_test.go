@@ -0,0 +1,117 @@
+package sender
+
+import (
+	"bytes"
+	"encoding/base64"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/mail"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMessagePlainText(t *testing.T) {
+	msg := &Message{From: "calendar@example.com", To: []string{"bob@example.com"}, Subject: "Напоминание", Text: "hello"}
+
+	raw, err := msg.Bytes()
+	require.NoError(t, err)
+
+	parsed, err := mail.ReadMessage(bytes.NewReader(raw))
+	require.NoError(t, err)
+
+	subject, err := new(mime.WordDecoder).DecodeHeader(parsed.Header.Get("Subject"))
+	require.NoError(t, err)
+	assert.Equal(t, "Напоминание", subject)
+	assert.Equal(t, "bob@example.com", parsed.Header.Get("To"))
+	assert.True(t, strings.HasSuffix(parsed.Header.Get("Message-ID"), "@example.com>"))
+	assert.Equal(t, `text/plain; charset="UTF-8"`, parsed.Header.Get("Content-Type"))
+}
+
+func TestMessageWithHTML(t *testing.T) {
+	msg := &Message{From: "calendar@example.com", To: []string{"bob@example.com"}, Subject: "Reminder: Planning",
+		Text: "Planning starts in 1 hour", HTML: "<p><strong>Planning</strong> starts in 1 hour</p>"}
+
+	raw, err := msg.Bytes()
+	require.NoError(t, err)
+
+	parsed, err := mail.ReadMessage(bytes.NewReader(raw))
+	require.NoError(t, err)
+
+	mediaType, params, err := mime.ParseMediaType(parsed.Header.Get("Content-Type"))
+	require.NoError(t, err)
+	require.Equal(t, "multipart/alternative", mediaType)
+
+	alternative := multipart.NewReader(parsed.Body, params["boundary"])
+	for _, want := range []struct{ contentType, body string }{
+		{`text/plain; charset="UTF-8"`, msg.Text},
+		{`text/html; charset="UTF-8"`, msg.HTML},
+	} {
+		part, err := alternative.NextPart()
+		require.NoError(t, err)
+		assert.Equal(t, want.contentType, part.Header.Get("Content-Type"))
+		body, err := io.ReadAll(part)
+		require.NoError(t, err)
+		assert.Equal(t, want.body, string(body))
+	}
+
+	_, err = alternative.NextPart()
+	assert.ErrorIs(t, err, io.EOF)
+}
+
+func TestMessageWithCalendar(t *testing.T) {
+	cal := "BEGIN:VCALENDAR\r\nMETHOD:REQUEST\r\nEND:VCALENDAR\r\n"
+	msg := &Message{From: "calendar@example.com", To: []string{"bob@example.com"}, Subject: "Invitation: Planning",
+		Text: "alice@example.com invites you", Calendar: []byte(cal), Method: "REQUEST"}
+
+	raw, err := msg.Bytes()
+	require.NoError(t, err)
+
+	parsed, err := mail.ReadMessage(bytes.NewReader(raw))
+	require.NoError(t, err)
+
+	mediaType, params, err := mime.ParseMediaType(parsed.Header.Get("Content-Type"))
+	require.NoError(t, err)
+	require.Equal(t, "multipart/mixed", mediaType)
+
+	mixed := multipart.NewReader(parsed.Body, params["boundary"])
+
+	part, err := mixed.NextPart()
+	require.NoError(t, err)
+	mediaType, altParams, err := mime.ParseMediaType(part.Header.Get("Content-Type"))
+	require.NoError(t, err)
+	require.Equal(t, "multipart/alternative", mediaType)
+
+	alternative := multipart.NewReader(part, altParams["boundary"])
+	text, err := alternative.NextPart()
+	require.NoError(t, err)
+	body, err := io.ReadAll(text)
+	require.NoError(t, err)
+	assert.Equal(t, "alice@example.com invites you", string(body))
+
+	calendar, err := alternative.NextPart()
+	require.NoError(t, err)
+	assert.Equal(t, `text/calendar; charset="UTF-8"; method=REQUEST`, calendar.Header.Get("Content-Type"))
+	assert.Equal(t, cal, readBase64(t, calendar))
+
+	attachment, err := mixed.NextPart()
+	require.NoError(t, err)
+	assert.Equal(t, "invite.ics", attachment.FileName())
+	assert.Equal(t, cal, readBase64(t, attachment))
+
+	_, err = mixed.NextPart()
+	assert.ErrorIs(t, err, io.EOF)
+}
+
+func readBase64(t *testing.T, part *multipart.Part) string {
+	t.Helper()
+
+	encoded, err := io.ReadAll(part)
+	require.NoError(t, err)
+	decoded, err := base64.StdEncoding.DecodeString(strings.ReplaceAll(string(encoded), "\r\n", ""))
+	require.NoError(t, err)
+	return string(decoded)
+}
@@ -0,0 +1,44 @@
+package sender
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/avraam311/improved-calendar-service/internal/models"
+)
+
+// SMS sends notifications through an HTTP SMS gateway to the phone number of
+// the user. The gateway gets a JSON body with the sender, the recipient and
+// the text, and the API key as a bearer token.
+type SMS struct {
+	client *http.Client
+	url    string
+	apiKey string
+	from   string
+}
+
+func NewSMS(client *http.Client, url, apiKey, from string) *SMS {
+	return &SMS{
+		client: client,
+		url:    url,
+		apiKey: apiKey,
+		from:   from,
+	}
+}
+
+func (s *SMS) Notify(ctx context.Context, n *models.Notification) error {
+	if n.To == "" {
+		return ErrNoAddress
+	}
+
+	header := http.Header{}
+	if s.apiKey != "" {
+		header.Set("Authorization", "Bearer "+s.apiKey)
+	}
+
+	return postJSON(ctx, s.client, s.url, map[string]string{
+		"from": s.from,
+		"to":   n.To,
+		"text": n.Text,
+	}, header)
+}
@@ -0,0 +1,77 @@
+package sender
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/avraam311/improved-calendar-service/internal/models"
+)
+
+// Telegram sends notifications through a Telegram-style bot API to the chat
+// ID of the user.
+type Telegram struct {
+	client  *http.Client
+	baseURL string
+	token   string
+}
+
+func NewTelegram(client *http.Client, baseURL, token string) *Telegram {
+	return &Telegram{
+		client:  client,
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+		token:   token,
+	}
+}
+
+type telegramResponse struct {
+	OK          bool   `json:"ok"`
+	Description string `json:"description"`
+}
+
+func (t *Telegram) Notify(ctx context.Context, n *models.Notification) error {
+	if n.To == "" {
+		return ErrNoAddress
+	}
+
+	payload, err := json.Marshal(map[string]string{
+		"chat_id": n.To,
+		"text":    n.Subject + "\n\n" + n.Text,
+	})
+	if err != nil {
+		return err
+	}
+
+	endpoint := t.baseURL + "/bot" + t.token + "/sendMessage"
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := t.client.Do(req)
+	if err != nil {
+		// The error quotes the URL, which holds the bot token.
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			err = urlErr.Err
+		}
+		return fmt.Errorf("telegram request failed - %w", err)
+	}
+	defer resp.Body.Close()
+
+	var answer telegramResponse
+	if err = json.NewDecoder(resp.Body).Decode(&answer); err != nil {
+		return fmt.Errorf("telegram answered %s - %w", resp.Status, err)
+	}
+	if !answer.OK {
+		return fmt.Errorf("telegram answered %s: %s", resp.Status, answer.Description)
+	}
+
+	return nil
+}
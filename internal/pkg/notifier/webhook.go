@@ -0,0 +1,63 @@
+package sender
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/avraam311/improved-calendar-service/internal/models"
+)
+
+// SignatureHeader carries the hex HMAC-SHA256 of the webhook body when a
+// secret is configured.
+const SignatureHeader = "X-Calendar-Signature"
+
+// Webhook posts notifications as JSON to the URL of the user.
+type Webhook struct {
+	client *http.Client
+	secret string
+}
+
+func NewWebhook(client *http.Client, secret string) *Webhook {
+	return &Webhook{
+		client: client,
+		secret: secret,
+	}
+}
+
+type webhookPayload struct {
+	EventID uint      `json:"event_id"`
+	Event   string    `json:"event"`
+	Date    time.Time `json:"date"`
+	Offset  int       `json:"offset"`
+	Subject string    `json:"subject"`
+	Text    string    `json:"text"`
+}
+
+func (w *Webhook) Notify(ctx context.Context, n *models.Notification) error {
+	if n.To == "" {
+		return ErrNoAddress
+	}
+
+	payload := webhookPayload{Subject: n.Subject, Text: n.Text}
+	if r := n.Reminder; r != nil {
+		payload.EventID, payload.Event, payload.Date, payload.Offset = r.EventID, r.Event, r.Date, r.Offset
+	}
+
+	header := http.Header{}
+	if w.secret != "" {
+		body, err := json.Marshal(payload)
+		if err != nil {
+			return err
+		}
+		mac := hmac.New(sha256.New, []byte(w.secret))
+		mac.Write(body)
+		header.Set(SignatureHeader, hex.EncodeToString(mac.Sum(nil)))
+	}
+
+	return postJSON(ctx, w.client, n.To, payload, header)
+}
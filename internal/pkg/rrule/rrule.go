@@ -0,0 +1,475 @@
+package rrule
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	ErrInvalidRule = errors.New("invalid recurrence rule")
+)
+
+type Frequency string
+
+const (
+	Daily   Frequency = "DAILY"
+	Weekly  Frequency = "WEEKLY"
+	Monthly Frequency = "MONTHLY"
+	Yearly  Frequency = "YEARLY"
+)
+
+// maxPeriods bounds expansion of rules that never end or whose filters never match.
+const maxPeriods = 100000
+
+var weekdays = map[string]time.Weekday{
+	"SU": time.Sunday,
+	"MO": time.Monday,
+	"TU": time.Tuesday,
+	"WE": time.Wednesday,
+	"TH": time.Thursday,
+	"FR": time.Friday,
+	"SA": time.Saturday,
+}
+
+var weekdayNames = [...]string{"SU", "MO", "TU", "WE", "TH", "FR", "SA"}
+
+// WeekdayNum is a BYDAY entry. N selects the n-th weekday of the month
+// (negative counts from the end); zero means every such weekday.
+type WeekdayNum struct {
+	N       int
+	Weekday time.Weekday
+}
+
+// Rule is a subset of the RFC 5545 RRULE: FREQ, INTERVAL, BYDAY, BYMONTHDAY,
+// COUNT, UNTIL and WKST.
+type Rule struct {
+	Freq       Frequency
+	Interval   int
+	ByDay      []WeekdayNum
+	ByMonthDay []int
+	Count      int
+	Until      time.Time
+	WeekStart  time.Weekday
+	// FloatingUntil marks an UNTIL without a UTC designator. Its wall-clock
+	// time is taken in the location of dtstart.
+	FloatingUntil bool
+}
+
+func Parse(s string) (*Rule, error) {
+	s = strings.TrimPrefix(strings.TrimSpace(s), "RRULE:")
+	if s == "" {
+		return nil, fmt.Errorf("%w: empty rule", ErrInvalidRule)
+	}
+
+	r := &Rule{Interval: 1, WeekStart: time.Monday}
+	for _, part := range strings.Split(s, ";") {
+		key, value, ok := strings.Cut(part, "=")
+		if !ok || value == "" {
+			return nil, fmt.Errorf("%w: malformed part %q", ErrInvalidRule, part)
+		}
+
+		var err error
+		switch strings.ToUpper(key) {
+		case "FREQ":
+			r.Freq = Frequency(strings.ToUpper(value))
+		case "INTERVAL":
+			r.Interval, err = strconv.Atoi(value)
+			if err == nil && r.Interval < 1 {
+				err = errors.New("must be positive")
+			}
+		case "COUNT":
+			r.Count, err = strconv.Atoi(value)
+			if err == nil && r.Count < 1 {
+				err = errors.New("must be positive")
+			}
+		case "UNTIL":
+			r.Until, r.FloatingUntil, err = parseUntil(value)
+		case "BYDAY":
+			r.ByDay, err = parseByDay(value)
+		case "BYMONTHDAY":
+			r.ByMonthDay, err = parseByMonthDay(value)
+		case "WKST":
+			wd, found := weekdays[strings.ToUpper(value)]
+			if !found {
+				err = errors.New("unknown weekday")
+			}
+			r.WeekStart = wd
+		default:
+			return nil, fmt.Errorf("%w: unsupported part %q", ErrInvalidRule, key)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s: %v", ErrInvalidRule, key, err)
+		}
+	}
+
+	if err := r.validate(); err != nil {
+		return nil, err
+	}
+
+	return r, nil
+}
+
+func (r *Rule) validate() error {
+	switch r.Freq {
+	case Daily, Weekly, Monthly:
+	case Yearly:
+		if len(r.ByDay) > 0 || len(r.ByMonthDay) > 0 {
+			return fmt.Errorf("%w: BYDAY and BYMONTHDAY are not supported with FREQ=YEARLY", ErrInvalidRule)
+		}
+	case "":
+		return fmt.Errorf("%w: FREQ is required", ErrInvalidRule)
+	default:
+		return fmt.Errorf("%w: unsupported FREQ %q", ErrInvalidRule, r.Freq)
+	}
+
+	if r.Count > 0 && !r.Until.IsZero() {
+		return fmt.Errorf("%w: COUNT and UNTIL are mutually exclusive", ErrInvalidRule)
+	}
+
+	if r.Freq != Monthly {
+		for _, d := range r.ByDay {
+			if d.N != 0 {
+				return fmt.Errorf("%w: BYDAY ordinals are only supported with FREQ=MONTHLY", ErrInvalidRule)
+			}
+		}
+	}
+
+	return nil
+}
+
+// parseUntil parses UNTIL and reports whether it is floating. A date includes
+// the whole day.
+func parseUntil(value string) (time.Time, bool, error) {
+	if t, err := time.Parse("20060102T150405Z", value); err == nil {
+		return t, false, nil
+	}
+	if t, err := time.Parse("20060102T150405", value); err == nil {
+		return t, true, nil
+	}
+
+	t, err := time.Parse("20060102", value)
+	if err != nil {
+		return time.Time{}, false, errors.New("unknown date format")
+	}
+
+	return t.Add(24*time.Hour - time.Second), true, nil
+}
+
+// until returns the end of the rule for the series starting at dtstart.
+func (r *Rule) until(dtstart time.Time) time.Time {
+	if !r.FloatingUntil || r.Until.IsZero() {
+		return r.Until
+	}
+
+	u := r.Until
+	return time.Date(u.Year(), u.Month(), u.Day(), u.Hour(), u.Minute(), u.Second(), 0, dtstart.Location())
+}
+
+func parseByDay(value string) ([]WeekdayNum, error) {
+	var days []WeekdayNum
+	for _, item := range strings.Split(strings.ToUpper(value), ",") {
+		if len(item) < 2 {
+			return nil, fmt.Errorf("bad weekday %q", item)
+		}
+
+		wd, ok := weekdays[item[len(item)-2:]]
+		if !ok {
+			return nil, fmt.Errorf("bad weekday %q", item)
+		}
+
+		n := 0
+		if prefix := item[:len(item)-2]; prefix != "" {
+			var err error
+			n, err = strconv.Atoi(prefix)
+			if err != nil || n == 0 || n < -5 || n > 5 {
+				return nil, fmt.Errorf("bad weekday ordinal %q", item)
+			}
+		}
+
+		days = append(days, WeekdayNum{N: n, Weekday: wd})
+	}
+
+	return days, nil
+}
+
+func parseByMonthDay(value string) ([]int, error) {
+	var days []int
+	for _, item := range strings.Split(value, ",") {
+		d, err := strconv.Atoi(item)
+		if err != nil || d == 0 || d < -31 || d > 31 {
+			return nil, fmt.Errorf("bad month day %q", item)
+		}
+		days = append(days, d)
+	}
+
+	return days, nil
+}
+
+// String renders the rule back into its RFC 5545 text form without the RRULE: prefix.
+func (r *Rule) String() string {
+	parts := []string{"FREQ=" + string(r.Freq)}
+	if r.Interval > 1 {
+		parts = append(parts, "INTERVAL="+strconv.Itoa(r.Interval))
+	}
+	if len(r.ByDay) > 0 {
+		days := make([]string, 0, len(r.ByDay))
+		for _, d := range r.ByDay {
+			prefix := ""
+			if d.N != 0 {
+				prefix = strconv.Itoa(d.N)
+			}
+			days = append(days, prefix+weekdayNames[d.Weekday])
+		}
+		parts = append(parts, "BYDAY="+strings.Join(days, ","))
+	}
+	if len(r.ByMonthDay) > 0 {
+		days := make([]string, 0, len(r.ByMonthDay))
+		for _, d := range r.ByMonthDay {
+			days = append(days, strconv.Itoa(d))
+		}
+		parts = append(parts, "BYMONTHDAY="+strings.Join(days, ","))
+	}
+	if r.Count > 0 {
+		parts = append(parts, "COUNT="+strconv.Itoa(r.Count))
+	}
+	if !r.Until.IsZero() && r.FloatingUntil {
+		parts = append(parts, "UNTIL="+r.Until.Format("20060102T150405"))
+	} else if !r.Until.IsZero() {
+		parts = append(parts, "UNTIL="+r.Until.UTC().Format("20060102T150405Z"))
+	}
+	if r.WeekStart != time.Monday {
+		parts = append(parts, "WKST="+weekdayNames[r.WeekStart])
+	}
+
+	return strings.Join(parts, ";")
+}
+
+// Between returns the occurrences of the series starting at dtstart that fall
+// within [from, to], skipping exdates. Occurrences keep dtstart's wall-clock
+// time in dtstart's location.
+func (r *Rule) Between(dtstart, from, to time.Time, exdates []time.Time) []time.Time {
+	var occurrences []time.Time
+	until := r.until(dtstart)
+	count := 0
+
+	for i := r.firstPeriod(dtstart, from); i < maxPeriods; i++ {
+		for _, t := range r.candidates(dtstart, i) {
+			if t.Before(dtstart) {
+				continue
+			}
+			if t.After(to) || (!until.IsZero() && t.After(until)) {
+				return occurrences
+			}
+
+			count++
+			if r.Count > 0 && count > r.Count {
+				return occurrences
+			}
+
+			if !t.Before(from) && !excluded(t, exdates) {
+				occurrences = append(occurrences, t)
+			}
+		}
+	}
+
+	return occurrences
+}
+
+// Last returns the last occurrence of the series starting at dtstart when the
+// rule ends with a COUNT or UNTIL, and false when it never ends. A rule that
+// ends before its first occurrence ends at dtstart.
+func (r *Rule) Last(dtstart time.Time) (time.Time, bool) {
+	if r.Count == 0 && r.Until.IsZero() {
+		return time.Time{}, false
+	}
+
+	to := r.until(dtstart)
+	if to.IsZero() {
+		to = time.Date(9999, time.December, 31, 0, 0, 0, 0, time.UTC)
+	}
+	occurrences := r.Between(dtstart, dtstart, to, nil)
+	if len(occurrences) == 0 {
+		return dtstart, true
+	}
+
+	return occurrences[len(occurrences)-1], true
+}
+
+// firstPeriod skips whole periods before from when occurrences don't need to be counted.
+func (r *Rule) firstPeriod(dtstart, from time.Time) int {
+	if r.Count > 0 || !from.After(dtstart) {
+		return 0
+	}
+
+	var periods int
+	switch r.Freq {
+	case Daily:
+		periods = int(from.Sub(dtstart).Hours()/24) / r.Interval
+	case Weekly:
+		periods = int(from.Sub(dtstart).Hours()/24/7) / r.Interval
+	case Monthly:
+		periods = monthsBetween(dtstart, from) / r.Interval
+	case Yearly:
+		periods = (from.Year() - dtstart.Year()) / r.Interval
+	}
+
+	if periods > 0 {
+		return periods - 1
+	}
+
+	return 0
+}
+
+func (r *Rule) candidates(dtstart time.Time, period int) []time.Time {
+	loc := dtstart.Location()
+	year, month, day := dtstart.Date()
+	at := func(y int, m time.Month, d int) time.Time {
+		return time.Date(y, m, d, dtstart.Hour(), dtstart.Minute(), dtstart.Second(), dtstart.Nanosecond(), loc)
+	}
+
+	switch r.Freq {
+	case Daily:
+		t := at(year, month, day+period*r.Interval)
+		if r.matchesWeekday(t) && r.matchesMonthDay(t) {
+			return []time.Time{t}
+		}
+	case Weekly:
+		offset := (int(dtstart.Weekday()) - int(r.WeekStart) + 7) % 7
+		weekStart := at(year, month, day-offset+7*period*r.Interval)
+
+		var result []time.Time
+		for d := 0; d < 7; d++ {
+			t := weekStart.AddDate(0, 0, d)
+			if len(r.ByDay) == 0 && t.Weekday() != dtstart.Weekday() {
+				continue
+			}
+			if r.matchesWeekday(t) && r.matchesMonthDay(t) {
+				result = append(result, t)
+			}
+		}
+		return result
+	case Monthly:
+		first := time.Date(year, month+time.Month(period*r.Interval), 1, 0, 0, 0, 0, loc)
+		var result []time.Time
+		for _, d := range r.monthDays(first.Year(), first.Month(), day) {
+			result = append(result, at(first.Year(), first.Month(), d))
+		}
+		return result
+	case Yearly:
+		t := at(year+period*r.Interval, month, day)
+		if t.Day() == day {
+			return []time.Time{t}
+		}
+	}
+
+	return nil
+}
+
+// monthDays resolves BYMONTHDAY and BYDAY for a single month, defaulting to
+// dtstart's day of month when neither is set.
+func (r *Rule) monthDays(year int, month time.Month, startDay int) []int {
+	dim := daysIn(year, month)
+
+	byMonthDay := map[int]bool{}
+	for _, d := range r.ByMonthDay {
+		if d < 0 {
+			d = dim + d + 1
+		}
+		if d >= 1 && d <= dim {
+			byMonthDay[d] = true
+		}
+	}
+
+	byDay := map[int]bool{}
+	for _, wd := range r.ByDay {
+		var matches []int
+		for d := 1; d <= dim; d++ {
+			if time.Date(year, month, d, 0, 0, 0, 0, time.UTC).Weekday() == wd.Weekday {
+				matches = append(matches, d)
+			}
+		}
+
+		switch {
+		case wd.N == 0:
+			for _, d := range matches {
+				byDay[d] = true
+			}
+		case wd.N > 0 && wd.N <= len(matches):
+			byDay[matches[wd.N-1]] = true
+		case wd.N < 0 && -wd.N <= len(matches):
+			byDay[matches[len(matches)+wd.N]] = true
+		}
+	}
+
+	var days []int
+	switch {
+	case len(r.ByMonthDay) > 0 && len(r.ByDay) > 0:
+		for d := range byMonthDay {
+			if byDay[d] {
+				days = append(days, d)
+			}
+		}
+	case len(r.ByMonthDay) > 0:
+		for d := range byMonthDay {
+			days = append(days, d)
+		}
+	case len(r.ByDay) > 0:
+		for d := range byDay {
+			days = append(days, d)
+		}
+	case startDay <= dim:
+		days = append(days, startDay)
+	}
+
+	sort.Ints(days)
+	return days
+}
+
+func (r *Rule) matchesWeekday(t time.Time) bool {
+	if len(r.ByDay) == 0 {
+		return true
+	}
+	for _, d := range r.ByDay {
+		if d.Weekday == t.Weekday() {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (r *Rule) matchesMonthDay(t time.Time) bool {
+	if len(r.ByMonthDay) == 0 {
+		return true
+	}
+	dim := daysIn(t.Year(), t.Month())
+	for _, d := range r.ByMonthDay {
+		if d == t.Day() || dim+d+1 == t.Day() {
+			return true
+		}
+	}
+
+	return false
+}
+
+func excluded(t time.Time, exdates []time.Time) bool {
+	for _, ex := range exdates {
+		if t.Equal(ex) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func daysIn(year int, month time.Month) int {
+	return time.Date(year, month+1, 0, 0, 0, 0, 0, time.UTC).Day()
+}
+
+func monthsBetween(from, to time.Time) int {
+	return (to.Year()-from.Year())*12 + int(to.Month()) - int(from.Month())
+}
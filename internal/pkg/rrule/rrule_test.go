@@ -0,0 +1,139 @@
+package rrule
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func date(y int, m time.Month, d, h int) time.Time {
+	return time.Date(y, m, d, h, 0, 0, 0, time.UTC)
+}
+
+func TestParseRoundTrip(t *testing.T) {
+	rule, err := Parse("RRULE:FREQ=monthly;INTERVAL=2;BYDAY=-1FR,1MO;COUNT=5")
+	require.NoError(t, err)
+	assert.Equal(t, "FREQ=MONTHLY;INTERVAL=2;BYDAY=-1FR,1MO;COUNT=5", rule.String())
+}
+
+func TestParseInvalid(t *testing.T) {
+	for _, s := range []string{
+		"",
+		"INTERVAL=2",
+		"FREQ=HOURLY",
+		"FREQ=DAILY;COUNT=2;UNTIL=20250101T000000Z",
+		"FREQ=WEEKLY;BYDAY=1MO",
+		"FREQ=DAILY;BYMONTHDAY=32",
+		"FREQ=DAILY;BYSETPOS=1",
+	} {
+		_, err := Parse(s)
+		assert.ErrorIs(t, err, ErrInvalidRule, s)
+	}
+}
+
+func TestBetweenWeeklyByDay(t *testing.T) {
+	rule, err := Parse("FREQ=WEEKLY;BYDAY=MO,WE;COUNT=4")
+	require.NoError(t, err)
+
+	start := date(2025, time.September, 1, 9) // Monday
+	got := rule.Between(start, start, date(2025, time.December, 31, 0), nil)
+	assert.Equal(t, []time.Time{
+		date(2025, time.September, 1, 9),
+		date(2025, time.September, 3, 9),
+		date(2025, time.September, 8, 9),
+		date(2025, time.September, 10, 9),
+	}, got)
+}
+
+func TestBetweenDailyWindowAndExdates(t *testing.T) {
+	rule, err := Parse("FREQ=DAILY;INTERVAL=2;UNTIL=20250912T235959Z")
+	require.NoError(t, err)
+
+	start := date(2025, time.September, 1, 10)
+	exdates := []time.Time{date(2025, time.September, 7, 10)}
+	got := rule.Between(start, date(2025, time.September, 4, 0), date(2025, time.September, 30, 0), exdates)
+	assert.Equal(t, []time.Time{
+		date(2025, time.September, 5, 10),
+		date(2025, time.September, 9, 10),
+		date(2025, time.September, 11, 10),
+	}, got)
+}
+
+func TestBetweenMonthlyLastFridayAndMonthDay(t *testing.T) {
+	rule, err := Parse("FREQ=MONTHLY;BYDAY=-1FR")
+	require.NoError(t, err)
+
+	start := date(2025, time.January, 31, 15)
+	got := rule.Between(start, start, date(2025, time.April, 30, 0), nil)
+	assert.Equal(t, []time.Time{
+		date(2025, time.January, 31, 15),
+		date(2025, time.February, 28, 15),
+		date(2025, time.March, 28, 15),
+		date(2025, time.April, 25, 15),
+	}, got)
+
+	rule, err = Parse("FREQ=MONTHLY;BYMONTHDAY=31")
+	require.NoError(t, err)
+	got = rule.Between(start, start, date(2025, time.May, 31, 23), nil)
+	assert.Equal(t, []time.Time{
+		date(2025, time.January, 31, 15),
+		date(2025, time.March, 31, 15),
+		date(2025, time.May, 31, 15),
+	}, got)
+}
+
+func TestBetweenKeepsWallClockAcrossDST(t *testing.T) {
+	loc, err := time.LoadLocation("Europe/Berlin")
+	require.NoError(t, err)
+
+	rule, err := Parse("FREQ=WEEKLY;COUNT=2")
+	require.NoError(t, err)
+
+	start := time.Date(2025, time.March, 24, 9, 0, 0, 0, loc)
+	got := rule.Between(start, start, start.AddDate(0, 1, 0), nil)
+	require.Len(t, got, 2)
+	assert.Equal(t, 9, got[1].Hour())
+	assert.Equal(t, 167*time.Hour, got[1].Sub(got[0]))
+}
+
+func TestLast(t *testing.T) {
+	start := date(2025, time.September, 1, 9) // Monday
+
+	rule, err := Parse("FREQ=WEEKLY;BYDAY=MO,WE;COUNT=5")
+	require.NoError(t, err)
+	last, ok := rule.Last(start)
+	assert.True(t, ok)
+	assert.Equal(t, date(2025, time.September, 15, 9), last)
+
+	rule, err = Parse("FREQ=DAILY;UNTIL=20250910T000000Z")
+	require.NoError(t, err)
+	last, ok = rule.Last(start)
+	assert.True(t, ok)
+	assert.Equal(t, date(2025, time.September, 9, 9), last)
+
+	rule, err = Parse("FREQ=DAILY")
+	require.NoError(t, err)
+	_, ok = rule.Last(start)
+	assert.False(t, ok)
+}
+
+func TestFloatingUntilInStartLocation(t *testing.T) {
+	loc, err := time.LoadLocation("America/New_York")
+	require.NoError(t, err)
+	start := time.Date(2025, time.September, 1, 22, 0, 0, 0, loc)
+
+	rule, err := Parse("FREQ=DAILY;UNTIL=20250910")
+	require.NoError(t, err)
+	last, ok := rule.Last(start)
+	assert.True(t, ok)
+	assert.True(t, last.Equal(time.Date(2025, time.September, 10, 22, 0, 0, 0, loc)), last)
+
+	rule, err = Parse("FREQ=DAILY;UNTIL=20250905T220000")
+	require.NoError(t, err)
+	last, ok = rule.Last(start)
+	assert.True(t, ok)
+	assert.True(t, last.Equal(time.Date(2025, time.September, 5, 22, 0, 0, 0, loc)), last)
+	assert.Equal(t, "FREQ=DAILY;UNTIL=20250905T220000", rule.String())
+}
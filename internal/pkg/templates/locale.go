@@ -0,0 +1,129 @@
+package templates
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/avraam311/improved-calendar-service/internal/models"
+)
+
+var ruMonths = [...]string{"января", "февраля", "марта", "апреля", "мая", "июня", "июля", "августа", "сентября",
+	"октября", "ноября", "декабря"}
+
+// funcsFor returns the template functions formatting dates, reminder offsets
+// and attendee statuses in the locale.
+func funcsFor(locale string) map[string]any {
+	if locale == "ru" {
+		return map[string]any{
+			"when":   func(d *Data) string { return ruWhen(d.Date, d.AllDay) },
+			"offset": ruOffset,
+			"status": ruStatus,
+		}
+	}
+
+	return map[string]any{
+		"when":   func(d *Data) string { return enWhen(d.Date, d.AllDay) },
+		"offset": enOffset,
+		"status": enStatus,
+	}
+}
+
+func enWhen(date time.Time, allDay bool) string {
+	if allDay {
+		return date.Format("2006-01-02")
+	}
+
+	return date.Format("2006-01-02 15:04 MST")
+}
+
+func ruWhen(date time.Time, allDay bool) string {
+	day := fmt.Sprintf("%d %s %d", date.Day(), ruMonths[date.Month()-1], date.Year())
+	if allDay {
+		return day
+	}
+
+	return day + date.Format(", 15:04 MST")
+}
+
+// enOffset describes when an event starts relative to a reminder sent offset
+// minutes before it.
+func enOffset(offset int) string {
+	if offset == 0 {
+		return "now"
+	}
+
+	days, hours, minutes := offset/1440, offset%1440/60, offset%60
+	var parts []string
+	for _, p := range []struct {
+		n    int
+		unit string
+	}{{days, "day"}, {hours, "hour"}, {minutes, "minute"}} {
+		if p.n == 1 {
+			parts = append(parts, "1 "+p.unit)
+		} else if p.n > 1 {
+			parts = append(parts, fmt.Sprintf("%d %ss", p.n, p.unit))
+		}
+	}
+
+	return "in " + strings.Join(parts, " ")
+}
+
+func ruOffset(offset int) string {
+	if offset == 0 {
+		return "сейчас"
+	}
+
+	days, hours, minutes := offset/1440, offset%1440/60, offset%60
+	var parts []string
+	if days > 0 {
+		parts = append(parts, ruPlural(days, "день", "дня", "дней"))
+	}
+	if hours > 0 {
+		parts = append(parts, ruPlural(hours, "час", "часа", "часов"))
+	}
+	if minutes > 0 {
+		parts = append(parts, ruPlural(minutes, "минуту", "минуты", "минут"))
+	}
+
+	return "через " + strings.Join(parts, " ")
+}
+
+// ruPlural picks the Russian plural form for n.
+func ruPlural(n int, one, few, many string) string {
+	form := many
+	switch n10, n100 := n%10, n%100; {
+	case n10 == 1 && n100 != 11:
+		form = one
+	case n10 >= 2 && n10 <= 4 && (n100 < 12 || n100 > 14):
+		form = few
+	}
+
+	return fmt.Sprintf("%d %s", n, form)
+}
+
+func enStatus(status string) string {
+	switch status {
+	case models.AttendeeAccepted:
+		return "accepted"
+	case models.AttendeeDeclined:
+		return "declined"
+	case models.AttendeeTentative:
+		return "tentatively accepted"
+	}
+
+	return "not yet answered"
+}
+
+func ruStatus(status string) string {
+	switch status {
+	case models.AttendeeAccepted:
+		return "принял(а)"
+	case models.AttendeeDeclined:
+		return "отклонил(а)"
+	case models.AttendeeTentative:
+		return "предварительно принял(а)"
+	}
+
+	return "пока не ответил(а) на"
+}
@@ -0,0 +1,174 @@
+package templates
+
+import (
+	"bytes"
+	"embed"
+	"errors"
+	"fmt"
+	htmltemplate "html/template"
+	"io/fs"
+	"path"
+	"strings"
+	texttemplate "text/template"
+	"time"
+)
+
+// Notification types.
+const (
+	TypeReminder = "reminder"
+	TypeInvite   = "invite"
+	TypeUpdate   = "update"
+	TypeCancel   = "cancel"
+	TypeReply    = "reply"
+	TypeDigest   = "digest"
+)
+
+// DefaultLocale is used for users without a locale and for the templates
+// missing in the locale of a user.
+const DefaultLocale = "en"
+
+const (
+	textSuffix = ".txt.tmpl"
+	htmlSuffix = ".html.tmpl"
+)
+
+var ErrNoTemplate = errors.New("no template")
+
+// defaultFS holds the built-in templates.
+//
+//go:embed default
+var defaultFS embed.FS
+
+// Default returns the built-in templates, laid out as Load expects.
+func Default() fs.FS {
+	sub, _ := fs.Sub(defaultFS, "default")
+	return sub
+}
+
+// Data is passed to every template. Date is in the time zone to show it in.
+type Data struct {
+	Event     string
+	Date      time.Time
+	AllDay    bool
+	Organizer string
+	Attendee  string
+	Status    string
+	Offset    int
+	// Removed marks the cancellation sent to a single uninvited attendee.
+	Removed bool
+	// Items are the events of a digest, Weekly tells a weekly digest starting
+	// at Date from a daily one.
+	Items  []*Data
+	Weekly bool
+}
+
+// Rendered is a notification ready to send. HTML is empty for the types
+// without an HTML template.
+type Rendered struct {
+	Subject string
+	Text    string
+	HTML    string
+}
+
+type set struct {
+	text *texttemplate.Template
+	html *htmltemplate.Template
+}
+
+// Renderer renders the notifications of every type in the locale of the
+// recipient.
+type Renderer struct {
+	sets map[string]map[string]*set
+}
+
+// Load parses the templates of the file systems, the files of a later one
+// replacing the same files of the earlier ones. Each locale is a directory
+// holding <type>.txt.tmpl, which defines the "subject" and "body" templates,
+// and an optional <type>.html.tmpl.
+func Load(fsyss ...fs.FS) (*Renderer, error) {
+	sources := make(map[string]string)
+	for _, fsys := range fsyss {
+		matches, err := fs.Glob(fsys, "*/*.tmpl")
+		if err != nil {
+			return nil, err
+		}
+		for _, name := range matches {
+			src, err := fs.ReadFile(fsys, name)
+			if err != nil {
+				return nil, err
+			}
+			sources[name] = string(src)
+		}
+	}
+
+	r := &Renderer{sets: make(map[string]map[string]*set)}
+	for name, src := range sources {
+		locale, file := path.Split(name)
+		locale = strings.TrimSuffix(locale, "/")
+		if strings.HasSuffix(file, htmlSuffix) {
+			continue
+		}
+		kind, ok := strings.CutSuffix(file, textSuffix)
+		if !ok {
+			continue
+		}
+
+		funcs := funcsFor(locale)
+		text, err := texttemplate.New(file).Funcs(texttemplate.FuncMap(funcs)).Parse(src)
+		if err != nil {
+			return nil, fmt.Errorf("template %s - %w", name, err)
+		}
+		if text.Lookup("subject") == nil || text.Lookup("body") == nil {
+			return nil, fmt.Errorf("template %s - subject or body not defined", name)
+		}
+
+		s := &set{text: text}
+		if htmlSrc, ok := sources[path.Join(locale, kind+htmlSuffix)]; ok {
+			s.html, err = htmltemplate.New(kind + htmlSuffix).Funcs(htmltemplate.FuncMap(funcs)).Parse(htmlSrc)
+			if err != nil {
+				return nil, fmt.Errorf("template %s - %w", path.Join(locale, kind+htmlSuffix), err)
+			}
+		}
+
+		if r.sets[locale] == nil {
+			r.sets[locale] = make(map[string]*set)
+		}
+		r.sets[locale][kind] = s
+	}
+
+	return r, nil
+}
+
+// Render renders the notification of the type in the locale, falling back to
+// the default locale.
+func (r *Renderer) Render(kind, locale string, data *Data) (*Rendered, error) {
+	s, ok := r.sets[locale][kind]
+	if !ok {
+		s, ok = r.sets[DefaultLocale][kind]
+	}
+	if !ok {
+		return nil, fmt.Errorf("%w for %q", ErrNoTemplate, kind)
+	}
+
+	var subject, text bytes.Buffer
+	if err := s.text.ExecuteTemplate(&subject, "subject", data); err != nil {
+		return nil, err
+	}
+	if err := s.text.ExecuteTemplate(&text, "body", data); err != nil {
+		return nil, err
+	}
+
+	rendered := &Rendered{
+		Subject: strings.Join(strings.Fields(subject.String()), " "),
+		Text:    strings.TrimSpace(text.String()) + "\n",
+	}
+	if s.html != nil {
+		var html bytes.Buffer
+		if err := s.html.Execute(&html, data); err != nil {
+			return nil, err
+		}
+		rendered.HTML = html.String()
+	}
+
+	return rendered, nil
+}
@@ -0,0 +1,71 @@
+package templates
+
+import (
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRenderReminder(t *testing.T) {
+	r, err := Load(Default())
+	require.NoError(t, err)
+
+	data := &Data{Event: "Review <draft>", Date: time.Date(2025, 9, 1, 10, 0, 0, 0, time.UTC), Offset: 90}
+
+	en, err := r.Render(TypeReminder, "en", data)
+	require.NoError(t, err)
+	assert.Equal(t, "Reminder: Review <draft>", en.Subject)
+	assert.Equal(t, "Review <draft> starts in 1 hour 30 minutes, on 2025-09-01 10:00 UTC.\n", en.Text)
+	assert.Contains(t, en.HTML, "<strong>Review &lt;draft&gt;</strong>")
+
+	ru, err := r.Render(TypeReminder, "ru", data)
+	require.NoError(t, err)
+	assert.Equal(t, "Напоминание: Review <draft>", ru.Subject)
+	assert.Equal(t, "«Review <draft>» начнётся через 1 час 30 минут, 1 сентября 2025, 10:00 UTC.\n", ru.Text)
+	assert.Contains(t, ru.HTML, `lang="ru"`)
+}
+
+func TestRenderFallsBackToDefaultLocale(t *testing.T) {
+	r, err := Load(Default())
+	require.NoError(t, err)
+
+	data := &Data{Event: "Retro", Date: time.Date(2025, 9, 1, 0, 0, 0, 0, time.UTC), AllDay: true,
+		Organizer: "alice@example.com", Removed: true}
+
+	rendered, err := r.Render(TypeCancel, "de", data)
+	require.NoError(t, err)
+	assert.Equal(t, "Cancelled: Retro", rendered.Subject)
+	assert.Equal(t, "alice@example.com has removed you from \"Retro\" on 2025-09-01.\n", rendered.Text)
+
+	_, err = r.Render("unknown", "en", data)
+	assert.ErrorIs(t, err, ErrNoTemplate)
+}
+
+func TestLoadOverridesDefaults(t *testing.T) {
+	override := fstest.MapFS{
+		"ru/digest.txt.tmpl": {Data: []byte(`{{define "subject"}}Сводка{{end}}{{define "body"}}{{len .Items}} шт.{{end}}`)},
+	}
+	r, err := Load(Default(), override)
+	require.NoError(t, err)
+
+	data := &Data{Date: time.Date(2025, 9, 1, 0, 0, 0, 0, time.UTC), AllDay: true, Items: []*Data{{Event: "Standup"}}}
+	rendered, err := r.Render(TypeDigest, "ru", data)
+	require.NoError(t, err)
+	assert.Equal(t, "Сводка", rendered.Subject)
+	assert.Equal(t, "1 шт.\n", rendered.Text)
+	assert.Contains(t, rendered.HTML, "Standup")
+
+	_, err = Load(fstest.MapFS{"en/reminder.txt.tmpl": {Data: []byte(`{{define "body"}}{{end}}`)}})
+	assert.Error(t, err)
+}
+
+func TestOffsetWords(t *testing.T) {
+	assert.Equal(t, "now", enOffset(0))
+	assert.Equal(t, "in 1 day 2 hours", enOffset(1560))
+	assert.Equal(t, "через 2 дня 21 минуту", ruOffset(2901))
+	assert.Equal(t, "через 11 минут", ruOffset(11))
+	assert.Equal(t, "через 5 часов", ruOffset(300))
+}
@@ -0,0 +1,84 @@
+package timerange
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+var ErrInvalidWeekStart = errors.New("week start must be monday or sunday")
+
+const (
+	dateOnly      = "2006-01-02"
+	dateTimeLocal = "2006-01-02T15:04:05"
+)
+
+// ParseDate parses a date, a local date-time or an RFC 3339 date-time. Values
+// without an offset are taken in loc, the result is always in loc.
+func ParseDate(s string, loc *time.Location) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t.In(loc), nil
+	}
+	if t, err := time.ParseInLocation(dateTimeLocal, s, loc); err == nil {
+		return t, nil
+	}
+
+	t, err := time.ParseInLocation(dateOnly, s, loc)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid date %q", s)
+	}
+
+	return t, nil
+}
+
+// ParseEnd parses the end of a period like ParseDate. A date without a time
+// ends the period at the next midnight, so that the whole day is included in
+// the period [from, end).
+func ParseEnd(s string, loc *time.Location) (time.Time, error) {
+	if t, err := time.ParseInLocation(dateOnly, s, loc); err == nil {
+		return t.AddDate(0, 0, 1), nil
+	}
+
+	return ParseDate(s, loc)
+}
+
+// ParseWeekday parses the first day of the week. An empty string is Monday.
+func ParseWeekday(s string) (time.Weekday, error) {
+	switch strings.ToLower(s) {
+	case "", "monday", "mon", "mo":
+		return time.Monday, nil
+	case "sunday", "sun", "su":
+		return time.Sunday, nil
+	}
+
+	return 0, ErrInvalidWeekStart
+}
+
+// Day returns the calendar day containing t in loc as [from, to). The day is
+// 23 or 25 hours long when the clocks change.
+func Day(t time.Time, loc *time.Location) (time.Time, time.Time) {
+	from := midnight(t.In(loc))
+	return from, from.AddDate(0, 0, 1)
+}
+
+// Week returns the calendar week containing t in loc as [from, to).
+func Week(t time.Time, loc *time.Location, weekStart time.Weekday) (time.Time, time.Time) {
+	day := midnight(t.In(loc))
+	offset := (int(day.Weekday()) - int(weekStart) + 7) % 7
+	from := day.AddDate(0, 0, -offset)
+
+	return from, from.AddDate(0, 0, 7)
+}
+
+// Month returns the calendar month containing t in loc as [from, to).
+func Month(t time.Time, loc *time.Location) (time.Time, time.Time) {
+	t = t.In(loc)
+	from := time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, loc)
+
+	return from, from.AddDate(0, 1, 0)
+}
+
+func midnight(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
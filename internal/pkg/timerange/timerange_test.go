@@ -0,0 +1,66 @@
+package timerange
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDayAcrossDST(t *testing.T) {
+	loc, err := time.LoadLocation("Europe/Berlin")
+	require.NoError(t, err)
+
+	from, to := Day(time.Date(2025, time.March, 30, 12, 0, 0, 0, loc), loc)
+	assert.Equal(t, time.Date(2025, time.March, 30, 0, 0, 0, 0, loc), from)
+	assert.Equal(t, 23*time.Hour, to.Sub(from))
+}
+
+func TestWeekStart(t *testing.T) {
+	wednesday := time.Date(2025, time.September, 3, 15, 0, 0, 0, time.UTC)
+
+	from, to := Week(wednesday, time.UTC, time.Monday)
+	assert.Equal(t, time.Date(2025, time.September, 1, 0, 0, 0, 0, time.UTC), from)
+	assert.Equal(t, time.Date(2025, time.September, 8, 0, 0, 0, 0, time.UTC), to)
+
+	from, _ = Week(wednesday, time.UTC, time.Sunday)
+	assert.Equal(t, time.Date(2025, time.August, 31, 0, 0, 0, 0, time.UTC), from)
+}
+
+func TestMonth(t *testing.T) {
+	loc, err := time.LoadLocation("America/New_York")
+	require.NoError(t, err)
+
+	// 2025-03-01 02:00 UTC is still February in New York.
+	from, to := Month(time.Date(2025, time.March, 1, 2, 0, 0, 0, time.UTC), loc)
+	assert.Equal(t, time.Date(2025, time.February, 1, 0, 0, 0, 0, loc), from)
+	assert.Equal(t, time.Date(2025, time.March, 1, 0, 0, 0, 0, loc), to)
+}
+
+func TestParseDate(t *testing.T) {
+	loc, err := time.LoadLocation("Europe/Moscow")
+	require.NoError(t, err)
+
+	for _, s := range []string{"2025-09-01", "2025-09-01T00:00:00", "2025-08-31T21:00:00Z"} {
+		got, err := ParseDate(s, loc)
+		require.NoError(t, err, s)
+		assert.True(t, got.Equal(time.Date(2025, time.September, 1, 0, 0, 0, 0, loc)), s)
+	}
+
+	_, err = ParseDate("01.09.2025", loc)
+	assert.Error(t, err)
+}
+
+func TestParseEnd(t *testing.T) {
+	loc, err := time.LoadLocation("Europe/Moscow")
+	require.NoError(t, err)
+
+	got, err := ParseEnd("2025-09-01", loc)
+	require.NoError(t, err)
+	assert.True(t, got.Equal(time.Date(2025, time.September, 2, 0, 0, 0, 0, loc)))
+
+	got, err = ParseEnd("2025-09-01T18:00:00", loc)
+	require.NoError(t, err)
+	assert.True(t, got.Equal(time.Date(2025, time.September, 1, 18, 0, 0, 0, loc)))
+}
@@ -2,67 +2,306 @@ package workers
 
 import (
 	"context"
-	"fmt"
-	"sync"
 	"time"
 
 	"github.com/avraam311/improved-calendar-service/internal/models"
+	"github.com/avraam311/improved-calendar-service/internal/pkg/cron"
+	"github.com/avraam311/improved-calendar-service/internal/pkg/rrule"
 	"go.uber.org/zap"
 )
 
 type Repository interface {
-	GetEventsToClean(ctx context.Context) ([]*models.EventToClean, error)
-	DeleteEvent(ctx context.Context, ID uint) (uint, error)
+	GetCountSeries(ctx context.Context, afterID uint, limit int) ([]*models.CountSeries, error)
+	SaveSeriesEnds(ctx context.Context, series []*models.CountSeries) error
+	GetEventsToClean(ctx context.Context, policy *models.RetentionPolicy) ([]*models.EventToClean, error)
+	ArchiveEvents(ctx context.Context, policy *models.RetentionPolicy, limit int) (int, int, error)
+	ExportArchive(ctx context.Context, before time.Time, limit int, write func([]*models.ArchiveRecord) error) (int, error)
+	DeleteExported(ctx context.Context, keys []models.ArchiveKey) (int, error)
 }
 
+type coldWriter interface {
+	Write(records []*models.ArchiveRecord) error
+	Pending() ([]models.ArchiveKey, error)
+	Commit() error
+}
+
+// Retention keeps events for MaxAge after they end, or for the max age of the
+// override for their owner. The events of the users on legal hold are kept
+// for good.
+type Retention struct {
+	MaxAge    time.Duration
+	Overrides map[int]time.Duration
+	LegalHold []int
+}
+
+// policy returns the cutoffs of the retention at now.
+func (r Retention) policy(now time.Time) *models.RetentionPolicy {
+	policy := &models.RetentionPolicy{
+		Cutoff:    now.Add(-r.MaxAge),
+		Overrides: make(map[int]time.Time, len(r.Overrides)),
+		LegalHold: r.LegalHold,
+	}
+	for userID, maxAge := range r.Overrides {
+		policy.Overrides[userID] = now.Add(-maxAge)
+	}
+
+	return policy
+}
+
+// Batch limits the events archived in one statement and sets the pause
+// between statements, so cleaning doesn't hold locks or load the database for
+// long.
+type Batch struct {
+	Size  int
+	Pause time.Duration
+}
+
+// ColdArchive moves the events kept in the archive for After to the files of
+// Writer. A nil Writer keeps the events in the archive for good.
+type ColdArchive struct {
+	Writer coldWriter
+	After  time.Duration
+}
+
+// Cleaner moves the events past their retention along with their overrides to
+// the events archive on schedule, batch by batch, and then the events archived
+// long ago to the cold archive. The ends of the series bounded by a COUNT are
+// computed first, so they can be archived like the others. Archived events can
+// be listed and restored through the API. In the dry run it only reports the
+// events it would archive and writes nothing, the ends of the series are kept
+// in memory for the report.
 type Cleaner struct {
-	repo         Repository
-	storeArchive map[string]*models.EventCreate
-	mu           sync.Mutex
-	logger       *zap.Logger
+	repo      Repository
+	schedule  *cron.Schedule
+	retention Retention
+	batch     Batch
+	cold      ColdArchive
+	dryRun    bool
+	logger    *zap.Logger
 }
 
-func NewCleaner(repo Repository, logger *zap.Logger) *Cleaner {
+func NewCleaner(repo Repository, schedule *cron.Schedule, retention Retention, batch Batch, cold ColdArchive, dryRun bool, logger *zap.Logger) *Cleaner {
 	return &Cleaner{
-		repo:         repo,
-		storeArchive: make(map[string]*models.EventCreate),
-		logger:       logger,
+		repo:      repo,
+		schedule:  schedule,
+		retention: retention,
+		batch:     batch,
+		cold:      cold,
+		dryRun:    dryRun,
+		logger:    logger,
 	}
 }
 
 func (c *Cleaner) Run(ctx context.Context) {
-	ticker := time.NewTicker(24 * time.Hour)
-	defer ticker.Stop()
-
 	for {
+		next := c.schedule.Next(time.Now().UTC())
+		if next.IsZero() {
+			c.logger.Warn("cleaner.go - cleaner schedule never fires")
+			return
+		}
+
+		timer := time.NewTimer(time.Until(next))
 		select {
 		case <-ctx.Done():
+			timer.Stop()
 			return
-		case <-ticker.C:
-			events, err := c.repo.GetEventsToClean(ctx)
+		case <-timer.C:
+		}
+
+		c.clean(ctx)
+	}
+}
+
+func (c *Cleaner) clean(ctx context.Context) {
+	now := time.Now().UTC()
+	policy := c.retention.policy(now)
+	save := c.repo.SaveSeriesEnds
+	if c.dryRun {
+		policy.SeriesEnds = make(map[uint]time.Time)
+		save = func(_ context.Context, series []*models.CountSeries) error {
+			for _, s := range series {
+				policy.SeriesEnds[s.ID] = s.SeriesEnd
+			}
+			return nil
+		}
+	}
+	if series, err := c.endCountSeries(ctx, save); err != nil {
+		c.logger.Warn("cleaner.go - failed to compute the ends of count series",
+			zap.Int("series", series), zap.Error(err))
+	}
+
+	if c.dryRun {
+		c.report(ctx, policy)
+		return
+	}
+
+	start := time.Now()
+	events, rows, batches, err := c.archive(ctx, policy)
+	fields := []zap.Field{zap.Int("events", events), zap.Int("rows", rows), zap.Int("batches", batches),
+		zap.Duration("duration", time.Since(start))}
+	if err != nil {
+		c.logger.Warn("cleaner.go - cleaning stopped", append(fields, zap.Error(err))...)
+		return
+	}
+	c.logger.Info("cleaner.go - archived old events", fields...)
+
+	if c.cold.Writer == nil {
+		return
+	}
+	start = time.Now()
+	rows, batches, err = c.export(ctx, now.Add(-c.cold.After))
+	fields = []zap.Field{zap.Int("rows", rows), zap.Int("batches", batches), zap.Duration("duration", time.Since(start))}
+	if err != nil {
+		c.logger.Warn("cleaner.go - cold archive export stopped", append(fields, zap.Error(err))...)
+		return
+	}
+	c.logger.Info("cleaner.go - exported archived events to cold archive", fields...)
+}
+
+// endCountSeries computes the end of the last occurrence of every series
+// bounded by a COUNT that has none stored yet and passes them to save, batch
+// by batch. Series with a rule that can't be parsed are skipped and kept. It
+// returns the number of series ended.
+func (c *Cleaner) endCountSeries(ctx context.Context, save func(context.Context, []*models.CountSeries) error) (int, error) {
+	var total int
+	var afterID uint
+	for ctx.Err() == nil {
+		series, err := c.repo.GetCountSeries(ctx, afterID, c.batch.Size)
+		if err != nil {
+			return total, err
+		}
+		if len(series) == 0 {
+			return total, nil
+		}
+		afterID = series[len(series)-1].ID
+
+		ended := make([]*models.CountSeries, 0, len(series))
+		for _, s := range series {
+			end, err := seriesEnd(s)
 			if err != nil {
-				c.logger.Warn("failed to get events to clean", zap.Error(err))
+				c.logger.Warn("cleaner.go - failed to compute the end of series",
+					zap.Uint("event_id", s.ID), zap.Error(err))
 				continue
 			}
+			s.SeriesEnd = end
+			ended = append(ended, s)
+		}
+		if err = save(ctx, ended); err != nil {
+			return total, err
+		}
+		total += len(ended)
 
-			c.mu.Lock()
-			for _, event := range events {
-				key := fmt.Sprintf("%s-%s", event.Mail, event.Event)
-				c.storeArchive[key] = &models.EventCreate{
-					UserID: event.UserID,
-					Event:  event.Event,
-					Date:   event.Date,
-					Mail:   event.Mail,
-				}
-
-				_, err := c.repo.DeleteEvent(ctx, event.ID)
-				if err != nil {
-					c.logger.Warn("failed to delete event from db", zap.Error(err), zap.Uint("event_id", event.ID))
-				}
+		if len(series) < c.batch.Size {
+			return total, nil
+		}
+	}
+
+	return total, ctx.Err()
+}
+
+// seriesEnd returns the end of the last occurrence of the series. The rule is
+// expanded in the time zone of the series, like the occurrences are.
+func seriesEnd(series *models.CountSeries) (time.Time, error) {
+	r, err := rrule.Parse(series.RRule)
+	if err != nil {
+		return time.Time{}, err
+	}
+	loc, err := time.LoadLocation(series.TZ)
+	if err != nil {
+		loc = time.UTC
+	}
+
+	last, ok := r.Last(series.Date.In(loc))
+	if !ok {
+		return time.Time{}, rrule.ErrInvalidRule
+	}
+
+	return last.Add(series.End.Sub(series.Date)).UTC(), nil
+}
+
+// archive archives the events batch by batch until none is left. It returns
+// the numbers of archived events, of archived rows including the overrides and
+// of batches.
+func (c *Cleaner) archive(ctx context.Context, policy *models.RetentionPolicy) (int, int, int, error) {
+	var events, rows, batches int
+	for {
+		batchEvents, batchRows, err := c.repo.ArchiveEvents(ctx, policy, c.batch.Size)
+		if err != nil {
+			return events, rows, batches, err
+		}
+		events += batchEvents
+		rows += batchRows
+		batches++
+
+		if batchEvents < c.batch.Size {
+			return events, rows, batches, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return events, rows, batches, ctx.Err()
+		case <-time.After(c.batch.Pause):
+		}
+	}
+}
+
+// export moves the rows archived before the cutoff to the cold archive batch
+// by batch until none is left. Each batch is committed in the writer once its
+// rows are deleted from the database. A batch a previous run wrote but didn't
+// commit is finished first: its rows are in the files already, so they are
+// only deleted. It returns the numbers of exported rows and of batches.
+func (c *Cleaner) export(ctx context.Context, before time.Time) (int, int, error) {
+	pending, err := c.cold.Writer.Pending()
+	if err != nil {
+		return 0, 0, err
+	}
+	if len(pending) > 0 {
+		if _, err = c.repo.DeleteExported(ctx, pending); err != nil {
+			return 0, 0, err
+		}
+		if err = c.cold.Writer.Commit(); err != nil {
+			return 0, 0, err
+		}
+	}
+
+	var rows, batches int
+	for {
+		batchRows, err := c.repo.ExportArchive(ctx, before, c.batch.Size, c.cold.Writer.Write)
+		if err != nil {
+			return rows, batches, err
+		}
+		if batchRows > 0 {
+			if err = c.cold.Writer.Commit(); err != nil {
+				return rows, batches, err
 			}
-			c.mu.Unlock()
+		}
+		rows += batchRows
+		batches++
+
+		if batchRows < c.batch.Size {
+			return rows, batches, nil
+		}
 
-			c.logger.Info("archived and deleted old events", zap.Int("count", len(c.storeArchive)))
+		select {
+		case <-ctx.Done():
+			return rows, batches, ctx.Err()
+		case <-time.After(c.batch.Pause):
 		}
 	}
 }
+
+// report logs the events the cleaner would archive.
+func (c *Cleaner) report(ctx context.Context, policy *models.RetentionPolicy) {
+	events, err := c.repo.GetEventsToClean(ctx, policy)
+	if err != nil {
+		c.logger.Warn("cleaner.go - failed to get events to clean", zap.Error(err))
+		return
+	}
+
+	for _, event := range events {
+		c.logger.Info("cleaner.go - dry run: would archive event", zap.Uint("event_id", event.ID),
+			zap.Int("user_id", event.UserID), zap.Time("end", event.End))
+	}
+	c.logger.Info("cleaner.go - dry run: events to archive", zap.Int("count", len(events)),
+		zap.Time("cutoff", policy.Cutoff))
+}
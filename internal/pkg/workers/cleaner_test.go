@@ -0,0 +1,192 @@
+package workers
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+
+	"github.com/avraam311/improved-calendar-service/internal/models"
+)
+
+func TestRetentionPolicy(t *testing.T) {
+	now := time.Date(2025, 9, 1, 3, 0, 0, 0, time.UTC)
+	retention := Retention{
+		MaxAge:    30 * 24 * time.Hour,
+		Overrides: map[int]time.Duration{2: 365 * 24 * time.Hour},
+		LegalHold: []int{3},
+	}
+
+	assert.Equal(t, &models.RetentionPolicy{
+		Cutoff:    time.Date(2025, 8, 2, 3, 0, 0, 0, time.UTC),
+		Overrides: map[int]time.Time{2: time.Date(2024, 9, 1, 3, 0, 0, 0, time.UTC)},
+		LegalHold: []int{3},
+	}, retention.policy(now))
+}
+
+// batchRepo archives the scripted batches in order and stores the ends of
+// the count series.
+type batchRepo struct {
+	batches [][2]int
+	err     error
+	calls   int
+	series  []*models.CountSeries
+	ended   []*models.CountSeries
+	reads   int
+	deleted []models.ArchiveKey
+	policy  *models.RetentionPolicy
+}
+
+func (r *batchRepo) DeleteExported(_ context.Context, keys []models.ArchiveKey) (int, error) {
+	r.deleted = append(r.deleted, keys...)
+	return len(keys), nil
+}
+
+func (r *batchRepo) GetCountSeries(_ context.Context, afterID uint, limit int) ([]*models.CountSeries, error) {
+	r.reads++
+	var series []*models.CountSeries
+	for _, s := range r.series {
+		if s.ID > afterID && len(series) < limit {
+			series = append(series, s)
+		}
+	}
+	return series, nil
+}
+
+func (r *batchRepo) SaveSeriesEnds(_ context.Context, series []*models.CountSeries) error {
+	r.ended = append(r.ended, series...)
+	return nil
+}
+
+func (r *batchRepo) GetEventsToClean(_ context.Context, policy *models.RetentionPolicy) ([]*models.EventToClean, error) {
+	r.policy = policy
+	return nil, nil
+}
+
+func (r *batchRepo) ArchiveEvents(context.Context, *models.RetentionPolicy, int) (int, int, error) {
+	if r.calls == len(r.batches) {
+		return 0, 0, r.err
+	}
+	batch := r.batches[r.calls]
+	r.calls++
+	return batch[0], batch[1], nil
+}
+
+func (r *batchRepo) ExportArchive(_ context.Context, _ time.Time, _ int, write func([]*models.ArchiveRecord) error) (int, error) {
+	if r.calls == len(r.batches) {
+		return 0, r.err
+	}
+	batch := r.batches[r.calls]
+	r.calls++
+	return batch[1], write(make([]*models.ArchiveRecord, batch[1]))
+}
+
+// countWriter counts the records written to the cold archive and the
+// commits.
+type countWriter struct {
+	records int
+	pending []models.ArchiveKey
+	commits int
+}
+
+func (w *countWriter) Write(records []*models.ArchiveRecord) error {
+	w.records += len(records)
+	return nil
+}
+
+func (w *countWriter) Pending() ([]models.ArchiveKey, error) {
+	return w.pending, nil
+}
+
+func (w *countWriter) Commit() error {
+	w.pending = nil
+	w.commits++
+	return nil
+}
+
+func TestCleanerArchivesInBatches(t *testing.T) {
+	repo := &batchRepo{batches: [][2]int{{2, 3}, {2, 2}, {1, 1}}}
+	cleaner := NewCleaner(repo, nil, Retention{}, Batch{Size: 2}, ColdArchive{}, false, nil)
+
+	events, rows, batches, err := cleaner.archive(context.Background(), &models.RetentionPolicy{})
+	assert.NoError(t, err)
+	assert.Equal(t, 5, events)
+	assert.Equal(t, 6, rows)
+	assert.Equal(t, 3, batches)
+
+	// A failed batch stops cleaning and keeps the counts of the archived ones.
+	repo = &batchRepo{batches: [][2]int{{2, 2}}, err: errors.New("connection reset")}
+	cleaner = NewCleaner(repo, nil, Retention{}, Batch{Size: 2}, ColdArchive{}, false, nil)
+
+	events, _, batches, err = cleaner.archive(context.Background(), &models.RetentionPolicy{})
+	assert.Error(t, err)
+	assert.Equal(t, 2, events)
+	assert.Equal(t, 1, batches)
+}
+
+func TestCleanerExportsInBatches(t *testing.T) {
+	repo := &batchRepo{batches: [][2]int{{0, 2}, {0, 1}}}
+	writer := &countWriter{}
+	cleaner := NewCleaner(repo, nil, Retention{}, Batch{Size: 2}, ColdArchive{Writer: writer, After: time.Hour}, false, nil)
+
+	rows, batches, err := cleaner.export(context.Background(), time.Now())
+	assert.NoError(t, err)
+	assert.Equal(t, 3, rows)
+	assert.Equal(t, 2, batches)
+	assert.Equal(t, 3, writer.records)
+	assert.Equal(t, 2, writer.commits)
+	assert.Empty(t, repo.deleted)
+}
+
+func TestCleanerExportFinishesPendingWrite(t *testing.T) {
+	archivedAt := time.Date(2025, 6, 1, 3, 0, 0, 0, time.UTC)
+	pending := []models.ArchiveKey{{ID: 7, ArchivedAt: archivedAt}, {ID: 9, ArchivedAt: archivedAt}}
+	repo := &batchRepo{batches: [][2]int{{0, 1}}}
+	writer := &countWriter{pending: pending}
+	cleaner := NewCleaner(repo, nil, Retention{}, Batch{Size: 2}, ColdArchive{Writer: writer, After: time.Hour}, false, nil)
+
+	// The rows of the pending write are deleted without being written again.
+	rows, _, err := cleaner.export(context.Background(), time.Now())
+	assert.NoError(t, err)
+	assert.Equal(t, pending, repo.deleted)
+	assert.Equal(t, 1, rows)
+	assert.Equal(t, 1, writer.records)
+	assert.Equal(t, 2, writer.commits)
+}
+
+func TestCleanerEndsCountSeries(t *testing.T) {
+	monday := time.Date(2025, 9, 1, 9, 0, 0, 0, time.UTC)
+	berlin := time.Date(2025, 10, 25, 8, 0, 0, 0, time.UTC)
+	repo := &batchRepo{series: []*models.CountSeries{
+		{ID: 1, Date: monday, End: monday.Add(time.Hour), TZ: "UTC", RRule: "FREQ=WEEKLY;BYDAY=MO,WE;COUNT=5"},
+		{ID: 2, Date: monday, End: monday, TZ: "UTC", RRule: "FREQ=HOURLY;COUNT=2"},
+		// The clocks go back on October 26, the occurrences keep 10:00 in Berlin.
+		{ID: 3, Date: berlin, End: berlin.Add(30 * time.Minute), TZ: "Europe/Berlin", RRule: "FREQ=DAILY;COUNT=3"},
+	}}
+	cleaner := NewCleaner(repo, nil, Retention{}, Batch{Size: 2}, ColdArchive{}, false, zap.NewNop())
+
+	ended, err := cleaner.endCountSeries(context.Background(), repo.SaveSeriesEnds)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, ended)
+	assert.Equal(t, 2, repo.reads)
+	assert.Len(t, repo.ended, 2)
+	assert.Equal(t, time.Date(2025, 9, 15, 10, 0, 0, 0, time.UTC), repo.ended[0].SeriesEnd)
+	assert.Equal(t, time.Date(2025, 10, 27, 9, 30, 0, 0, time.UTC), repo.ended[1].SeriesEnd)
+}
+
+func TestCleanerDryRunStoresNoSeriesEnds(t *testing.T) {
+	monday := time.Date(2025, 9, 1, 9, 0, 0, 0, time.UTC)
+	repo := &batchRepo{series: []*models.CountSeries{
+		{ID: 1, Date: monday, End: monday.Add(time.Hour), TZ: "UTC", RRule: "FREQ=WEEKLY;BYDAY=MO,WE;COUNT=5"},
+	}}
+	cleaner := NewCleaner(repo, nil, Retention{}, Batch{Size: 2}, ColdArchive{}, true, zap.NewNop())
+
+	// The end of the series only reaches the report.
+	cleaner.clean(context.Background())
+	assert.Empty(t, repo.ended)
+	assert.Zero(t, repo.calls)
+	assert.Equal(t, map[uint]time.Time{1: time.Date(2025, 9, 15, 10, 0, 0, 0, time.UTC)}, repo.policy.SeriesEnds)
+}
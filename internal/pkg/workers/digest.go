@@ -0,0 +1,186 @@
+package workers
+
+import (
+	"context"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/avraam311/improved-calendar-service/internal/models"
+	"github.com/avraam311/improved-calendar-service/internal/pkg/templates"
+	"github.com/avraam311/improved-calendar-service/internal/pkg/timerange"
+)
+
+const (
+	// digestInterval is how often the digest worker looks for due digests.
+	digestInterval = 5 * time.Minute
+	// defaultDigestAt is the local time digests go out at by default.
+	defaultDigestAt = "07:00"
+)
+
+type digestRepo interface {
+	GetSubscribers(ctx context.Context) ([]*models.UserSettings, error)
+	ClaimDigest(ctx context.Context, userID int, kind string, period time.Time) (bool, error)
+	ReleaseDigest(ctx context.Context, userID int, kind string, period time.Time) error
+}
+
+type agendaI interface {
+	GetEvents(ctx context.Context, eventGet *models.EventGet) ([]*models.Event, error)
+}
+
+// digestPeriod is a digest due for the events in [from, to).
+type digestPeriod struct {
+	kind     string
+	from, to time.Time
+}
+
+// Digest mails the daily and weekly agendas to the users who opted in, once
+// the digest time has come in the time zone of the user. Every digest is
+// claimed in the database before it is sent, so it goes out once. Digests
+// without events are skipped.
+type Digest struct {
+	repo      digestRepo
+	agenda    agendaI
+	channels  channelsI
+	renderer  rendererI
+	weekStart time.Weekday
+	logger    *zap.Logger
+}
+
+func NewDigest(repo digestRepo, agenda agendaI, channels channelsI, renderer rendererI, weekStart time.Weekday, logger *zap.Logger) *Digest {
+	return &Digest{
+		repo:      repo,
+		agenda:    agenda,
+		channels:  channels,
+		renderer:  renderer,
+		weekStart: weekStart,
+		logger:    logger,
+	}
+}
+
+func (d *Digest) Run(ctx context.Context) {
+	ticker := time.NewTicker(digestInterval)
+	defer ticker.Stop()
+
+	for {
+		d.sendDue(ctx, time.Now())
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (d *Digest) sendDue(ctx context.Context, now time.Time) {
+	subscribers, err := d.repo.GetSubscribers(ctx)
+	if err != nil {
+		d.logger.Warn("digest.go - failed to get digest subscribers", zap.Error(err))
+		return
+	}
+
+	for _, settings := range subscribers {
+		for _, period := range duePeriods(settings.Digest, now, d.weekStart) {
+			if ctx.Err() != nil {
+				return
+			}
+			d.send(ctx, settings, period)
+		}
+	}
+}
+
+func (d *Digest) send(ctx context.Context, settings *models.UserSettings, period digestPeriod) {
+	logger := d.logger.With(zap.Int("user_id", settings.UserID), zap.String("kind", period.kind))
+
+	claimed, err := d.repo.ClaimDigest(ctx, settings.UserID, period.kind, period.from)
+	if err != nil {
+		logger.Warn("digest.go - failed to claim digest", zap.Error(err))
+		return
+	}
+	if !claimed {
+		return
+	}
+
+	err = d.deliver(ctx, settings, period)
+	if err == nil {
+		return
+	}
+	logger.Warn("digest.go - failed to send digest", zap.Error(err))
+
+	if err = d.repo.ReleaseDigest(ctx, settings.UserID, period.kind, period.from); err != nil {
+		logger.Warn("digest.go - failed to release digest", zap.Error(err))
+	}
+}
+
+func (d *Digest) deliver(ctx context.Context, settings *models.UserSettings, period digestPeriod) error {
+	events, err := d.agenda.GetEvents(ctx, &models.EventGet{
+		UserID:   settings.UserID,
+		DateFrom: period.from,
+		DateTo:   period.to.Add(-time.Nanosecond),
+	})
+	if err != nil || len(events) == 0 {
+		return err
+	}
+
+	data := &templates.Data{Date: period.from, AllDay: true, Weekly: period.kind == models.DigestWeekly}
+	for _, event := range events {
+		data.Items = append(data.Items, &templates.Data{
+			Event:  event.Event,
+			Date:   event.Date.In(period.from.Location()),
+			AllDay: event.AllDay,
+		})
+	}
+
+	msg, err := d.renderer.Render(templates.TypeDigest, settings.Locale, data)
+	if err != nil {
+		return err
+	}
+
+	return d.channels.Send(ctx, &models.Notification{
+		Channel: models.ChannelEmail,
+		To:      settings.Digest.Mail,
+		Subject: msg.Subject,
+		Text:    msg.Text,
+		HTML:    msg.HTML,
+	})
+}
+
+// duePeriods returns the digests of the user due at now: the daily digest of
+// the current day and, on the first day of the week, the weekly one, as soon
+// as the digest time has passed in the time zone of the user.
+func duePeriods(digest *models.DigestSettings, now time.Time, weekStart time.Weekday) []digestPeriod {
+	if digest == nil || digest.Mail == "" {
+		return nil
+	}
+
+	loc, err := time.LoadLocation(digest.TZ)
+	if err != nil {
+		loc = time.UTC
+	}
+	at := digest.At
+	if at == "" {
+		at = defaultDigestAt
+	}
+	clock, err := time.Parse("15:04", at)
+	if err != nil {
+		return nil
+	}
+
+	dayFrom, dayTo := timerange.Day(now, loc)
+	sendAt := time.Date(dayFrom.Year(), dayFrom.Month(), dayFrom.Day(), clock.Hour(), clock.Minute(), 0, 0, loc)
+	if now.Before(sendAt) {
+		return nil
+	}
+
+	var periods []digestPeriod
+	if digest.Daily {
+		periods = append(periods, digestPeriod{kind: models.DigestDaily, from: dayFrom, to: dayTo})
+	}
+	if digest.Weekly && dayFrom.Weekday() == weekStart {
+		weekFrom, weekTo := timerange.Week(now, loc, weekStart)
+		periods = append(periods, digestPeriod{kind: models.DigestWeekly, from: weekFrom, to: weekTo})
+	}
+
+	return periods
+}
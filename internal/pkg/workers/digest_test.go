@@ -0,0 +1,88 @@
+package workers
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+
+	"github.com/avraam311/improved-calendar-service/internal/models"
+	"github.com/avraam311/improved-calendar-service/internal/pkg/templates"
+)
+
+// fakeAgenda returns the events starting in the inclusive range, like the
+// event service does.
+type fakeAgenda []*models.Event
+
+func (a fakeAgenda) GetEvents(_ context.Context, eventGet *models.EventGet) ([]*models.Event, error) {
+	var events []*models.Event
+	for _, event := range a {
+		if !event.Date.Before(eventGet.DateFrom) && !event.Date.After(eventGet.DateTo) {
+			events = append(events, event)
+		}
+	}
+
+	return events, nil
+}
+
+// itemsRenderer records the events of the rendered digest.
+type itemsRenderer struct {
+	items []string
+}
+
+func (r *itemsRenderer) Render(_, _ string, data *templates.Data) (*templates.Rendered, error) {
+	for _, item := range data.Items {
+		r.items = append(r.items, item.Event)
+	}
+
+	return &templates.Rendered{}, nil
+}
+
+func TestDuePeriods(t *testing.T) {
+	moscow, err := time.LoadLocation("Europe/Moscow")
+	if err != nil {
+		t.Skipf("no time zone data: %v", err)
+	}
+	digest := &models.DigestSettings{Daily: true, Weekly: true, Mail: "alice@example.com", TZ: "Europe/Moscow", At: "08:30"}
+	monday := time.Date(2025, 9, 1, 0, 0, 0, 0, moscow)
+
+	// 08:00 in Moscow is too early.
+	assert.Empty(t, duePeriods(digest, time.Date(2025, 9, 1, 5, 0, 0, 0, time.UTC), time.Monday))
+
+	periods := duePeriods(digest, time.Date(2025, 9, 1, 5, 30, 0, 0, time.UTC), time.Monday)
+	assert.Equal(t, []digestPeriod{
+		{kind: models.DigestDaily, from: monday, to: monday.AddDate(0, 0, 1)},
+		{kind: models.DigestWeekly, from: monday, to: monday.AddDate(0, 0, 7)},
+	}, periods)
+
+	// No weekly digest on Tuesday, and none for a week starting on Sunday.
+	periods = duePeriods(digest, time.Date(2025, 9, 2, 6, 0, 0, 0, time.UTC), time.Monday)
+	assert.Len(t, periods, 1)
+	assert.Equal(t, models.DigestDaily, periods[0].kind)
+	assert.Len(t, duePeriods(digest, time.Date(2025, 9, 1, 6, 0, 0, 0, time.UTC), time.Sunday), 1)
+
+	assert.Empty(t, duePeriods(&models.DigestSettings{Daily: true}, time.Date(2025, 9, 1, 12, 0, 0, 0, time.UTC), time.Monday))
+	assert.Empty(t, duePeriods(nil, time.Date(2025, 9, 1, 12, 0, 0, 0, time.UTC), time.Monday))
+}
+
+func TestDigestLeavesOutNextDay(t *testing.T) {
+	day := time.Date(2025, 9, 1, 0, 0, 0, 0, time.UTC)
+	agenda := fakeAgenda{
+		{Event: "Standup", Date: day.Add(9 * time.Hour)},
+		{Event: "Holiday", Date: day.AddDate(0, 0, 1), AllDay: true},
+		{Event: "Deploy", Date: day.AddDate(0, 0, 1)},
+	}
+	renderer := &itemsRenderer{}
+	channels := &fakeChannels{}
+	digest := NewDigest(nil, agenda, channels, renderer, time.Monday, zap.NewNop())
+	settings := &models.UserSettings{UserID: 1, Digest: &models.DigestSettings{Daily: true, Mail: "alice@example.com"}}
+
+	// The events at midnight on the end of the day belong to the next one.
+	err := digest.deliver(context.Background(), settings,
+		digestPeriod{kind: models.DigestDaily, from: day, to: day.AddDate(0, 0, 1)})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"Standup"}, renderer.items)
+	assert.Equal(t, []string{"alice@example.com"}, channels.sent)
+}
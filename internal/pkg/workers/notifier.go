@@ -2,92 +2,227 @@ package workers
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
-	"sync"
 	"time"
 
 	"github.com/avraam311/improved-calendar-service/internal/models"
+	"github.com/avraam311/improved-calendar-service/internal/pkg/templates"
 	"go.uber.org/zap"
 )
 
-type mailI interface {
-	SendMessage(msg []byte) error
+const (
+	// notifierBatch is the number of due reminders claimed at once.
+	notifierBatch = 100
+	// notifierLease is how long claimed reminders are kept from other
+	// notifiers. It has to cover sending a whole batch, a reminder whose
+	// result isn't saved by then is sent again.
+	notifierLease = 10 * time.Minute
+)
+
+type channelsI interface {
+	Send(ctx context.Context, n *models.Notification) error
+}
+
+type settingsI interface {
+	GetSettings(ctx context.Context, userID int) (*models.UserSettings, error)
 }
 
+type rendererI interface {
+	Render(kind, locale string, data *templates.Data) (*templates.Rendered, error)
+}
+
+type reminderQueue interface {
+	ClaimDue(ctx context.Context, now time.Time, limit int, leaseUntil time.Time) ([]*models.Reminder, error)
+	SaveResult(ctx context.Context, reminder *models.Reminder) error
+}
+
+type reminderScheduler interface {
+	ScheduleReminders(ctx context.Context, IDs ...uint) error
+	RebuildReminders(ctx context.Context) error
+}
+
+// Notifier sends the due reminders of the reminder queue every minute over the
+// channel of each reminder, to the address the user set for it and in the
+// language of the user. The queue lives in the database, so pending reminders
+// survive restarts and several notifiers can run side by side. Failed
+// deliveries are retried with backoff until the retry policy gives up and the
+// reminder goes to the dead letters. Reminders due in the quiet hours or a
+// do-not-disturb period of the user are put off until it ends, unless the
+// event is urgent.
 type Notifier struct {
-	EventsCh chan *models.EventCreate
-	store    map[string]*models.EventCreate
-	mu       sync.Mutex
-	mail     mailI
-	logger   *zap.Logger
+	queue     reminderQueue
+	scheduler reminderScheduler
+	channels  channelsI
+	settings  settingsI
+	renderer  rendererI
+	retry     RetryPolicy
+	logger    *zap.Logger
 }
 
-func NewNotifier(evsCh chan *models.EventCreate, mailI mailI, logger *zap.Logger) *Notifier {
+func NewNotifier(queue reminderQueue, scheduler reminderScheduler, channels channelsI, settings settingsI, renderer rendererI, retry RetryPolicy, logger *zap.Logger) *Notifier {
 	return &Notifier{
-		EventsCh: evsCh,
-		store:    make(map[string]*models.EventCreate),
-		mail:     mailI,
-		logger:   logger,
+		queue:     queue,
+		scheduler: scheduler,
+		channels:  channels,
+		settings:  settings,
+		renderer:  renderer,
+		retry:     retry,
+		logger:    logger,
 	}
 }
 
 func (n *Notifier) Run(ctx context.Context) {
+	if err := n.scheduler.RebuildReminders(ctx); err != nil {
+		n.logger.Warn("worker.go - failed to rebuild reminders", zap.Error(err))
+	}
+
 	ticker := time.NewTicker(time.Minute)
 	defer ticker.Stop()
 
-	go func() {
-		for {
-			select {
-			case <-ctx.Done():
-				return
-			case <-ticker.C:
-				now := time.Now()
-				oneHourLater := now.Add(time.Hour)
-				var toDelete []string
-
-				n.mu.Lock()
-				for key, event := range n.store {
-					if !event.Date.Before(now) && !event.Date.After(oneHourLater) {
-						evByte, err := json.Marshal(event)
-						if err != nil {
-							n.logger.Warn("worker.go - failed to marshal event", zap.Error(err))
-						}
-						err = n.sendToMail(evByte)
-						if err != nil {
-							n.logger.Warn("worker.go - failed to send notification about event", zap.Error(err))
-						} else {
-							toDelete = append(toDelete, key)
-						}
-					}
-				}
-				for _, key := range toDelete {
-					delete(n.store, key)
-				}
-				n.mu.Unlock()
-			}
-		}
-	}()
-
 	for {
+		n.sendDue(ctx)
+
 		select {
 		case <-ctx.Done():
 			return
-		case event := <-n.EventsCh:
-			key := fmt.Sprintf("%s-%s", event.Mail, event.Event)
-			n.mu.Lock()
-			n.store[key] = event
-			n.mu.Unlock()
+		case <-ticker.C:
+		}
+	}
+}
+
+// sendDue claims the due reminders batch by batch, sends them outside of any
+// transaction and saves the result of each one on its own. Then it schedules
+// the next reminder of every event reminded of. Reminders that were put off
+// are sent later and need no scheduling yet.
+func (n *Notifier) sendDue(ctx context.Context) {
+	for ctx.Err() == nil {
+		now := time.Now().UTC()
+		claimed, err := n.queue.ClaimDue(ctx, now, notifierBatch, now.Add(notifierLease))
+		if err != nil {
+			n.logger.Warn("worker.go - failed to claim due reminders", zap.Error(err))
+			return
+		}
+
+		IDs := make([]uint, 0, len(claimed))
+		for _, reminder := range claimed {
+			n.deliver(ctx, reminder)
+			if err = n.queue.SaveResult(ctx, reminder); err != nil {
+				n.logger.Warn("worker.go - failed to save reminder result",
+					zap.Uint("reminder", reminder.ID), zap.Error(err))
+				continue
+			}
+			if reminder.Status != models.ReminderPending && reminder.Status != models.ReminderDeferred {
+				IDs = append(IDs, reminder.EventID)
+			}
+		}
+		if err = n.scheduler.ScheduleReminders(ctx, IDs...); err != nil {
+			n.logger.Warn("worker.go - failed to schedule next reminders", zap.Error(err))
+		}
+
+		if len(claimed) < notifierBatch {
+			return
 		}
 	}
 }
 
-func (n *Notifier) sendToMail(msg []byte) error {
-	mailErr := n.mail.SendMessage(msg)
+// deliver sends the reminder and decides its status: sent, retried after a
+// backoff, or dead once it ran out of attempts. In the quiet time of the user
+// it only puts the reminder off, which doesn't count as an attempt. A deferred
+// reminder is sent once the quiet time ends, even if the event has started by
+// then.
+func (n *Notifier) deliver(ctx context.Context, reminder *models.Reminder) {
+	now := time.Now().UTC()
+	settings, err := n.settings.GetSettings(ctx, reminder.UserID)
+	if err != nil {
+		n.logger.Warn("worker.go - failed to get user settings", zap.Error(err))
+	}
+	if err == nil && !reminder.Urgent {
+		if until := quietUntil(settings.Quiet, now); !until.IsZero() {
+			if reminder.Status == models.ReminderPending {
+				reminder.Status = models.ReminderDeferred
+			}
+			reminder.NextAttemptAt = until.UTC()
+			return
+		}
+	}
+
+	reminder.Attempts++
+	if err == nil {
+		err = n.send(ctx, reminder, settings)
+	}
+	if err == nil {
+		reminder.Status = models.ReminderSent
+		reminder.LastError = ""
+		return
+	}
+
+	reminder.LastError = err.Error()
+	if reminder.Attempts >= n.retry.MaxAttempts {
+		reminder.Status = models.ReminderDead
+		n.logger.Error("worker.go - reminder moved to dead letters",
+			zap.Uint("reminder", reminder.ID), zap.Int("attempts", reminder.Attempts), zap.Error(err))
+		return
+	}
+
+	reminder.Status = models.ReminderRetry
+	reminder.NextAttemptAt = now.Add(n.retry.Delay(reminder.Attempts))
+}
+
+// send renders the reminder in the time zone of its event, like the
+// invitations are, and sends it.
+func (n *Notifier) send(ctx context.Context, reminder *models.Reminder, settings *models.UserSettings) error {
+	loc, err := time.LoadLocation(reminder.TZ)
+	if err != nil {
+		loc = time.UTC
+	}
+	msg, err := n.renderer.Render(templates.TypeReminder, settings.Locale, &templates.Data{
+		Event:  reminder.Event,
+		Date:   reminder.Date.In(loc),
+		AllDay: reminder.AllDay,
+		Offset: reminder.Offset,
+	})
+	if err != nil {
+		n.logger.Warn("worker.go - failed to render reminder", zap.Error(err))
+		return err
+	}
 
-	if mailErr != nil {
-		return fmt.Errorf("failed to send notification to mail - %w", mailErr)
+	err = n.channels.Send(ctx, &models.Notification{
+		Channel:  reminder.Channel,
+		To:       address(reminder, settings),
+		Subject:  msg.Subject,
+		Text:     msg.Text,
+		HTML:     msg.HTML,
+		Reminder: reminder,
+	})
+	if err != nil {
+		n.logger.Warn("worker.go - failed to send notification about event",
+			zap.String("channel", reminder.Channel), zap.Error(err))
+		return fmt.Errorf("failed to send notification to %s - %w", reminder.Channel, err)
 	}
 
 	return nil
 }
+
+// address returns the address of the reminder owner on the reminder channel.
+// Emails go to the mail of the event.
+func address(reminder *models.Reminder, settings *models.UserSettings) string {
+	if reminder.Channel == models.ChannelEmail {
+		return reminder.Mail
+	}
+
+	channels := settings.Channels
+	if channels == nil {
+		return ""
+	}
+
+	switch reminder.Channel {
+	case models.ChannelWebhook:
+		return channels.Webhook
+	case models.ChannelTelegram:
+		return channels.Telegram
+	case models.ChannelSMS:
+		return channels.SMS
+	}
+
+	return ""
+}
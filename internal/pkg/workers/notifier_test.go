@@ -0,0 +1,193 @@
+package workers
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+
+	"github.com/avraam311/improved-calendar-service/internal/models"
+	"github.com/avraam311/improved-calendar-service/internal/pkg/templates"
+)
+
+// fakeQueue hands out the due reminders once and records the saved results.
+type fakeQueue struct {
+	due     []*models.Reminder
+	saved   []models.Reminder
+	saveErr map[uint]error
+}
+
+func (q *fakeQueue) ClaimDue(_ context.Context, now time.Time, limit int, _ time.Time) ([]*models.Reminder, error) {
+	var claimed []*models.Reminder
+	for _, reminder := range q.due {
+		if !reminder.NextAttemptAt.After(now) && len(claimed) < limit {
+			claimed = append(claimed, reminder)
+		}
+	}
+	q.due = nil
+
+	return claimed, nil
+}
+
+func (q *fakeQueue) SaveResult(_ context.Context, reminder *models.Reminder) error {
+	if err := q.saveErr[reminder.ID]; err != nil {
+		return err
+	}
+	q.saved = append(q.saved, *reminder)
+
+	return nil
+}
+
+type fakeScheduler struct {
+	scheduled []uint
+}
+
+func (s *fakeScheduler) ScheduleReminders(_ context.Context, IDs ...uint) error {
+	s.scheduled = append(s.scheduled, IDs...)
+	return nil
+}
+
+func (s *fakeScheduler) RebuildReminders(context.Context) error {
+	return nil
+}
+
+// fakeChannels fails the notifications to the addresses in fail.
+type fakeChannels struct {
+	sent []string
+	fail map[string]bool
+}
+
+func (c *fakeChannels) Send(_ context.Context, n *models.Notification) error {
+	if c.fail[n.To] {
+		return errors.New("smtp unavailable")
+	}
+	c.sent = append(c.sent, n.To)
+
+	return nil
+}
+
+type fakeSettings map[int]*models.UserSettings
+
+func (s fakeSettings) GetSettings(_ context.Context, userID int) (*models.UserSettings, error) {
+	if settings, ok := s[userID]; ok {
+		return settings, nil
+	}
+
+	return &models.UserSettings{UserID: userID}, nil
+}
+
+type fakeRenderer struct{}
+
+func (fakeRenderer) Render(_, _ string, data *templates.Data) (*templates.Rendered, error) {
+	return &templates.Rendered{Subject: data.Event, Text: data.Event}, nil
+}
+
+func newTestNotifier(queue *fakeQueue, channels *fakeChannels, settings fakeSettings) (*Notifier, *fakeScheduler) {
+	scheduler := &fakeScheduler{}
+	retry := RetryPolicy{MaxAttempts: 2, Base: time.Minute, Max: time.Hour}
+
+	return NewNotifier(queue, scheduler, channels, settings, fakeRenderer{}, retry, zap.NewNop()), scheduler
+}
+
+func TestNotifierSavesEachResult(t *testing.T) {
+	now := time.Now().UTC()
+	queue := &fakeQueue{
+		due: []*models.Reminder{
+			{ID: 1, EventID: 7, UserID: 1, Event: "Review", Date: now.Add(time.Hour), Mail: "alice@example.com", Channel: models.ChannelEmail, Status: models.ReminderPending, NextAttemptAt: now},
+			{ID: 2, EventID: 8, UserID: 1, Event: "Standup", Date: now.Add(time.Hour), Mail: "bob@example.com", Channel: models.ChannelEmail, Status: models.ReminderPending, NextAttemptAt: now},
+			{ID: 3, EventID: 9, UserID: 1, Event: "Retro", Date: now.Add(time.Hour), Mail: "carol@example.com", Channel: models.ChannelEmail, Status: models.ReminderRetry, Attempts: 1, NextAttemptAt: now},
+			{ID: 4, EventID: 10, UserID: 1, Event: "Planning", Date: now.Add(time.Hour), Mail: "dave@example.com", Channel: models.ChannelEmail, Status: models.ReminderPending, NextAttemptAt: now},
+		},
+		saveErr: map[uint]error{4: errors.New("connection lost")},
+	}
+	channels := &fakeChannels{fail: map[string]bool{"bob@example.com": true, "carol@example.com": true}}
+	notifier, scheduler := newTestNotifier(queue, channels, fakeSettings{})
+
+	notifier.sendDue(context.Background())
+
+	assert.Equal(t, []string{"alice@example.com", "dave@example.com"}, channels.sent)
+	assert.Len(t, queue.saved, 3)
+	assert.Equal(t, models.ReminderSent, queue.saved[0].Status)
+	assert.Equal(t, models.ReminderRetry, queue.saved[1].Status)
+	assert.Equal(t, "failed to send notification to email - smtp unavailable", queue.saved[1].LastError)
+	assert.Equal(t, models.ReminderDead, queue.saved[2].Status)
+	assert.Equal(t, 2, queue.saved[2].Attempts)
+	// The result for Dave was not saved, the reminder waits for its lease to expire.
+	assert.Equal(t, []uint{7, 8, 9}, scheduler.scheduled)
+}
+
+func TestNotifierSendsDeferredReminderAfterEventStart(t *testing.T) {
+	now := time.Now().UTC()
+	dndEnd := now.Add(30 * time.Minute)
+	reminder := &models.Reminder{ID: 1, EventID: 7, UserID: 1, Event: "Review", Date: now.Add(10 * time.Minute),
+		Mail: "alice@example.com", Channel: models.ChannelEmail, Status: models.ReminderPending, NextAttemptAt: now}
+	queue := &fakeQueue{due: []*models.Reminder{reminder}}
+	channels := &fakeChannels{}
+	settings := fakeSettings{1: {UserID: 1, Quiet: &models.QuietSettings{
+		DND: []models.Interval{{Start: now.Add(-time.Hour), End: dndEnd}},
+	}}}
+	notifier, scheduler := newTestNotifier(queue, channels, settings)
+
+	notifier.sendDue(context.Background())
+
+	assert.Empty(t, channels.sent)
+	assert.Len(t, queue.saved, 1)
+	assert.Equal(t, models.ReminderDeferred, queue.saved[0].Status)
+	assert.Equal(t, 0, queue.saved[0].Attempts)
+	assert.Equal(t, dndEnd, queue.saved[0].NextAttemptAt)
+	assert.Empty(t, scheduler.scheduled)
+
+	// The do-not-disturb period is over and the event has started: the
+	// deferred reminder is still sent.
+	deferred := queue.saved[0]
+	deferred.Date = now.Add(-20 * time.Minute)
+	deferred.NextAttemptAt = now
+	queue.due = []*models.Reminder{&deferred}
+	delete(settings, 1)
+
+	notifier.sendDue(context.Background())
+
+	assert.Equal(t, []string{"alice@example.com"}, channels.sent)
+	assert.Len(t, queue.saved, 2)
+	assert.Equal(t, models.ReminderSent, queue.saved[1].Status)
+	assert.Equal(t, 1, queue.saved[1].Attempts)
+	assert.Equal(t, []uint{7}, scheduler.scheduled)
+}
+
+// dataRenderer records the data of the rendered reminders.
+type dataRenderer struct {
+	data []*templates.Data
+}
+
+func (r *dataRenderer) Render(_, _ string, data *templates.Data) (*templates.Rendered, error) {
+	r.data = append(r.data, data)
+	return &templates.Rendered{}, nil
+}
+
+func TestNotifierRendersInEventTimeZone(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Skipf("no time zone data: %v", err)
+	}
+	now := time.Now().UTC()
+	date := time.Date(2025, 9, 1, 22, 0, 0, 0, time.UTC)
+	queue := &fakeQueue{due: []*models.Reminder{
+		{ID: 1, EventID: 7, UserID: 1, Event: "Holiday", Date: date, Mail: "alice@example.com",
+			Channel: models.ChannelEmail, Status: models.ReminderPending, NextAttemptAt: now,
+			TZ: "Europe/Berlin", AllDay: true},
+	}}
+	renderer := &dataRenderer{}
+	notifier := NewNotifier(queue, &fakeScheduler{}, &fakeChannels{}, fakeSettings{}, renderer,
+		RetryPolicy{MaxAttempts: 2, Base: time.Minute, Max: time.Hour}, zap.NewNop())
+
+	// Midnight in Berlin is still the previous day in UTC.
+	notifier.sendDue(context.Background())
+
+	assert.Len(t, renderer.data, 1)
+	assert.Equal(t, time.Date(2025, 9, 2, 0, 0, 0, 0, berlin), renderer.data[0].Date)
+	assert.Equal(t, berlin, renderer.data[0].Date.Location())
+	assert.True(t, renderer.data[0].AllDay)
+}
@@ -0,0 +1,69 @@
+package workers
+
+import (
+	"time"
+
+	"github.com/avraam311/improved-calendar-service/internal/models"
+)
+
+// quietUntil returns the end of the quiet time of the user that now falls
+// into, or the zero time if the user may be notified now. Quiet hours are
+// evaluated in the time zone of the settings and end the next day when they
+// span midnight. Quiet hours and do-not-disturb periods that follow one
+// another are skipped together.
+func quietUntil(quiet *models.QuietSettings, now time.Time) time.Time {
+	if quiet == nil {
+		return time.Time{}
+	}
+
+	loc, err := time.LoadLocation(quiet.TZ)
+	if err != nil {
+		loc = time.UTC
+	}
+	start, errStart := time.Parse("15:04", quiet.Start)
+	end, errEnd := time.Parse("15:04", quiet.End)
+	hours := errStart == nil && errEnd == nil && !start.Equal(end)
+
+	until := now
+	for range len(quiet.DND) + 2 {
+		moved := false
+		if hours {
+			if hoursEnd, ok := quietHoursEnd(start, end, until.In(loc)); ok {
+				until = hoursEnd
+				moved = true
+			}
+		}
+		for _, dnd := range quiet.DND {
+			if !until.Before(dnd.Start) && until.Before(dnd.End) {
+				until = dnd.End
+				moved = true
+			}
+		}
+		if !moved {
+			break
+		}
+	}
+
+	if until.Equal(now) {
+		return time.Time{}
+	}
+	return until
+}
+
+// quietHoursEnd returns the end of the quiet hours from start to end that
+// local falls into, checking the ones that began the day before as well.
+func quietHoursEnd(start, end, local time.Time) (time.Time, bool) {
+	for _, days := range []int{-1, 0} {
+		day := local.AddDate(0, 0, days)
+		from := time.Date(day.Year(), day.Month(), day.Day(), start.Hour(), start.Minute(), 0, 0, local.Location())
+		to := time.Date(day.Year(), day.Month(), day.Day(), end.Hour(), end.Minute(), 0, 0, local.Location())
+		if !to.After(from) {
+			to = time.Date(day.Year(), day.Month(), day.Day()+1, end.Hour(), end.Minute(), 0, 0, local.Location())
+		}
+		if !local.Before(from) && local.Before(to) {
+			return to, true
+		}
+	}
+
+	return time.Time{}, false
+}
@@ -0,0 +1,37 @@
+package workers
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/avraam311/improved-calendar-service/internal/models"
+)
+
+func TestQuietUntil(t *testing.T) {
+	moscow, err := time.LoadLocation("Europe/Moscow")
+	if err != nil {
+		t.Skipf("no time zone data: %v", err)
+	}
+	quiet := &models.QuietSettings{TZ: "Europe/Moscow", Start: "22:00", End: "08:00"}
+	morning := time.Date(2025, 9, 2, 8, 0, 0, 0, moscow)
+
+	// 23:30 and 03:00 in Moscow are quiet until 08:00, 12:00 is not.
+	assert.True(t, morning.Equal(quietUntil(quiet, time.Date(2025, 9, 1, 20, 30, 0, 0, time.UTC))))
+	assert.True(t, morning.Equal(quietUntil(quiet, time.Date(2025, 9, 2, 0, 0, 0, 0, time.UTC))))
+	assert.True(t, quietUntil(quiet, time.Date(2025, 9, 2, 9, 0, 0, 0, time.UTC)).IsZero())
+	assert.True(t, quietUntil(quiet, time.Date(2025, 9, 2, 5, 0, 0, 0, time.UTC)).IsZero())
+
+	// A do-not-disturb period right after the quiet hours puts it off further.
+	quiet.DND = []models.Interval{{Start: morning.Add(-time.Hour), End: morning.Add(2 * time.Hour)}}
+	assert.True(t, morning.Add(2*time.Hour).Equal(quietUntil(quiet, time.Date(2025, 9, 2, 0, 0, 0, 0, time.UTC))))
+	assert.True(t, morning.Add(2*time.Hour).Equal(quietUntil(quiet, time.Date(2025, 9, 2, 5, 30, 0, 0, time.UTC))))
+
+	// Hours within one day, and no quiet hours when they start as they end.
+	assert.True(t, quietUntil(&models.QuietSettings{Start: "13:00", End: "14:00"}, time.Date(2025, 9, 2, 12, 0, 0, 0, time.UTC)).IsZero())
+	assert.True(t, time.Date(2025, 9, 2, 14, 0, 0, 0, time.UTC).Equal(
+		quietUntil(&models.QuietSettings{Start: "13:00", End: "14:00"}, time.Date(2025, 9, 2, 13, 15, 0, 0, time.UTC))))
+	assert.True(t, quietUntil(&models.QuietSettings{Start: "13:00", End: "13:00"}, time.Date(2025, 9, 2, 13, 15, 0, 0, time.UTC)).IsZero())
+	assert.True(t, quietUntil(nil, time.Date(2025, 9, 2, 13, 15, 0, 0, time.UTC)).IsZero())
+}
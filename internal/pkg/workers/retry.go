@@ -0,0 +1,35 @@
+package workers
+
+import (
+	"math/rand/v2"
+	"time"
+)
+
+// RetryPolicy bounds the delivery attempts of a reminder. The n-th retry
+// waits Base*2^(n-1), at most Max, of which a random half is jitter so the
+// failed reminders of one batch don't hit the channel again all at once.
+type RetryPolicy struct {
+	MaxAttempts int
+	Base        time.Duration
+	Max         time.Duration
+}
+
+// Delay returns the wait before the next attempt after the given number of
+// failed attempts.
+func (p RetryPolicy) Delay(attempts int) time.Duration {
+	delay := p.Max
+	if attempts < 1 {
+		attempts = 1
+	}
+	if attempts <= 32 {
+		if d := p.Base << (attempts - 1); d > 0 && d < p.Max {
+			delay = d
+		}
+	}
+	if delay <= 0 {
+		return 0
+	}
+
+	half := delay / 2
+	return delay - half + rand.N(half+1)
+}
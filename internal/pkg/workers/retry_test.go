@@ -0,0 +1,31 @@
+package workers
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRetryPolicyDelay(t *testing.T) {
+	policy := RetryPolicy{MaxAttempts: 5, Base: time.Minute, Max: time.Hour}
+
+	tests := []struct {
+		attempts int
+		max      time.Duration
+	}{
+		{1, time.Minute},
+		{2, 2 * time.Minute},
+		{4, 8 * time.Minute},
+		{7, time.Hour},
+		{100, time.Hour},
+	}
+
+	for _, tt := range tests {
+		for range 20 {
+			delay := policy.Delay(tt.attempts)
+			assert.GreaterOrEqual(t, delay, tt.max/2, "attempts %d", tt.attempts)
+			assert.LessOrEqual(t, delay, tt.max, "attempts %d", tt.attempts)
+		}
+	}
+}
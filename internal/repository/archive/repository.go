@@ -0,0 +1,471 @@
+package archive
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+
+	"github.com/avraam311/improved-calendar-service/internal/models"
+)
+
+var (
+	ErrArchivedEventNotFound = errors.New("archived event not found")
+	ErrRestoreConflict       = errors.New("archived event conflicts with an existing event")
+)
+
+const (
+	// uniqueViolation and exclusionViolation are the SQLSTATEs of a violated
+	// unique index and exclusion constraint.
+	uniqueViolation    = "23505"
+	exclusionViolation = "23P01"
+)
+
+// archiveColumns are the columns events keep in the archive.
+const archiveColumns = `id, user_id, event, date, end_date, all_day, tz, mail, rrule, exdates, uid, parent_id,
+		    recurrence_id, cancelled, exclusive, reminders, urgent, created_at`
+
+type DB interface {
+	Exec(ctx context.Context, sql string, arguments ...any) (pgconn.CommandTag, error)
+	Query(ctx context.Context, sql string, optionsAndArgs ...any) (pgx.Rows, error)
+	QueryRow(ctx context.Context, sql string, optionsAndArgs ...any) pgx.Row
+	Begin(ctx context.Context) (pgx.Tx, error)
+}
+
+type Repository struct {
+	db DB
+}
+
+func New(db DB) *Repository {
+	return &Repository{
+		db: db,
+	}
+}
+
+// dueEvents selects the series and single events that ended before the
+// cutoff of the retention policy, $1 to $6. A series ends with its UNTIL date
+// or the stored or given end of its last occurrence when it is bounded by a COUNT, so
+// endless series are kept, as are series with an override ending after the
+// cutoff. Restored events are kept until their restore is past the cutoff.
+const dueEvents = `
+		FROM events e
+		LEFT JOIN unnest($2::int[], $3::timestamp[]) AS o (user_id, cutoff) ON o.user_id = e.user_id
+		LEFT JOIN unnest($5::int[], $6::timestamp[]) AS s (id, series_end) ON s.id = e.id
+		WHERE e.parent_id IS NULL
+		  AND e.user_id <> ALL($4::int[])
+		  AND CASE
+		      WHEN e.rrule IS NULL THEN e.end_date
+		      WHEN e.series_end IS NOT NULL THEN e.series_end
+		      WHEN s.series_end IS NOT NULL THEN s.series_end
+		      ELSE to_date(substring(e.rrule FROM 'UNTIL=([0-9]{8})'), 'YYYYMMDD') + interval '1 day' + (e.end_date - e.date)
+		  END < COALESCE(o.cutoff, $1)
+		  AND (e.restored_at IS NULL OR e.restored_at < COALESCE(o.cutoff, $1))
+		  AND NOT EXISTS (
+		      SELECT 1 FROM events c WHERE c.parent_id = e.id AND c.end_date >= COALESCE(o.cutoff, $1)
+		  )
+`
+
+// GetEventsToClean returns the events the retention policy would archive.
+func (r *Repository) GetEventsToClean(ctx context.Context, policy *models.RetentionPolicy) ([]*models.EventToClean, error) {
+	query := `
+		SELECT e.id, e.user_id, e.event, e.date, e.end_date, e.mail, e.created_at` + dueEvents + `
+		ORDER BY e.end_date;
+	`
+
+	rows, err := r.db.Query(ctx, query, policyArgs(policy)...)
+	if err != nil {
+		return nil, fmt.Errorf("repository/GetEventsToClean - %w", err)
+	}
+	defer rows.Close()
+
+	events := []*models.EventToClean{}
+	for rows.Next() {
+		var e models.EventToClean
+		if err := rows.Scan(&e.ID, &e.UserID, &e.Event, &e.Date, &e.End, &e.Mail, &e.CreatedAt); err != nil {
+			return nil, fmt.Errorf("repository/GetEventsToClean - %w", err)
+		}
+		events = append(events, &e)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("repository/GetEventsToClean - %w", err)
+	}
+
+	return events, nil
+}
+
+// ArchiveEvents moves up to limit events the retention policy selects, the
+// ones that ended first, along with their overrides to the archive in a
+// single statement, so an event is either archived or kept. The attendees of
+// each row are kept with it in the archive, the statement sees them before the
+// foreign key deletes them with the event. Events locked by a concurrent
+// cleaner are skipped. It returns the number of archived events
+// and of archived rows, which include the overrides.
+func (r *Repository) ArchiveEvents(ctx context.Context, policy *models.RetentionPolicy, limit int) (int, int, error) {
+	query := `
+		WITH due AS (
+		    SELECT e.id` + dueEvents + `
+		    ORDER BY e.end_date
+		    LIMIT $7
+		    FOR UPDATE OF e SKIP LOCKED
+		), moved AS (
+		    DELETE FROM events
+		    WHERE id IN (SELECT id FROM due) OR parent_id IN (SELECT id FROM due)
+		    RETURNING ` + archiveColumns + `, updated_at
+		), archived AS (
+		    INSERT INTO events_archive (` + archiveColumns + `, updated_at, attendees)
+		    SELECT ` + archiveColumns + `, updated_at, (
+		        SELECT jsonb_agg(jsonb_build_object(
+		            'id', a.id, 'user_id', a.user_id, 'email', a.email, 'status', a.status,
+		            'created_at', a.created_at, 'updated_at', a.updated_at
+		        ) ORDER BY a.id)
+		        FROM event_attendees a
+		        WHERE a.event_id = m.id
+		    )
+		    FROM moved m
+		    RETURNING parent_id
+		)
+		SELECT count(*) FILTER (WHERE parent_id IS NULL), count(*) FROM archived;
+	`
+
+	var events, rows int
+	err := r.db.QueryRow(ctx, query, append(policyArgs(policy), limit)...).Scan(&events, &rows)
+	if err != nil {
+		return 0, 0, fmt.Errorf("repository/ArchiveEvents - %w", err)
+	}
+
+	return events, rows, nil
+}
+
+// GetCountSeries returns up to limit series bounded by a COUNT whose end
+// isn't stored yet, in the order of their IDs starting after afterID.
+func (r *Repository) GetCountSeries(ctx context.Context, afterID uint, limit int) ([]*models.CountSeries, error) {
+	query := `
+		SELECT id, date, end_date, tz, rrule
+		FROM events
+		WHERE parent_id IS NULL AND series_end IS NULL AND rrule LIKE '%COUNT=%' AND id > $1
+		ORDER BY id
+		LIMIT $2;
+	`
+
+	rows, err := r.db.Query(ctx, query, afterID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("repository/GetCountSeries - %w", err)
+	}
+	defer rows.Close()
+
+	series := []*models.CountSeries{}
+	for rows.Next() {
+		var s models.CountSeries
+		if err = rows.Scan(&s.ID, &s.Date, &s.End, &s.TZ, &s.RRule); err != nil {
+			return nil, fmt.Errorf("repository/GetCountSeries - %w", err)
+		}
+		series = append(series, &s)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("repository/GetCountSeries - %w", err)
+	}
+
+	return series, nil
+}
+
+// SaveSeriesEnds stores the computed ends of the series. A series whose start
+// or rule changed since it was read keeps no end, it is computed again.
+func (r *Repository) SaveSeriesEnds(ctx context.Context, series []*models.CountSeries) error {
+	if len(series) == 0 {
+		return nil
+	}
+
+	IDs := make([]uint, 0, len(series))
+	dates := make([]time.Time, 0, len(series))
+	rules := make([]string, 0, len(series))
+	ends := make([]time.Time, 0, len(series))
+	for _, s := range series {
+		IDs = append(IDs, s.ID)
+		dates = append(dates, s.Date)
+		rules = append(rules, s.RRule)
+		ends = append(ends, s.SeriesEnd)
+	}
+
+	query := `
+		UPDATE events e
+		SET series_end = s.series_end
+		FROM unnest($1::int[], $2::timestamp[], $3::text[], $4::timestamp[]) AS s (id, date, rrule, series_end)
+		WHERE e.id = s.id AND e.date = s.date AND e.rrule = s.rrule;
+	`
+	if _, err := r.db.Exec(ctx, query, IDs, dates, rules, ends); err != nil {
+		return fmt.Errorf("repository/SaveSeriesEnds - %w", err)
+	}
+
+	return nil
+}
+
+// GetArchivedEvents returns the archived series and single events of the
+// user, the latest first.
+func (r *Repository) GetArchivedEvents(ctx context.Context, get *models.ArchiveGet) ([]*models.ArchivedEvent, error) {
+	query := `
+		SELECT id, user_id, event, date, end_date, all_day, tz, mail, COALESCE(rrule, ''), COALESCE(uid, ''),
+		    created_at, archived_at
+		FROM events_archive
+		WHERE user_id = $1 AND parent_id IS NULL
+		  AND ($2 = '' OR strpos(lower(event), lower($2)) > 0)
+		  AND ($3::timestamp IS NULL OR rrule IS NOT NULL OR end_date > $3 OR date >= $3)
+		  AND ($4::timestamp IS NULL OR date <= $4)
+		ORDER BY date DESC, id DESC
+		LIMIT $5 OFFSET $6;
+	`
+
+	rows, err := r.db.Query(ctx, query, get.UserID, get.Query, optionalTime(get.DateFrom), optionalTime(get.DateTo),
+		get.Limit, get.Offset)
+	if err != nil {
+		return nil, fmt.Errorf("repository/GetArchivedEvents - %w", err)
+	}
+	defer rows.Close()
+
+	events := []*models.ArchivedEvent{}
+	for rows.Next() {
+		var e models.ArchivedEvent
+		err = rows.Scan(&e.ID, &e.UserID, &e.Event, &e.Date, &e.End, &e.AllDay, &e.TZ, &e.Mail, &e.RRule, &e.UID,
+			&e.CreatedAt, &e.ArchivedAt)
+		if err != nil {
+			return nil, fmt.Errorf("repository/GetArchivedEvents - %w", err)
+		}
+		events = append(events, &e)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("repository/GetArchivedEvents - %w", err)
+	}
+
+	return events, nil
+}
+
+// RestoreEvent moves the archived event of the user along with its overrides
+// and their attendees back to the events under the same IDs. The restore is
+// recorded, so the retention of the event starts over.
+func (r *Repository) RestoreEvent(ctx context.Context, userID int, ID uint) (uint, error) {
+	query := `
+		WITH moved AS (
+		    DELETE FROM events_archive
+		    WHERE (id = $1 AND user_id = $2 AND parent_id IS NULL)
+		       OR parent_id = (SELECT id FROM events_archive WHERE id = $1 AND user_id = $2 AND parent_id IS NULL)
+		    RETURNING ` + archiveColumns + `, attendees
+		), restored AS (
+		    INSERT INTO events (` + archiveColumns + `, restored_at)
+		    SELECT ` + archiveColumns + `, CURRENT_TIMESTAMP FROM moved
+		    RETURNING id
+		), attendees AS (
+		    INSERT INTO event_attendees (id, event_id, user_id, email, status, created_at, updated_at)
+		    SELECT a.id, m.id, a.user_id, a.email, a.status, a.created_at, a.updated_at
+		    FROM moved m
+		    JOIN restored r ON r.id = m.id
+		    CROSS JOIN jsonb_to_recordset(m.attendees)
+		        AS a (id int, user_id int, email text, status text, created_at timestamp, updated_at timestamp)
+		)
+		SELECT count(*) FROM restored;
+	`
+
+	var restored int
+	err := r.db.QueryRow(ctx, query, ID, userID).Scan(&restored)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && (pgErr.Code == uniqueViolation || pgErr.Code == exclusionViolation) {
+			return 0, ErrRestoreConflict
+		}
+		return 0, fmt.Errorf("repository/RestoreEvent - %w", err)
+	}
+	if restored == 0 {
+		return 0, ErrArchivedEventNotFound
+	}
+
+	return ID, nil
+}
+
+// ExportArchive hands up to limit rows archived before the cutoff to write
+// and deletes them from the archive once write succeeds. The rows stay locked
+// until then, so concurrent cleaners skip them, and a failed write leaves them
+// in the archive. Rows written but left in the archive because the commit
+// failed are deleted with DeleteExported. It returns the number of exported
+// rows.
+func (r *Repository) ExportArchive(ctx context.Context, before time.Time, limit int, write func([]*models.ArchiveRecord) error) (int, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("repository/ExportArchive - %w", err)
+	}
+	defer func() {
+		_ = tx.Rollback(ctx)
+	}()
+
+	query := `
+		SELECT id, user_id, event, date, end_date, all_day, tz, mail, COALESCE(rrule, ''), exdates, COALESCE(uid, ''),
+		    parent_id, recurrence_id, cancelled, exclusive, reminders, urgent, attendees, created_at, updated_at, archived_at
+		FROM events_archive
+		WHERE archived_at < $1
+		ORDER BY id
+		LIMIT $2
+		FOR UPDATE SKIP LOCKED;
+	`
+	rows, err := tx.Query(ctx, query, before, limit)
+	if err != nil {
+		return 0, fmt.Errorf("repository/ExportArchive - %w", err)
+	}
+	records, err := scanRecords(rows)
+	if err != nil {
+		return 0, fmt.Errorf("repository/ExportArchive - %w", err)
+	}
+	if len(records) == 0 {
+		return 0, nil
+	}
+
+	if err = write(records); err != nil {
+		return 0, fmt.Errorf("repository/ExportArchive - %w", err)
+	}
+
+	IDs := make([]uint, 0, len(records))
+	for _, record := range records {
+		IDs = append(IDs, record.ID)
+	}
+	if _, err = tx.Exec(ctx, `DELETE FROM events_archive WHERE id = ANY($1);`, IDs); err != nil {
+		return 0, fmt.Errorf("repository/ExportArchive - %w", err)
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return 0, fmt.Errorf("repository/ExportArchive - %w", err)
+	}
+
+	return len(records), nil
+}
+
+// DeleteExported deletes the rows already written to the cold archive. Rows
+// restored and archived again since have another archiving time and are kept.
+// It returns the number of deleted rows.
+func (r *Repository) DeleteExported(ctx context.Context, keys []models.ArchiveKey) (int, error) {
+	IDs := make([]uint, 0, len(keys))
+	archivedAt := make([]time.Time, 0, len(keys))
+	for _, key := range keys {
+		IDs = append(IDs, key.ID)
+		archivedAt = append(archivedAt, key.ArchivedAt)
+	}
+
+	query := `
+		DELETE FROM events_archive a
+		USING unnest($1::int[], $2::timestamp[]) AS k (id, archived_at)
+		WHERE a.id = k.id AND a.archived_at = k.archived_at;
+	`
+	cmdTag, err := r.db.Exec(ctx, query, IDs, archivedAt)
+	if err != nil {
+		return 0, fmt.Errorf("repository/DeleteExported - %w", err)
+	}
+
+	return int(cmdTag.RowsAffected()), nil
+}
+
+// ImportArchive stores the records in the archive in one transaction. Rows
+// already in the archive or the events are skipped, so a file can be imported
+// again. Imported rows count as archived at the time of the import, so the
+// cleaner doesn't export them back to the cold archive before they could be
+// restored. It returns the number of imported rows.
+func (r *Repository) ImportArchive(ctx context.Context, records []*models.ArchiveRecord) (int, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("repository/ImportArchive - %w", err)
+	}
+	defer func() {
+		_ = tx.Rollback(ctx)
+	}()
+
+	query := `
+		INSERT INTO events_archive (
+		    id, user_id, event, date, end_date, all_day, tz, mail, rrule, exdates, uid, parent_id, recurrence_id,
+		    cancelled, exclusive, reminders, urgent, attendees, created_at, updated_at, archived_at
+		)
+		SELECT $1, $2, $3, $4, $5, $6, $7, $8, NULLIF($9, ''), COALESCE($10::timestamp[], '{}'), NULLIF($11, ''), $12,
+		    $13, $14, $15, $16, $17, $18, $19, $20, CURRENT_TIMESTAMP
+		WHERE NOT EXISTS (SELECT 1 FROM events WHERE id = $1)
+		ON CONFLICT (id) DO NOTHING;
+	`
+	imported := 0
+	for _, rec := range records {
+		var reminders, attendees any
+		if rec.Reminders != nil {
+			reminders = rec.Reminders
+		}
+		if rec.Attendees != nil {
+			attendees = rec.Attendees
+		}
+
+		cmdTag, err := tx.Exec(ctx, query, rec.ID, rec.UserID, rec.Event, rec.Date, rec.End, rec.AllDay, rec.TZ,
+			rec.Mail, rec.RRule, rec.ExDates, rec.UID, rec.ParentID, rec.RecurrenceID, rec.Cancelled, rec.Exclusive,
+			reminders, rec.Urgent, attendees, rec.CreatedAt, rec.UpdatedAt)
+		if err != nil {
+			return 0, fmt.Errorf("repository/ImportArchive - %w", err)
+		}
+		imported += int(cmdTag.RowsAffected())
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return 0, fmt.Errorf("repository/ImportArchive - %w", err)
+	}
+
+	return imported, nil
+}
+
+func scanRecords(rows pgx.Rows) ([]*models.ArchiveRecord, error) {
+	defer rows.Close()
+
+	records := []*models.ArchiveRecord{}
+	for rows.Next() {
+		var rec models.ArchiveRecord
+		err := rows.Scan(&rec.ID, &rec.UserID, &rec.Event, &rec.Date, &rec.End, &rec.AllDay, &rec.TZ, &rec.Mail,
+			&rec.RRule, &rec.ExDates, &rec.UID, &rec.ParentID, &rec.RecurrenceID, &rec.Cancelled, &rec.Exclusive,
+			&rec.Reminders, &rec.Urgent, &rec.Attendees, &rec.CreatedAt, &rec.UpdatedAt, &rec.ArchivedAt)
+		if err != nil {
+			return nil, err
+		}
+		records = append(records, &rec)
+	}
+
+	return records, rows.Err()
+}
+
+// policyArgs returns the arguments of dueEvents: the default cutoff, the users
+// with their own cutoffs, the users on legal hold and the given series ends.
+func policyArgs(policy *models.RetentionPolicy) []any {
+	userIDs := make([]int, 0, len(policy.Overrides))
+	for userID := range policy.Overrides {
+		userIDs = append(userIDs, userID)
+	}
+	sort.Ints(userIDs)
+	cutoffs := make([]time.Time, 0, len(userIDs))
+	for _, userID := range userIDs {
+		cutoffs = append(cutoffs, policy.Overrides[userID])
+	}
+	legalHold := policy.LegalHold
+	if legalHold == nil {
+		legalHold = []int{}
+	}
+	seriesIDs := make([]uint, 0, len(policy.SeriesEnds))
+	for ID := range policy.SeriesEnds {
+		seriesIDs = append(seriesIDs, ID)
+	}
+	sort.Slice(seriesIDs, func(i, j int) bool {
+		return seriesIDs[i] < seriesIDs[j]
+	})
+	ends := make([]time.Time, 0, len(seriesIDs))
+	for _, ID := range seriesIDs {
+		ends = append(ends, policy.SeriesEnds[ID])
+	}
+
+	return []any{policy.Cutoff, userIDs, cutoffs, legalHold, seriesIDs, ends}
+}
+
+// optionalTime passes the zero time as NULL.
+func optionalTime(t time.Time) any {
+	if t.IsZero() {
+		return nil
+	}
+
+	return t
+}
@@ -0,0 +1,286 @@
+package archive
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/pashagolub/pgxmock/v4"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/avraam311/improved-calendar-service/internal/models"
+)
+
+func newTestRepo(t *testing.T) (*Repository, pgxmock.PgxPoolIface) {
+	mock, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatalf("failed to create pgxmock: %v", err)
+	}
+
+	return New(mock), mock
+}
+
+func TestRepositoryGetEventsToCleanAppliesOverrides(t *testing.T) {
+	repo, mock := newTestRepo(t)
+	defer mock.Close()
+
+	cutoff := time.Date(2025, 8, 2, 3, 0, 0, 0, time.UTC)
+	longer := time.Date(2024, 9, 1, 3, 0, 0, 0, time.UTC)
+	shorter := time.Date(2025, 8, 25, 3, 0, 0, 0, time.UTC)
+	policy := &models.RetentionPolicy{Cutoff: cutoff, Overrides: map[int]time.Time{4: shorter, 2: longer}}
+	date := time.Date(2025, 7, 1, 9, 0, 0, 0, time.UTC)
+
+	mock.ExpectQuery("FROM events e").
+		WithArgs(cutoff, []int{2, 4}, []time.Time{longer, shorter}, []int{}, []uint{}, []time.Time{}).
+		WillReturnRows(pgxmock.NewRows([]string{"id", "user_id", "event", "date", "end_date", "mail", "created_at"}).
+			AddRow(uint(3), 1, "Retro", date, date.Add(time.Hour), "alice@example.com", date.AddDate(0, -1, 0)))
+
+	events, err := repo.GetEventsToClean(context.Background(), policy)
+	assert.NoError(t, err)
+	assert.Len(t, events, 1)
+	assert.Equal(t, date.Add(time.Hour), events[0].End)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRepositoryGetEventsToCleanUsesGivenSeriesEnds(t *testing.T) {
+	repo, mock := newTestRepo(t)
+	defer mock.Close()
+
+	cutoff := time.Date(2025, 8, 2, 3, 0, 0, 0, time.UTC)
+	ended := time.Date(2025, 7, 14, 10, 0, 0, 0, time.UTC)
+	policy := &models.RetentionPolicy{Cutoff: cutoff, SeriesEnds: map[uint]time.Time{9: ended, 5: cutoff}}
+
+	mock.ExpectQuery(`(?s)LEFT JOIN unnest\(\$5::int\[\], \$6::timestamp\[\]\) AS s.*WHEN s.series_end IS NOT NULL THEN s.series_end`).
+		WithArgs(cutoff, []int{}, []time.Time{}, []int{}, []uint{5, 9}, []time.Time{cutoff, ended}).
+		WillReturnRows(pgxmock.NewRows([]string{"id", "user_id", "event", "date", "end_date", "mail", "created_at"}))
+
+	events, err := repo.GetEventsToClean(context.Background(), policy)
+	assert.NoError(t, err)
+	assert.Empty(t, events)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRepositoryArchiveEvents(t *testing.T) {
+	repo, mock := newTestRepo(t)
+	defer mock.Close()
+
+	cutoff := time.Date(2025, 8, 2, 3, 0, 0, 0, time.UTC)
+	policy := &models.RetentionPolicy{Cutoff: cutoff, LegalHold: []int{3}}
+
+	mock.ExpectQuery(`(?s)WHEN e.series_end IS NOT NULL THEN e.series_end.*e.restored_at IS NULL OR e.restored_at < COALESCE\(o.cutoff, \$1\).*FOR UPDATE OF e SKIP LOCKED.*INSERT INTO events_archive \(.*attendees\).*FROM event_attendees a`).
+		WithArgs(cutoff, []int{}, []time.Time{}, []int{3}, []uint{}, []time.Time{}, 500).
+		WillReturnRows(pgxmock.NewRows([]string{"events", "rows"}).AddRow(500, 512))
+
+	events, rows, err := repo.ArchiveEvents(context.Background(), policy, 500)
+	assert.NoError(t, err)
+	assert.Equal(t, 500, events)
+	assert.Equal(t, 512, rows)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRepositoryGetCountSeries(t *testing.T) {
+	repo, mock := newTestRepo(t)
+	defer mock.Close()
+
+	date := time.Date(2025, 9, 1, 9, 0, 0, 0, time.UTC)
+
+	mock.ExpectQuery("series_end IS NULL").
+		WithArgs(uint(7), 100).
+		WillReturnRows(pgxmock.NewRows([]string{"id", "date", "end_date", "tz", "rrule"}).
+			AddRow(uint(8), date, date.Add(time.Hour), "UTC", "FREQ=DAILY;COUNT=10"))
+
+	series, err := repo.GetCountSeries(context.Background(), 7, 100)
+	assert.NoError(t, err)
+	assert.Len(t, series, 1)
+	assert.Equal(t, "FREQ=DAILY;COUNT=10", series[0].RRule)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRepositorySaveSeriesEnds(t *testing.T) {
+	repo, mock := newTestRepo(t)
+	defer mock.Close()
+
+	date := time.Date(2025, 9, 1, 9, 0, 0, 0, time.UTC)
+	end := time.Date(2025, 9, 10, 10, 0, 0, 0, time.UTC)
+	series := []*models.CountSeries{{ID: 8, Date: date, End: date.Add(time.Hour), TZ: "UTC",
+		RRule: "FREQ=DAILY;COUNT=10", SeriesEnd: end}}
+
+	mock.ExpectExec("SET series_end = s.series_end").
+		WithArgs([]uint{8}, []time.Time{date}, []string{"FREQ=DAILY;COUNT=10"}, []time.Time{end}).
+		WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+
+	assert.NoError(t, repo.SaveSeriesEnds(context.Background(), series))
+	assert.NoError(t, repo.SaveSeriesEnds(context.Background(), nil))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRepositoryGetArchivedEvents(t *testing.T) {
+	repo, mock := newTestRepo(t)
+	defer mock.Close()
+
+	date := time.Date(2025, 3, 1, 9, 0, 0, 0, time.UTC)
+	archivedAt := time.Date(2025, 9, 1, 0, 0, 0, 0, time.UTC)
+	get := &models.ArchiveGet{UserID: 1, Query: "review", DateTo: archivedAt, Limit: 10}
+
+	mock.ExpectQuery("FROM events_archive").
+		WithArgs(1, "review", nil, archivedAt, 10, 0).
+		WillReturnRows(pgxmock.NewRows([]string{"id", "user_id", "event", "date", "end_date", "all_day", "tz", "mail",
+			"rrule", "uid", "created_at", "archived_at"}).
+			AddRow(uint(7), 1, "Design review", date, date.Add(time.Hour), false, "UTC", "alice@example.com", "",
+				"7@improved-calendar-service", date.AddDate(0, -1, 0), archivedAt))
+
+	events, err := repo.GetArchivedEvents(context.Background(), get)
+	assert.NoError(t, err)
+	assert.Len(t, events, 1)
+	assert.Equal(t, "Design review", events[0].Event)
+	assert.Equal(t, archivedAt, events[0].ArchivedAt)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRepositoryRestoreEvent(t *testing.T) {
+	repo, mock := newTestRepo(t)
+	defer mock.Close()
+
+	// The restore is recorded on the events, so the cleaner keeps them.
+	mock.ExpectQuery(`(?s)DELETE FROM events_archive.*RETURNING .*, attendees.*INSERT INTO events \(.*, restored_at\).*CURRENT_TIMESTAMP FROM moved.*INSERT INTO event_attendees.*jsonb_to_recordset\(m.attendees\)`).
+		WithArgs(uint(7), 1).
+		WillReturnRows(pgxmock.NewRows([]string{"count"}).AddRow(2))
+	mock.ExpectQuery("DELETE FROM events_archive").
+		WithArgs(uint(8), 1).
+		WillReturnRows(pgxmock.NewRows([]string{"count"}).AddRow(0))
+	mock.ExpectQuery("DELETE FROM events_archive").
+		WithArgs(uint(9), 1).
+		WillReturnError(&pgconn.PgError{Code: uniqueViolation})
+
+	ID, err := repo.RestoreEvent(context.Background(), 1, 7)
+	assert.NoError(t, err)
+	assert.Equal(t, uint(7), ID)
+
+	_, err = repo.RestoreEvent(context.Background(), 1, 8)
+	assert.ErrorIs(t, err, ErrArchivedEventNotFound)
+
+	_, err = repo.RestoreEvent(context.Background(), 1, 9)
+	assert.ErrorIs(t, err, ErrRestoreConflict)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+var recordRows = []string{"id", "user_id", "event", "date", "end_date", "all_day", "tz", "mail", "rrule", "exdates",
+	"uid", "parent_id", "recurrence_id", "cancelled", "exclusive", "reminders", "urgent", "attendees", "created_at",
+	"updated_at", "archived_at"}
+
+func TestRepositoryExportArchive(t *testing.T) {
+	repo, mock := newTestRepo(t)
+	defer mock.Close()
+
+	before := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	date := time.Date(2025, 3, 1, 9, 0, 0, 0, time.UTC)
+	archivedAt := time.Date(2025, 4, 2, 3, 0, 0, 0, time.UTC)
+	parentID := uint(7)
+	attendees := json.RawMessage(`[{"id": 3, "email": "bob@example.com", "status": "accepted", "user_id": 2}]`)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("FROM events_archive").
+		WithArgs(before, 100).
+		WillReturnRows(pgxmock.NewRows(recordRows).
+			AddRow(uint(7), 1, "Standup", date, date.Add(15*time.Minute), false, "UTC", "alice@example.com",
+				"FREQ=DAILY;UNTIL=20250320T000000Z", []time.Time{}, "7@improved-calendar-service", (*uint)(nil),
+				(*time.Time)(nil), false, false, []models.ReminderSpec(nil), false, attendees, date, date, archivedAt).
+			AddRow(uint(9), 1, "Standup", date.AddDate(0, 0, 1), date.AddDate(0, 0, 1), false, "UTC",
+				"alice@example.com", "", []time.Time{}, "", &parentID, &date, true, false, []models.ReminderSpec(nil),
+				false, json.RawMessage(nil), date, date, archivedAt))
+	mock.ExpectExec("DELETE FROM events_archive").
+		WithArgs([]uint{7, 9}).
+		WillReturnResult(pgxmock.NewResult("DELETE", 2))
+	mock.ExpectCommit()
+	mock.ExpectRollback()
+
+	var written []*models.ArchiveRecord
+	exported, err := repo.ExportArchive(context.Background(), before, 100, func(records []*models.ArchiveRecord) error {
+		written = records
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 2, exported)
+	assert.Len(t, written, 2)
+	assert.Equal(t, attendees, written[0].Attendees)
+	assert.Equal(t, &parentID, written[1].ParentID)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRepositoryExportArchiveKeepsRowsOnWriteError(t *testing.T) {
+	repo, mock := newTestRepo(t)
+	defer mock.Close()
+
+	before := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	date := time.Date(2025, 3, 1, 9, 0, 0, 0, time.UTC)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("FROM events_archive").
+		WithArgs(before, 100).
+		WillReturnRows(pgxmock.NewRows(recordRows).
+			AddRow(uint(7), 1, "Review", date, date, false, "UTC", "alice@example.com", "", []time.Time{}, "",
+				(*uint)(nil), (*time.Time)(nil), false, false, []models.ReminderSpec(nil), false, json.RawMessage(nil),
+				date, date, date))
+	mock.ExpectRollback()
+
+	_, err := repo.ExportArchive(context.Background(), before, 100, func([]*models.ArchiveRecord) error {
+		return errors.New("disk full")
+	})
+	assert.Error(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRepositoryDeleteExported(t *testing.T) {
+	repo, mock := newTestRepo(t)
+	defer mock.Close()
+
+	archivedAt := time.Date(2025, 4, 2, 3, 0, 0, 0, time.UTC)
+	keys := []models.ArchiveKey{{ID: 7, ArchivedAt: archivedAt}, {ID: 9, ArchivedAt: archivedAt}}
+
+	mock.ExpectExec("DELETE FROM events_archive a").
+		WithArgs([]uint{7, 9}, []time.Time{archivedAt, archivedAt}).
+		WillReturnResult(pgxmock.NewResult("DELETE", 1))
+
+	deleted, err := repo.DeleteExported(context.Background(), keys)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, deleted)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRepositoryImportArchiveSkipsExisting(t *testing.T) {
+	repo, mock := newTestRepo(t)
+	defer mock.Close()
+
+	date := time.Date(2025, 3, 1, 9, 0, 0, 0, time.UTC)
+	attendees := json.RawMessage(`[{"id": 3, "email": "bob@example.com", "status": "accepted", "user_id": 2}]`)
+	records := []*models.ArchiveRecord{
+		{ID: 7, UserID: 1, Event: "Review", Date: date, End: date, TZ: "UTC", Mail: "alice@example.com",
+			Reminders: []models.ReminderSpec{{Offset: 15}}, Attendees: attendees, CreatedAt: date, UpdatedAt: date,
+			ArchivedAt: date},
+		{ID: 8, UserID: 1, Event: "Retro", Date: date, End: date, TZ: "UTC", Mail: "alice@example.com",
+			CreatedAt: date, UpdatedAt: date, ArchivedAt: date},
+	}
+
+	// The rows are archived again at the time of the import, not at the time
+	// they were exported.
+	mock.ExpectBegin()
+	mock.ExpectExec(`(?s)INSERT INTO events_archive.*\$19, \$20, CURRENT_TIMESTAMP`).
+		WithArgs(uint(7), 1, "Review", date, date, false, "UTC", "alice@example.com", "", []time.Time(nil), "",
+			(*uint)(nil), (*time.Time)(nil), false, false, []models.ReminderSpec{{Offset: 15}}, false, attendees, date,
+			date).
+		WillReturnResult(pgxmock.NewResult("INSERT", 1))
+	mock.ExpectExec("INSERT INTO events_archive").
+		WithArgs(uint(8), 1, "Retro", date, date, false, "UTC", "alice@example.com", "", []time.Time(nil), "",
+			(*uint)(nil), (*time.Time)(nil), false, false, nil, false, nil, date, date).
+		WillReturnResult(pgxmock.NewResult("INSERT", 0))
+	mock.ExpectCommit()
+	mock.ExpectRollback()
+
+	imported, err := repo.ImportArchive(context.Background(), records)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, imported)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
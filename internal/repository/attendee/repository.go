@@ -0,0 +1,201 @@
+package attendee
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/avraam311/improved-calendar-service/internal/models"
+)
+
+var (
+	ErrEventNotFound     = errors.New("event not found")
+	ErrAttendeeNotFound  = errors.New("attendee not found")
+	ErrAttendeeAmbiguous = errors.New("user is invited under several emails")
+)
+
+type DB interface {
+	Query(ctx context.Context, sql string, optionsAndArgs ...any) (pgx.Rows, error)
+	QueryRow(ctx context.Context, sql string, optionsAndArgs ...any) pgx.Row
+	Begin(ctx context.Context) (pgx.Tx, error)
+}
+
+const attendeeColumns = `id, event_id, COALESCE(user_id, 0), email, status, updated_at`
+
+type Repository struct {
+	db DB
+}
+
+func New(db DB) *Repository {
+	return &Repository{
+		db: db,
+	}
+}
+
+// GetEvent returns the series or single event attendees are invited to and
+// the email of its organiser. Cancelled occurrences of a series are returned
+// among its exdates.
+func (r *Repository) GetEvent(ctx context.Context, eventID uint) (*models.Event, string, error) {
+	query := `
+		SELECT id, user_id, event, date, end_date, all_day, tz, COALESCE(rrule, ''),
+		    exdates || ARRAY(SELECT o.recurrence_id FROM events o WHERE o.parent_id = e.id AND o.cancelled),
+		    COALESCE(uid, ''), updated_at, mail
+		FROM events e
+		WHERE id = $1 AND parent_id IS NULL;
+	`
+
+	var (
+		e    models.Event
+		mail string
+	)
+	err := r.db.QueryRow(ctx, query, eventID).Scan(&e.ID, &e.UserID, &e.Event, &e.Date, &e.End, &e.AllDay, &e.TZ,
+		&e.RRule, &e.ExDates, &e.UID, &e.UpdatedAt, &mail)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, "", ErrEventNotFound
+		}
+
+		return nil, "", fmt.Errorf("repository/GetEvent - %w", err)
+	}
+
+	return &e, mail, nil
+}
+
+// AddAttendees invites the attendees to the event in a single transaction and
+// returns the ones that were not invited before.
+func (r *Repository) AddAttendees(ctx context.Context, eventID uint, attendees []*models.Attendee) ([]*models.Attendee, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("repository/AddAttendees - %w", err)
+	}
+	defer func() {
+		_ = tx.Rollback(ctx)
+	}()
+
+	query := `
+		INSERT INTO event_attendees (event_id, user_id, email)
+		VALUES ($1, NULLIF($2, 0), $3)
+		ON CONFLICT (event_id, email) DO NOTHING
+		RETURNING ` + attendeeColumns + `;
+	`
+
+	added := []*models.Attendee{}
+	for _, attendee := range attendees {
+		var a models.Attendee
+		err = tx.QueryRow(ctx, query, eventID, attendee.UserID, attendee.Email).
+			Scan(&a.ID, &a.EventID, &a.UserID, &a.Email, &a.Status, &a.UpdatedAt)
+		if errors.Is(err, pgx.ErrNoRows) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("repository/AddAttendees - %w", err)
+		}
+		added = append(added, &a)
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("repository/AddAttendees - %w", err)
+	}
+
+	return added, nil
+}
+
+// SetStatus records the response of the attendee identified by user ID or,
+// without one, by email. The response is only recorded when it matches one
+// attendee, a user invited under several emails responds by email alone.
+func (r *Repository) SetStatus(ctx context.Context, response *models.AttendeeResponse) (*models.Attendee, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("repository/SetStatus - %w", err)
+	}
+	defer func() {
+		_ = tx.Rollback(ctx)
+	}()
+
+	query := `
+		UPDATE event_attendees
+		SET status = $4, updated_at = CURRENT_TIMESTAMP
+		WHERE event_id = $1 AND CASE WHEN $2 <> 0 THEN user_id = $2 ELSE email = $3 END
+		RETURNING ` + attendeeColumns + `;
+	`
+
+	rows, err := tx.Query(ctx, query, response.EventID, response.UserID, response.Email, response.Status)
+	if err != nil {
+		return nil, fmt.Errorf("repository/SetStatus - %w", err)
+	}
+	attendees, err := scanAttendees(rows)
+	if err != nil {
+		return nil, fmt.Errorf("repository/SetStatus - %w", err)
+	}
+	switch {
+	case len(attendees) == 0:
+		return nil, ErrAttendeeNotFound
+	case len(attendees) > 1:
+		return nil, ErrAttendeeAmbiguous
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("repository/SetStatus - %w", err)
+	}
+
+	return attendees[0], nil
+}
+
+// RemoveAttendee uninvites the attendee with the given email and returns it.
+func (r *Repository) RemoveAttendee(ctx context.Context, eventID uint, email string) (*models.Attendee, error) {
+	query := `
+		DELETE FROM event_attendees
+		WHERE event_id = $1 AND email = $2
+		RETURNING ` + attendeeColumns + `;
+	`
+
+	var a models.Attendee
+	err := r.db.QueryRow(ctx, query, eventID, email).
+		Scan(&a.ID, &a.EventID, &a.UserID, &a.Email, &a.Status, &a.UpdatedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrAttendeeNotFound
+		}
+
+		return nil, fmt.Errorf("repository/RemoveAttendee - %w", err)
+	}
+
+	return &a, nil
+}
+
+func (r *Repository) GetAttendees(ctx context.Context, eventID uint) ([]*models.Attendee, error) {
+	query := `
+		SELECT ` + attendeeColumns + `
+		FROM event_attendees
+		WHERE event_id = $1
+		ORDER BY id
+	`
+
+	rows, err := r.db.Query(ctx, query, eventID)
+	if err != nil {
+		return nil, fmt.Errorf("repository/GetAttendees - %w", err)
+	}
+	attendees, err := scanAttendees(rows)
+	if err != nil {
+		return nil, fmt.Errorf("repository/GetAttendees - %w", err)
+	}
+
+	return attendees, nil
+}
+
+func scanAttendees(rows pgx.Rows) ([]*models.Attendee, error) {
+	defer rows.Close()
+
+	attendees := []*models.Attendee{}
+	for rows.Next() {
+		var a models.Attendee
+		if err := rows.Scan(&a.ID, &a.EventID, &a.UserID, &a.Email, &a.Status, &a.UpdatedAt); err != nil {
+			return nil, err
+		}
+		attendees = append(attendees, &a)
+	}
+
+	return attendees, rows.Err()
+}
@@ -0,0 +1,113 @@
+package attendee
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/pashagolub/pgxmock/v4"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/avraam311/improved-calendar-service/internal/models"
+)
+
+func newTestRepo(t *testing.T) (*Repository, pgxmock.PgxPoolIface) {
+	mock, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatalf("failed to create pgxmock: %v", err)
+	}
+	return New(mock), mock
+}
+
+func TestRepositoryAddAttendeesSkipsInvited(t *testing.T) {
+	repo, mock := newTestRepo(t)
+	defer mock.Close()
+
+	now := time.Now()
+	attendees := []*models.Attendee{
+		{UserID: 2, Email: "bob@example.com"},
+		{Email: "carol@example.com"},
+	}
+	columns := []string{"id", "event_id", "user_id", "email", "status", "updated_at"}
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("INSERT INTO event_attendees").
+		WithArgs(uint(1), 2, "bob@example.com").
+		WillReturnRows(pgxmock.NewRows(columns))
+	mock.ExpectQuery("INSERT INTO event_attendees").
+		WithArgs(uint(1), 0, "carol@example.com").
+		WillReturnRows(pgxmock.NewRows(columns).AddRow(uint(2), uint(1), 0, "carol@example.com", models.AttendeeNeedsAction, now))
+	mock.ExpectCommit()
+	mock.ExpectRollback()
+
+	added, err := repo.AddAttendees(context.Background(), 1, attendees)
+	assert.NoError(t, err)
+	assert.Len(t, added, 1)
+	assert.Equal(t, "carol@example.com", added[0].Email)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRepositorySetStatusNotFound(t *testing.T) {
+	repo, mock := newTestRepo(t)
+	defer mock.Close()
+
+	response := &models.AttendeeResponse{EventID: 1, Email: "dave@example.com", Status: models.AttendeeDeclined}
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("UPDATE event_attendees").
+		WithArgs(response.EventID, 0, response.Email, response.Status).
+		WillReturnRows(pgxmock.NewRows([]string{"id", "event_id", "user_id", "email", "status", "updated_at"}))
+	mock.ExpectRollback()
+
+	_, err := repo.SetStatus(context.Background(), response)
+	assert.ErrorIs(t, err, ErrAttendeeNotFound)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRepositorySetStatusMatchesOneAttendee(t *testing.T) {
+	repo, mock := newTestRepo(t)
+	defer mock.Close()
+
+	now := time.Date(2025, 9, 1, 9, 0, 0, 0, time.UTC)
+	response := &models.AttendeeResponse{EventID: 1, UserID: 2, Email: "carol@example.com", Status: models.AttendeeAccepted}
+	columns := []string{"id", "event_id", "user_id", "email", "status", "updated_at"}
+
+	// The user ID is matched on its own, the email only without it.
+	mock.ExpectBegin()
+	mock.ExpectQuery(`(?s)UPDATE event_attendees.*CASE WHEN \$2 <> 0 THEN user_id = \$2 ELSE email = \$3 END`).
+		WithArgs(response.EventID, 2, response.Email, response.Status).
+		WillReturnRows(pgxmock.NewRows(columns).
+			AddRow(uint(3), uint(1), 2, "bob@example.com", models.AttendeeAccepted, now))
+	mock.ExpectCommit()
+	mock.ExpectRollback()
+
+	attendee, err := repo.SetStatus(context.Background(), response)
+	assert.NoError(t, err)
+	assert.Equal(t, "bob@example.com", attendee.Email)
+
+	// A user invited under two emails changes neither of them.
+	mock.ExpectBegin()
+	mock.ExpectQuery("UPDATE event_attendees").
+		WithArgs(response.EventID, 2, response.Email, response.Status).
+		WillReturnRows(pgxmock.NewRows(columns).
+			AddRow(uint(3), uint(1), 2, "bob@example.com", models.AttendeeAccepted, now).
+			AddRow(uint(4), uint(1), 2, "bob@work.example.com", models.AttendeeAccepted, now))
+	mock.ExpectRollback()
+
+	_, err = repo.SetStatus(context.Background(), response)
+	assert.ErrorIs(t, err, ErrAttendeeAmbiguous)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRepositoryRemoveAttendeeNotFound(t *testing.T) {
+	repo, mock := newTestRepo(t)
+	defer mock.Close()
+
+	mock.ExpectQuery("DELETE FROM event_attendees").
+		WithArgs(uint(1), "dave@example.com").
+		WillReturnRows(pgxmock.NewRows([]string{"id", "event_id", "user_id", "email", "status", "updated_at"}))
+
+	_, err := repo.RemoveAttendee(context.Background(), 1, "dave@example.com")
+	assert.ErrorIs(t, err, ErrAttendeeNotFound)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
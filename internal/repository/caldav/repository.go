@@ -0,0 +1,85 @@
+package caldav
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+var (
+	ErrPasswordNotFound = errors.New("caldav password not found")
+)
+
+type DB interface {
+	Exec(ctx context.Context, sql string, arguments ...any) (pgconn.CommandTag, error)
+	QueryRow(ctx context.Context, sql string, optionsAndArgs ...any) pgx.Row
+}
+
+type Repository struct {
+	db DB
+}
+
+func New(db DB) *Repository {
+	return &Repository{
+		db: db,
+	}
+}
+
+// SavePassword stores the password hash for the user, replacing the previous
+// one.
+func (r *Repository) SavePassword(ctx context.Context, userID int, passwordHash string) error {
+	query := `
+		INSERT INTO caldav_passwords (
+		    user_id, token_hash
+		) VALUES ($1, $2)
+		ON CONFLICT (user_id) DO UPDATE
+		SET token_hash = EXCLUDED.token_hash, created_at = CURRENT_TIMESTAMP;
+	`
+
+	_, err := r.db.Exec(ctx, query, userID, passwordHash)
+	if err != nil {
+		return fmt.Errorf("repository/SavePassword - %w", err)
+	}
+
+	return nil
+}
+
+func (r *Repository) DeletePassword(ctx context.Context, userID int) error {
+	query := `
+		DELETE FROM caldav_passwords
+		WHERE user_id = $1;
+	`
+
+	cmdTag, err := r.db.Exec(ctx, query, userID)
+	if err != nil {
+		return fmt.Errorf("repository/DeletePassword - %w", err)
+	}
+	if cmdTag.RowsAffected() == 0 {
+		return ErrPasswordNotFound
+	}
+
+	return nil
+}
+
+func (r *Repository) GetUserID(ctx context.Context, passwordHash string) (int, error) {
+	query := `
+		SELECT user_id
+		FROM caldav_passwords
+		WHERE token_hash = $1;
+	`
+
+	var userID int
+	err := r.db.QueryRow(ctx, query, passwordHash).Scan(&userID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return 0, ErrPasswordNotFound
+		}
+
+		return 0, fmt.Errorf("repository/GetUserID - %w", err)
+	}
+
+	return userID, nil
+}
@@ -0,0 +1,91 @@
+package digest
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+
+	"github.com/avraam311/improved-calendar-service/internal/models"
+)
+
+type DB interface {
+	Exec(ctx context.Context, sql string, arguments ...any) (pgconn.CommandTag, error)
+	Query(ctx context.Context, sql string, optionsAndArgs ...any) (pgx.Rows, error)
+}
+
+type Repository struct {
+	db DB
+}
+
+func New(db DB) *Repository {
+	return &Repository{
+		db: db,
+	}
+}
+
+// GetSubscribers returns the settings of the users who opted in to a digest.
+func (r *Repository) GetSubscribers(ctx context.Context) ([]*models.UserSettings, error) {
+	query := `
+		SELECT user_id, COALESCE(locale, ''), digest
+		FROM user_settings
+		WHERE (digest->>'daily')::boolean OR (digest->>'weekly')::boolean
+		ORDER BY user_id;
+	`
+
+	rows, err := r.db.Query(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("repository/GetSubscribers - %w", err)
+	}
+	defer rows.Close()
+
+	subscribers := []*models.UserSettings{}
+	for rows.Next() {
+		var settings models.UserSettings
+		if err = rows.Scan(&settings.UserID, &settings.Locale, &settings.Digest); err != nil {
+			return nil, fmt.Errorf("repository/GetSubscribers - %w", err)
+		}
+		subscribers = append(subscribers, &settings)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("repository/GetSubscribers - %w", err)
+	}
+
+	return subscribers, nil
+}
+
+// ClaimDigest records the digest of the user for the period starting on the
+// day of period. It reports false when the digest was already claimed, so
+// every digest goes out once even with several workers.
+func (r *Repository) ClaimDigest(ctx context.Context, userID int, kind string, period time.Time) (bool, error) {
+	query := `
+		INSERT INTO digests (user_id, kind, period)
+		VALUES ($1, $2, $3::date)
+		ON CONFLICT DO NOTHING;
+	`
+
+	tag, err := r.db.Exec(ctx, query, userID, kind, period.Format(time.DateOnly))
+	if err != nil {
+		return false, fmt.Errorf("repository/ClaimDigest - %w", err)
+	}
+
+	return tag.RowsAffected() == 1, nil
+}
+
+// ReleaseDigest drops the claim of a digest that could not be sent, so it is
+// tried again.
+func (r *Repository) ReleaseDigest(ctx context.Context, userID int, kind string, period time.Time) error {
+	query := `
+		DELETE FROM digests
+		WHERE user_id = $1 AND kind = $2 AND period = $3::date;
+	`
+
+	_, err := r.db.Exec(ctx, query, userID, kind, period.Format(time.DateOnly))
+	if err != nil {
+		return fmt.Errorf("repository/ReleaseDigest - %w", err)
+	}
+
+	return nil
+}
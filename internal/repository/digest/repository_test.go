@@ -0,0 +1,39 @@
+package digest
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/pashagolub/pgxmock/v4"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/avraam311/improved-calendar-service/internal/models"
+)
+
+func TestRepositoryClaimDigest(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatalf("failed to create pgxmock: %v", err)
+	}
+	defer mock.Close()
+	repo := New(mock)
+
+	period := time.Date(2025, 9, 1, 0, 0, 0, 0, time.FixedZone("MSK", 3*60*60))
+
+	mock.ExpectExec("INSERT INTO digests").
+		WithArgs(1, models.DigestDaily, "2025-09-01").
+		WillReturnResult(pgxmock.NewResult("INSERT", 1))
+	mock.ExpectExec("INSERT INTO digests").
+		WithArgs(1, models.DigestDaily, "2025-09-01").
+		WillReturnResult(pgxmock.NewResult("INSERT", 0))
+
+	claimed, err := repo.ClaimDigest(context.Background(), 1, models.DigestDaily, period)
+	assert.NoError(t, err)
+	assert.True(t, claimed)
+
+	claimed, err = repo.ClaimDigest(context.Background(), 1, models.DigestDaily, period)
+	assert.NoError(t, err)
+	assert.False(t, claimed)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"time"
 
 	"github.com/avraam311/improved-calendar-service/internal/models"
 	"github.com/jackc/pgx/v5"
@@ -12,14 +13,23 @@ import (
 
 var (
 	ErrEventNotFound = errors.New("event not found")
+	ErrEventConflict = errors.New("event overlaps an exclusive event")
 )
 
+// exclusionViolation is the SQLSTATE of a violated exclusion constraint.
+const exclusionViolation = "23P01"
+
 type DB interface {
 	Exec(ctx context.Context, sql string, arguments ...any) (pgconn.CommandTag, error)
 	Query(ctx context.Context, sql string, optionsAndArgs ...any) (pgx.Rows, error)
 	QueryRow(ctx context.Context, sql string, optionsAndArgs ...any) pgx.Row
+	Begin(ctx context.Context) (pgx.Tx, error)
 }
 
+// eventColumns is the column list read by scanEvents.
+const eventColumns = `id, user_id, event, date, end_date, all_day, tz, COALESCE(rrule, ''), exdates,
+		    COALESCE(uid, ''), parent_id, recurrence_id, cancelled, updated_at`
+
 type Repository struct {
 	db DB
 }
@@ -30,48 +40,205 @@ func New(db DB) *Repository {
 	}
 }
 
+// txKey keys the transaction of WithUserLock in the context.
+type txKey struct{}
+
+// WithUserLock runs fn in a transaction holding an advisory lock of the user,
+// which the repository methods called with the context of fn join. Writers
+// taking the lock are serialized until the transaction ends, so what fn reads
+// stays valid for its writes. The lock is released on commit or rollback.
+func (r *Repository) WithUserLock(ctx context.Context, userID int, fn func(ctx context.Context) error) error {
+	tx, err := r.conn(ctx).Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("repository/WithUserLock - %w", err)
+	}
+	defer func() {
+		_ = tx.Rollback(ctx)
+	}()
+
+	_, err = tx.Exec(ctx, "SELECT pg_advisory_xact_lock(hashtext('events'), $1)", userID)
+	if err != nil {
+		return fmt.Errorf("repository/WithUserLock - %w", err)
+	}
+
+	if err = fn(context.WithValue(ctx, txKey{}, tx)); err != nil {
+		return err
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return fmt.Errorf("repository/WithUserLock - %w", err)
+	}
+
+	return nil
+}
+
+// conn returns the transaction of WithUserLock the context carries, or the
+// database. Methods beginning a transaction of their own nest it in a
+// savepoint of the outer one.
+func (r *Repository) conn(ctx context.Context) DB {
+	if tx, ok := ctx.Value(txKey{}).(pgx.Tx); ok {
+		return tx
+	}
+
+	return r.db
+}
+
+// createEventQuery inserts a series or single event and returns its ID.
+const createEventQuery = `
+	INSERT INTO events (
+	    user_id, event, date, end_date, all_day, tz, mail, rrule, exdates, uid, exclusive, reminders, urgent
+	) VALUES ($1, $2, $3, $4, $5, $6, $7, NULLIF($8, ''), COALESCE($9::timestamp[], '{}'), $10, $11, $12, $13)
+	RETURNING id;
+`
+
 func (r *Repository) CreateEvent(ctx context.Context, event *models.EventCreate) (uint, error) {
-	query := `
-		INSERT INTO events (
-		    user_id, event, date
-		) VALUES ($1, $2, $3)
-		RETURNING id;
-    `
 	var ID uint
-	err := r.db.QueryRow(ctx, query, event.UserID, event.Event, event.Date).Scan(&ID)
+	err := r.conn(ctx).QueryRow(ctx, createEventQuery, createEventArgs(event)...).Scan(&ID)
 	if err != nil {
+		if isExclusionViolation(err) {
+			return 0, ErrEventConflict
+		}
+
 		return 0, fmt.Errorf("repository/CreateEvent - %w", err)
 	}
 
 	return ID, nil
 }
 
-func (r *Repository) UpdateEvent(ctx context.Context, event *models.Event) (uint, error) {
-	query := `
+func createEventArgs(event *models.EventCreate) []any {
+	return []any{event.UserID, event.Event, event.Date, event.End, event.AllDay, event.TZ, event.Mail, event.RRule,
+		event.ExDates, event.UID, event.ConflictPolicy == models.ConflictReject, remindersValue(event.Reminders),
+		event.Urgent}
+}
+
+// CreateSeries stores the series and its overrides in one transaction.
+func (r *Repository) CreateSeries(ctx context.Context, series *models.EventCreate, overrides []*models.Event) (uint, error) {
+	tx, err := r.conn(ctx).Begin(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("repository/CreateSeries - %w", err)
+	}
+	defer func() {
+		_ = tx.Rollback(ctx)
+	}()
+
+	var ID uint
+	err = tx.QueryRow(ctx, createEventQuery, createEventArgs(series)...).Scan(&ID)
+	if err != nil {
+		if isExclusionViolation(err) {
+			return 0, ErrEventConflict
+		}
+
+		return 0, fmt.Errorf("repository/CreateSeries - %w", err)
+	}
+
+	if err = saveOverrides(ctx, tx, ID, overrides); err != nil {
+		return 0, fmt.Errorf("repository/CreateSeries - %w", err)
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return 0, fmt.Errorf("repository/CreateSeries - %w", err)
+	}
+
+	return ID, nil
+}
+
+// updateEventQuery replaces the fields of a series or single event. The
+// overrides of a series are kept unless its start or rule changes, then they
+// point at occurrences the series no longer has and are dropped. The end of a
+// COUNT series is computed again by the cleaner.
+const updateEventQuery = `
+		WITH dropped AS (
+		    DELETE FROM events o
+		    USING events s
+		    WHERE o.parent_id = s.id AND s.id = $12
+		      AND (s.date, COALESCE(s.rrule, '')) IS DISTINCT FROM ($3, $7)
+		)
 		UPDATE events
 		SET
 			user_id = $1,
 			event = $2,
-		    date = $3
-		WHERE id = $4;
+		    date = $3,
+		    end_date = $4,
+		    all_day = $5,
+		    tz = $6,
+		    rrule = NULLIF($7, ''),
+		    exdates = COALESCE($8::timestamp[], '{}'),
+		    exclusive = $9,
+		    reminders = $10,
+		    urgent = $11,
+		    series_end = NULL,
+		    updated_at = CURRENT_TIMESTAMP
+		WHERE id = $12;
 	`
 
-	_, err := r.db.Exec(ctx, query, event.UserID, event.Event, event.Date, event.ID)
+func (r *Repository) UpdateEvent(ctx context.Context, event *models.Event) (uint, error) {
+	_, err := r.conn(ctx).Exec(ctx, updateEventQuery, updateEventArgs(event)...)
 
 	if err != nil {
+		if isExclusionViolation(err) {
+			return 0, ErrEventConflict
+		}
+
 		return 0, fmt.Errorf("repository/UpdateEvent - %w", err)
 	}
 
 	return event.ID, nil
 }
 
+func updateEventArgs(event *models.Event) []any {
+	return []any{event.UserID, event.Event, event.Date, event.End, event.AllDay, event.TZ, event.RRule, event.ExDates,
+		event.ConflictPolicy == models.ConflictReject, remindersValue(event.Reminders), event.Urgent, event.ID}
+}
+
+// ReplaceSeries updates the series and replaces all of its overrides with the
+// given ones in one transaction, so no override of the old rule outlives it.
+func (r *Repository) ReplaceSeries(ctx context.Context, series *models.Event, overrides []*models.Event) (uint, error) {
+	tx, err := r.conn(ctx).Begin(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("repository/ReplaceSeries - %w", err)
+	}
+	defer func() {
+		_ = tx.Rollback(ctx)
+	}()
+
+	cmdTag, err := tx.Exec(ctx, updateEventQuery, updateEventArgs(series)...)
+	if err != nil {
+		if isExclusionViolation(err) {
+			return 0, ErrEventConflict
+		}
+
+		return 0, fmt.Errorf("repository/ReplaceSeries - %w", err)
+	}
+	if cmdTag.RowsAffected() == 0 {
+		return 0, ErrEventNotFound
+	}
+
+	_, err = tx.Exec(ctx, `
+		DELETE FROM events
+		WHERE parent_id = $1;
+	`, series.ID)
+	if err != nil {
+		return 0, fmt.Errorf("repository/ReplaceSeries - %w", err)
+	}
+
+	if err = saveOverrides(ctx, tx, series.ID, overrides); err != nil {
+		return 0, fmt.Errorf("repository/ReplaceSeries - %w", err)
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return 0, fmt.Errorf("repository/ReplaceSeries - %w", err)
+	}
+
+	return series.ID, nil
+}
+
 func (r *Repository) DeleteEvent(ctx context.Context, ID uint) (uint, error) {
 	query := `
    		DELETE FROM events
    		WHERE id = $1;
     `
 
-	cmdTag, err := r.db.Exec(ctx, query, ID)
+	cmdTag, err := r.conn(ctx).Exec(ctx, query, ID)
 	if cmdTag.RowsAffected() == 0 {
 		return 0, ErrEventNotFound
 	}
@@ -83,55 +250,339 @@ func (r *Repository) DeleteEvent(ctx context.Context, ID uint) (uint, error) {
 	return ID, nil
 }
 
-func (r *Repository) GetEvents(ctx context.Context, eventGet *models.EventGet) ([]*models.Event, error) {
+func (r *Repository) GetEvent(ctx context.Context, ID uint) (*models.Event, error) {
 	query := `
-		SELECT id, user_id, event, date
+		SELECT id, user_id, event, date, end_date, all_day, tz, COALESCE(rrule, ''), exdates, COALESCE(uid, ''), mail,
+		    reminders, urgent
 		FROM events
-		WHERE user_id = $1 AND date >= $2 AND date <= $3
+		WHERE id = $1 AND parent_id IS NULL;
+	`
+
+	var e models.Event
+	err := r.conn(ctx).QueryRow(ctx, query, ID).Scan(&e.ID, &e.UserID, &e.Event, &e.Date, &e.End, &e.AllDay, &e.TZ,
+		&e.RRule, &e.ExDates, &e.UID, &e.Mail, &e.Reminders, &e.Urgent)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrEventNotFound
+		}
+
+		return nil, fmt.Errorf("repository/GetEvent - %w", err)
+	}
+
+	return &e, nil
+}
+
+// saveOverrideQuery inserts an override of the series $1, replacing a previous
+// override of the same occurrence.
+const saveOverrideQuery = `
+	INSERT INTO events (
+	    user_id, event, date, end_date, all_day, tz, mail, parent_id, recurrence_id, cancelled
+	)
+	SELECT user_id, $2, $3, $4, $5, tz, mail, id, $6, $7
+	FROM events
+	WHERE id = $1 AND parent_id IS NULL
+	ON CONFLICT (parent_id, recurrence_id) WHERE parent_id IS NOT NULL
+	DO UPDATE SET
+	    event = EXCLUDED.event,
+	    date = EXCLUDED.date,
+	    end_date = EXCLUDED.end_date,
+	    all_day = EXCLUDED.all_day,
+	    cancelled = EXCLUDED.cancelled,
+	    updated_at = CURRENT_TIMESTAMP
+	RETURNING id;
+`
+
+func saveOverrides(ctx context.Context, tx pgx.Tx, seriesID uint, overrides []*models.Event) error {
+	for _, override := range overrides {
+		var ID uint
+		err := tx.QueryRow(ctx, saveOverrideQuery, seriesID, override.Event, override.Date, override.End,
+			override.AllDay, *override.RecurrenceID, override.Cancelled).Scan(&ID)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// SaveOverride stores a modified or cancelled occurrence of a recurring series,
+// replacing a previous override of the same occurrence.
+func (r *Repository) SaveOverride(ctx context.Context, override *models.Event) (uint, error) {
+	var ID uint
+	err := r.conn(ctx).QueryRow(ctx, saveOverrideQuery, *override.ParentID, override.Event, override.Date, override.End, override.AllDay,
+		*override.RecurrenceID, override.Cancelled).Scan(&ID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return 0, ErrEventNotFound
+		}
+
+		return 0, fmt.Errorf("repository/SaveOverride - %w", err)
+	}
+
+	return ID, nil
+}
+
+// SplitSeries ends the series before from by replacing its rule and drops the
+// overrides of the cut-off occurrences. When next is given it is stored as a
+// new series owned by the same user and its ID is returned.
+func (r *Repository) SplitSeries(ctx context.Context, ID uint, rule string, from time.Time, next *models.Event) (uint, error) {
+	tx, err := r.conn(ctx).Begin(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("repository/SplitSeries - %w", err)
+	}
+	defer func() {
+		_ = tx.Rollback(ctx)
+	}()
+
+	cmdTag, err := tx.Exec(ctx, `
+		UPDATE events
+		SET rrule = NULLIF($1, ''), series_end = NULL, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $2 AND parent_id IS NULL;
+	`, rule, ID)
+	if err != nil {
+		return 0, fmt.Errorf("repository/SplitSeries - %w", err)
+	}
+	if cmdTag.RowsAffected() == 0 {
+		return 0, ErrEventNotFound
+	}
+
+	_, err = tx.Exec(ctx, `
+		DELETE FROM events
+		WHERE parent_id = $1 AND recurrence_id >= $2;
+	`, ID, from)
+	if err != nil {
+		return 0, fmt.Errorf("repository/SplitSeries - %w", err)
+	}
+
+	nextID := ID
+	if next != nil {
+		err = tx.QueryRow(ctx, `
+			INSERT INTO events (
+			    user_id, event, date, end_date, all_day, tz, mail, rrule, exdates, reminders, urgent, exclusive
+			)
+			SELECT user_id, $2, $3, $4, $5, $6, mail, NULLIF($7, ''), COALESCE($8::timestamp[], '{}'), $9, $10, $11
+			FROM events
+			WHERE id = $1
+			RETURNING id;
+		`, ID, next.Event, next.Date, next.End, next.AllDay, next.TZ, next.RRule, next.ExDates,
+			remindersValue(next.Reminders), next.Urgent, next.ConflictPolicy == models.ConflictReject).Scan(&nextID)
+		if err != nil {
+			return 0, fmt.Errorf("repository/SplitSeries - %w", err)
+		}
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return 0, fmt.Errorf("repository/SplitSeries - %w", err)
+	}
+
+	return nextID, nil
+}
+
+// GetEvents returns the events overlapping the window, every series that
+// started before its end and the overrides of occurrences overlapping it.
+// An event overlaps the window when it starts before the window ends and ends
+// after it starts; events without duration overlap when they start inside it.
+// Besides the events of the user it returns the events the user is invited
+// to and hasn't declined.
+func (r *Repository) GetEvents(ctx context.Context, eventGet *models.EventGet) ([]*models.Event, error) {
+	query := `
+		SELECT ` + eventColumns + `
+		FROM events e
+		WHERE (user_id = $1 OR COALESCE(parent_id, id) IN (
+		    SELECT event_id FROM event_attendees WHERE user_id = $1 AND status <> 'declined'
+		)) AND (
+		    (parent_id IS NULL AND rrule IS NULL AND date <= $3 AND (end_date > $2 OR date >= $2))
+		    OR (parent_id IS NULL AND rrule IS NOT NULL AND date <= $3)
+		    OR (parent_id IS NOT NULL AND (
+		        (date <= $3 AND (end_date > $2 OR date >= $2))
+		        OR (recurrence_id <= $3 AND recurrence_id + (
+		            SELECT p.end_date - p.date FROM events p WHERE p.id = e.parent_id
+		        ) >= $2)
+		    ))
+		)
 		ORDER BY date
     `
 
-	rows, err := r.db.Query(ctx, query, eventGet.UserID, eventGet.DateFrom, eventGet.DateTo)
+	rows, err := r.conn(ctx).Query(ctx, query, eventGet.UserID, eventGet.DateFrom, eventGet.DateTo)
+	if err != nil {
+		return nil, fmt.Errorf("repository/GetEvents - %w", err)
+	}
+
+	events, err := scanEvents(rows)
 	if err != nil {
 		return nil, fmt.Errorf("repository/GetEvents - %w", err)
 	}
+
+	return events, nil
+}
+
+// GetUserEvents returns every stored series, single event and override of the user.
+func (r *Repository) GetUserEvents(ctx context.Context, userID int) ([]*models.Event, error) {
+	query := `
+		SELECT ` + eventColumns + `
+		FROM events
+		WHERE user_id = $1
+		ORDER BY id
+	`
+
+	rows, err := r.conn(ctx).Query(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("repository/GetUserEvents - %w", err)
+	}
+
+	events, err := scanEvents(rows)
+	if err != nil {
+		return nil, fmt.Errorf("repository/GetUserEvents - %w", err)
+	}
+
+	return events, nil
+}
+
+// GetEventByUID returns the event with the given UID followed by its overrides.
+func (r *Repository) GetEventByUID(ctx context.Context, userID int, uid string) ([]*models.Event, error) {
+	query := `
+		SELECT ` + eventColumns + `
+		FROM events
+		WHERE user_id = $1 AND (
+		    (parent_id IS NULL AND uid = $2)
+		    OR parent_id = (SELECT id FROM events WHERE user_id = $1 AND uid = $2 AND parent_id IS NULL)
+		)
+		ORDER BY parent_id NULLS FIRST, recurrence_id
+	`
+
+	rows, err := r.conn(ctx).Query(ctx, query, userID, uid)
+	if err != nil {
+		return nil, fmt.Errorf("repository/GetEventByUID - %w", err)
+	}
+
+	events, err := scanEvents(rows)
+	if err != nil {
+		return nil, fmt.Errorf("repository/GetEventByUID - %w", err)
+	}
+	if len(events) == 0 {
+		return nil, ErrEventNotFound
+	}
+
+	return events, nil
+}
+
+func scanEvents(rows pgx.Rows) ([]*models.Event, error) {
 	defer rows.Close()
 
 	events := []*models.Event{}
 	for rows.Next() {
 		var e models.Event
-		if err := rows.Scan(&e.ID, &e.UserID, &e.Event, &e.Date); err != nil {
-			return nil, fmt.Errorf("repository/GetEvents - %w", err)
+		err := rows.Scan(&e.ID, &e.UserID, &e.Event, &e.Date, &e.End, &e.AllDay, &e.TZ, &e.RRule, &e.ExDates,
+			&e.UID, &e.ParentID, &e.RecurrenceID, &e.Cancelled, &e.UpdatedAt)
+		if err != nil {
+			return nil, err
 		}
 
 		events = append(events, &e)
 	}
 
-	return events, nil
+	return events, rows.Err()
 }
 
-func (r *Repository) GetEventsToClean(ctx context.Context) ([]*models.EventToClean, error) {
+// ImportEvents upserts the events by UID in a single transaction. Events that
+// already exist unchanged are reported as skipped, the end of an updated COUNT
+// series is computed again by the cleaner. When an update moves the start of a
+// series or changes its rule, its overrides are dropped like ReplaceSeries
+// does, so none of them points at an occurrence of the old rule. Every event
+// is stored under its own savepoint, so an event rejected by the database is
+// reported as failed and the others are still imported.
+func (r *Repository) ImportEvents(ctx context.Context, events []*models.EventCreate) ([]*models.ImportItem, error) {
 	query := `
-        SELECT id, user_id, event, date, created_at
-        FROM events
-        WHERE created_at <= now() - interval '1 month'
-        ORDER BY created_at
-    `
+		WITH dropped AS (
+		    DELETE FROM events o
+		    USING events s
+		    WHERE o.parent_id = s.id AND s.user_id = $1 AND s.uid = $10 AND s.parent_id IS NULL
+		      AND (s.date, COALESCE(s.rrule, '')) IS DISTINCT FROM ($3, $8)
+		)
+		INSERT INTO events (
+		    user_id, event, date, end_date, all_day, tz, mail, rrule, exdates, uid
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, NULLIF($8, ''), COALESCE($9::timestamp[], '{}'), $10)
+		ON CONFLICT (user_id, uid) WHERE parent_id IS NULL
+		DO UPDATE SET
+		    event = EXCLUDED.event,
+		    date = EXCLUDED.date,
+		    end_date = EXCLUDED.end_date,
+		    all_day = EXCLUDED.all_day,
+		    tz = EXCLUDED.tz,
+		    rrule = EXCLUDED.rrule,
+		    exdates = EXCLUDED.exdates,
+		    series_end = NULL,
+		    updated_at = CURRENT_TIMESTAMP
+		WHERE (events.event, events.date, events.end_date, events.all_day, events.tz, events.rrule, events.exdates)
+		    IS DISTINCT FROM (EXCLUDED.event, EXCLUDED.date, EXCLUDED.end_date, EXCLUDED.all_day, EXCLUDED.tz,
+		        EXCLUDED.rrule, EXCLUDED.exdates)
+		RETURNING id, xmax = 0;
+	`
 
-	rows, err := r.db.Query(ctx, query)
+	tx, err := r.conn(ctx).Begin(ctx)
 	if err != nil {
-		return nil, fmt.Errorf("repository/GetEventsToClean - %w", err)
+		return nil, fmt.Errorf("repository/ImportEvents - %w", err)
 	}
-	defer rows.Close()
+	defer func() {
+		_ = tx.Rollback(ctx)
+	}()
 
-	events := []*models.EventToClean{}
-	for rows.Next() {
-		var e models.EventToClean
-		if err := rows.Scan(&e.ID, &e.UserID, &e.Event, &e.Date, &e.CreatedAt); err != nil {
-			return nil, fmt.Errorf("repository/GetEventsToClean - %w", err)
+	items := make([]*models.ImportItem, 0, len(events))
+	for _, event := range events {
+		item := &models.ImportItem{UID: event.UID}
+
+		if _, err = tx.Exec(ctx, "SAVEPOINT import_event"); err != nil {
+			return nil, fmt.Errorf("repository/ImportEvents - %w", err)
 		}
-		events = append(events, &e)
+
+		var inserted bool
+		err = tx.QueryRow(ctx, query, event.UserID, event.Event, event.Date, event.End, event.AllDay, event.TZ,
+			event.Mail, event.RRule, event.ExDates, event.UID).
+			Scan(&item.ID, &inserted)
+
+		savepoint := "RELEASE SAVEPOINT import_event"
+		var pgErr *pgconn.PgError
+		switch {
+		case errors.As(err, &pgErr):
+			savepoint = "ROLLBACK TO SAVEPOINT import_event"
+			item.Status = models.ImportFailed
+			item.Error = pgErr.Message
+		case errors.Is(err, pgx.ErrNoRows):
+			item.Status = models.ImportSkipped
+		case err != nil:
+			return nil, fmt.Errorf("repository/ImportEvents - %w", err)
+		case inserted:
+			item.Status = models.ImportCreated
+		default:
+			item.Status = models.ImportUpdated
+		}
+		if _, err = tx.Exec(ctx, savepoint); err != nil {
+			return nil, fmt.Errorf("repository/ImportEvents - %w", err)
+		}
+
+		items = append(items, item)
 	}
 
-	return events, nil
+	if err = tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("repository/ImportEvents - %w", err)
+	}
+
+	return items, nil
+}
+
+// remindersValue stores the reminders of an event as JSON. Events without
+// reminders keep NULL and use the defaults of their owner.
+func remindersValue(reminders []models.ReminderSpec) any {
+	if reminders == nil {
+		return nil
+	}
+
+	return reminders
+}
+
+// isExclusionViolation reports whether err comes from the constraint keeping
+// exclusive events of a user from overlapping.
+func isExclusionViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == exclusionViolation
 }
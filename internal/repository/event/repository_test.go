@@ -5,6 +5,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/pashagolub/pgxmock/v4"
 	"github.com/stretchr/testify/assert"
 
@@ -28,10 +29,13 @@ func TestRepositoryCreateEvent(t *testing.T) {
 		UserID: 1,
 		Event:  "Test event",
 		Date:   time.Now(),
+		Mail:   "user@example.com",
+		RRule:  "FREQ=WEEKLY;BYDAY=MO",
+		UID:    "standup@example.com",
 	}
 
 	mock.ExpectQuery("INSERT INTO events").
-		WithArgs(event.UserID, event.Event, event.Date).
+		WithArgs(event.UserID, event.Event, event.Date, event.End, event.AllDay, event.TZ, event.Mail, event.RRule, event.ExDates, event.UID, false, nil, false).
 		WillReturnRows(pgxmock.NewRows([]string{"id"}).AddRow(id))
 
 	gotID, err := repo.CreateEvent(context.Background(), event)
@@ -52,7 +56,7 @@ func TestRepositoryUpdateEvent(t *testing.T) {
 	}
 
 	mock.ExpectExec("UPDATE events").
-		WithArgs(event.UserID, event.Event, event.Date, event.ID).
+		WithArgs(event.UserID, event.Event, event.Date, event.End, event.AllDay, event.TZ, event.RRule, event.ExDates, false, nil, false, event.ID).
 		WillReturnResult(pgxmock.NewResult("UPDATE", 1))
 
 	_, err := repo.UpdateEvent(context.Background(), event)
@@ -60,6 +64,50 @@ func TestRepositoryUpdateEvent(t *testing.T) {
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
 
+func TestRepositoryUpdateEventDropsOverridesOfChangedSeries(t *testing.T) {
+	repo, mock := newTestRepo(t)
+	defer mock.Close()
+
+	event := &models.Event{
+		ID:     uint(1),
+		UserID: 2,
+		Event:  "Standup",
+		Date:   time.Date(2025, 9, 1, 10, 0, 0, 0, time.UTC),
+		RRule:  "FREQ=WEEKLY;BYDAY=TU",
+	}
+
+	// The overrides are deleted by the same statement when the start or the
+	// rule of the series differs from the stored one.
+	mock.ExpectExec(`(?s)DELETE FROM events o.*WHERE o.parent_id = s.id AND s.id = \$12.*\(s.date, COALESCE\(s.rrule, ''\)\) IS DISTINCT FROM \(\$3, \$7\).*UPDATE events`).
+		WithArgs(event.UserID, event.Event, event.Date, event.End, event.AllDay, event.TZ, event.RRule, event.ExDates, false, nil, false, event.ID).
+		WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+
+	_, err := repo.UpdateEvent(context.Background(), event)
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRepositoryUpdateEventConflict(t *testing.T) {
+	repo, mock := newTestRepo(t)
+	defer mock.Close()
+
+	event := &models.Event{
+		ID:             uint(1),
+		UserID:         2,
+		Event:          "Meeting",
+		Date:           time.Now(),
+		ConflictPolicy: models.ConflictReject,
+	}
+
+	mock.ExpectExec("UPDATE events").
+		WithArgs(event.UserID, event.Event, event.Date, event.End, event.AllDay, event.TZ, event.RRule, event.ExDates, true, nil, false, event.ID).
+		WillReturnError(&pgconn.PgError{Code: "23P01"})
+
+	_, err := repo.UpdateEvent(context.Background(), event)
+	assert.ErrorIs(t, err, ErrEventConflict)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
 func TestRepositoryDeleteEventNotFound(t *testing.T) {
 	repo, mock := newTestRepo(t)
 	defer mock.Close()
@@ -74,3 +122,288 @@ func TestRepositoryDeleteEventNotFound(t *testing.T) {
 	assert.ErrorIs(t, err, ErrEventNotFound)
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
+
+func TestRepositoryGetEventsIncludesSeries(t *testing.T) {
+	repo, mock := newTestRepo(t)
+	defer mock.Close()
+
+	from := time.Date(2025, time.September, 1, 0, 0, 0, 0, time.UTC)
+	eventGet := &models.EventGet{UserID: 1, DateFrom: from, DateTo: from.AddDate(0, 0, 7)}
+	seriesStart := from.AddDate(0, -1, 0)
+
+	mock.ExpectQuery("SELECT id, user_id, event, date, end_date, all_day, tz, COALESCE\\(rrule, ''\\), exdates").
+		WithArgs(eventGet.UserID, eventGet.DateFrom, eventGet.DateTo).
+		WillReturnRows(pgxmock.NewRows([]string{"id", "user_id", "event", "date", "end_date", "all_day", "tz", "rrule", "exdates", "uid", "parent_id", "recurrence_id", "cancelled", "updated_at"}).
+			AddRow(uint(1), 1, "Standup", seriesStart, seriesStart.Add(15*time.Minute), false, "UTC", "FREQ=DAILY", []time.Time{}, "standup@example.com", (*uint)(nil), (*time.Time)(nil), false, seriesStart))
+
+	events, err := repo.GetEvents(context.Background(), eventGet)
+	assert.NoError(t, err)
+	assert.Len(t, events, 1)
+	assert.Equal(t, "FREQ=DAILY", events[0].RRule)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRepositorySaveOverrideMissingSeries(t *testing.T) {
+	repo, mock := newTestRepo(t)
+	defer mock.Close()
+
+	parentID := uint(1)
+	occurrence := time.Now()
+	override := &models.Event{Event: "Moved", Date: occurrence.Add(time.Hour), ParentID: &parentID, RecurrenceID: &occurrence}
+
+	mock.ExpectQuery("INSERT INTO events").
+		WithArgs(parentID, override.Event, override.Date, override.End, override.AllDay, occurrence, false).
+		WillReturnRows(pgxmock.NewRows([]string{"id"}))
+
+	_, err := repo.SaveOverride(context.Background(), override)
+	assert.ErrorIs(t, err, ErrEventNotFound)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRepositorySplitSeries(t *testing.T) {
+	repo, mock := newTestRepo(t)
+	defer mock.Close()
+
+	seriesID := uint(1)
+	at := time.Now()
+	next := &models.Event{Event: "Standup", Date: at, RRule: "FREQ=DAILY"}
+
+	mock.ExpectBegin()
+	mock.ExpectExec("UPDATE events").
+		WithArgs("FREQ=DAILY;UNTIL=20250905T085959Z", seriesID).
+		WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+	mock.ExpectExec("DELETE FROM events").
+		WithArgs(seriesID, at).
+		WillReturnResult(pgxmock.NewResult("DELETE", 2))
+	mock.ExpectQuery("INSERT INTO events").
+		WithArgs(seriesID, next.Event, next.Date, next.End, next.AllDay, next.TZ, next.RRule, next.ExDates, nil, false, false).
+		WillReturnRows(pgxmock.NewRows([]string{"id"}).AddRow(uint(2)))
+	mock.ExpectCommit()
+	mock.ExpectRollback()
+
+	gotID, err := repo.SplitSeries(context.Background(), seriesID, "FREQ=DAILY;UNTIL=20250905T085959Z", at, next)
+	assert.NoError(t, err)
+	assert.Equal(t, uint(2), gotID)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRepositoryReplaceSeriesDropsOverrides(t *testing.T) {
+	repo, mock := newTestRepo(t)
+	defer mock.Close()
+
+	date := time.Now()
+	series := &models.Event{ID: 1, UserID: 1, Event: "Standup", Date: date, End: date, RRule: "FREQ=DAILY"}
+
+	mock.ExpectBegin()
+	mock.ExpectExec("UPDATE events").
+		WithArgs(1, "Standup", date, date, false, "", "FREQ=DAILY", series.ExDates, false, nil, false, uint(1)).
+		WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+	mock.ExpectExec("DELETE FROM events").
+		WithArgs(uint(1)).
+		WillReturnResult(pgxmock.NewResult("DELETE", 3))
+	mock.ExpectCommit()
+	mock.ExpectRollback()
+
+	gotID, err := repo.ReplaceSeries(context.Background(), series, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, uint(1), gotID)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRepositoryCreateSeriesWithOverrides(t *testing.T) {
+	repo, mock := newTestRepo(t)
+	defer mock.Close()
+
+	date := time.Now()
+	occurrence := date.AddDate(0, 0, 1)
+	series := &models.EventCreate{UserID: 1, Event: "Standup", Date: date, End: date, RRule: "FREQ=DAILY", UID: "standup@example.com"}
+	override := &models.Event{Event: "Late standup", Date: occurrence.Add(time.Hour), End: occurrence.Add(time.Hour), RecurrenceID: &occurrence}
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("INSERT INTO events").
+		WithArgs(1, "Standup", date, date, false, "", "", "FREQ=DAILY", series.ExDates, "standup@example.com", false, nil, false).
+		WillReturnRows(pgxmock.NewRows([]string{"id"}).AddRow(uint(5)))
+	mock.ExpectQuery("INSERT INTO events").
+		WithArgs(uint(5), "Late standup", override.Date, override.End, false, occurrence, false).
+		WillReturnRows(pgxmock.NewRows([]string{"id"}).AddRow(uint(6)))
+	mock.ExpectCommit()
+	mock.ExpectRollback()
+
+	gotID, err := repo.CreateSeries(context.Background(), series, []*models.Event{override})
+	assert.NoError(t, err)
+	assert.Equal(t, uint(5), gotID)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRepositoryWithUserLock(t *testing.T) {
+	repo, mock := newTestRepo(t)
+	defer mock.Close()
+
+	date := time.Now()
+	event := &models.EventCreate{UserID: 1, Event: "Standup", Date: date, End: date, RRule: "FREQ=DAILY", ConflictPolicy: models.ConflictReject}
+
+	mock.ExpectBegin()
+	mock.ExpectExec("SELECT pg_advisory_xact_lock").
+		WithArgs(1).
+		WillReturnResult(pgxmock.NewResult("SELECT", 1))
+	mock.ExpectQuery("INSERT INTO events").
+		WithArgs(1, "Standup", date, date, false, "", "", "FREQ=DAILY", event.ExDates, "", true, nil, false).
+		WillReturnRows(pgxmock.NewRows([]string{"id"}).AddRow(uint(1)))
+	mock.ExpectCommit()
+	mock.ExpectRollback()
+
+	err := repo.WithUserLock(context.Background(), 1, func(ctx context.Context) error {
+		_, err := repo.CreateEvent(ctx, event)
+		return err
+	})
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRepositoryImportEvents(t *testing.T) {
+	repo, mock := newTestRepo(t)
+	defer mock.Close()
+
+	date := time.Now()
+	events := []*models.EventCreate{
+		{UserID: 1, Event: "New", Date: date, End: date, Mail: "user@example.com", UID: "new@example.com"},
+		{UserID: 1, Event: "Changed", Date: date, End: date, Mail: "user@example.com", UID: "changed@example.com"},
+		{UserID: 1, Event: "Same", Date: date, End: date, Mail: "user@example.com", UID: "same@example.com"},
+	}
+
+	mock.ExpectBegin()
+	mock.ExpectExec("SAVEPOINT import_event").
+		WillReturnResult(pgxmock.NewResult("SAVEPOINT", 0))
+	mock.ExpectQuery("INSERT INTO events").
+		WithArgs(1, "New", date, date, false, "", "user@example.com", "", events[0].ExDates, "new@example.com").
+		WillReturnRows(pgxmock.NewRows([]string{"id", "inserted"}).AddRow(uint(1), true))
+	mock.ExpectExec("RELEASE SAVEPOINT import_event").
+		WillReturnResult(pgxmock.NewResult("RELEASE", 0))
+	mock.ExpectExec("SAVEPOINT import_event").
+		WillReturnResult(pgxmock.NewResult("SAVEPOINT", 0))
+	mock.ExpectQuery("INSERT INTO events").
+		WithArgs(1, "Changed", date, date, false, "", "user@example.com", "", events[1].ExDates, "changed@example.com").
+		WillReturnRows(pgxmock.NewRows([]string{"id", "inserted"}).AddRow(uint(2), false))
+	mock.ExpectExec("RELEASE SAVEPOINT import_event").
+		WillReturnResult(pgxmock.NewResult("RELEASE", 0))
+	mock.ExpectExec("SAVEPOINT import_event").
+		WillReturnResult(pgxmock.NewResult("SAVEPOINT", 0))
+	mock.ExpectQuery("INSERT INTO events").
+		WithArgs(1, "Same", date, date, false, "", "user@example.com", "", events[2].ExDates, "same@example.com").
+		WillReturnRows(pgxmock.NewRows([]string{"id", "inserted"}))
+	mock.ExpectExec("RELEASE SAVEPOINT import_event").
+		WillReturnResult(pgxmock.NewResult("RELEASE", 0))
+	mock.ExpectCommit()
+	mock.ExpectRollback()
+
+	items, err := repo.ImportEvents(context.Background(), events)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{models.ImportCreated, models.ImportUpdated, models.ImportSkipped},
+		[]string{items[0].Status, items[1].Status, items[2].Status})
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRepositoryImportEventsResetsSeriesEnd(t *testing.T) {
+	repo, mock := newTestRepo(t)
+	defer mock.Close()
+
+	date := time.Date(2025, 9, 1, 9, 0, 0, 0, time.UTC)
+	events := []*models.EventCreate{
+		{UserID: 1, Event: "Standup", Date: date, End: date.Add(15 * time.Minute), Mail: "user@example.com",
+			RRule: "FREQ=DAILY;COUNT=50", UID: "standup@example.com"},
+	}
+
+	// The series used to end after 5 occurrences, the stored end is dropped so
+	// the cleaner doesn't archive it while occurrences are still to come.
+	mock.ExpectBegin()
+	mock.ExpectExec("SAVEPOINT import_event").
+		WillReturnResult(pgxmock.NewResult("SAVEPOINT", 0))
+	mock.ExpectQuery(`(?s)INSERT INTO events.*DO UPDATE SET.*series_end = NULL`).
+		WithArgs(1, "Standup", date, date.Add(15*time.Minute), false, "", "user@example.com", "FREQ=DAILY;COUNT=50",
+			events[0].ExDates, "standup@example.com").
+		WillReturnRows(pgxmock.NewRows([]string{"id", "inserted"}).AddRow(uint(4), false))
+	mock.ExpectExec("RELEASE SAVEPOINT import_event").
+		WillReturnResult(pgxmock.NewResult("RELEASE", 0))
+	mock.ExpectCommit()
+	mock.ExpectRollback()
+
+	items, err := repo.ImportEvents(context.Background(), events)
+	assert.NoError(t, err)
+	assert.Equal(t, models.ImportUpdated, items[0].Status)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRepositoryImportEventsDropsOverridesOfChangedSeries(t *testing.T) {
+	repo, mock := newTestRepo(t)
+	defer mock.Close()
+
+	date := time.Date(2025, 9, 1, 10, 0, 0, 0, time.UTC)
+	events := []*models.EventCreate{
+		{UserID: 1, Event: "Standup", Date: date, End: date.Add(15 * time.Minute), Mail: "user@example.com",
+			RRule: "FREQ=WEEKLY", UID: "standup@example.com"},
+	}
+
+	// The overrides of the stored series are deleted by the same statement
+	// when its start or rule differs from the imported one.
+	mock.ExpectBegin()
+	mock.ExpectExec("SAVEPOINT import_event").
+		WillReturnResult(pgxmock.NewResult("SAVEPOINT", 0))
+	mock.ExpectQuery(`(?s)DELETE FROM events o.*WHERE o.parent_id = s.id.*\(s.date, COALESCE\(s.rrule, ''\)\) IS DISTINCT FROM \(\$3, \$8\).*INSERT INTO events`).
+		WithArgs(1, "Standup", date, date.Add(15*time.Minute), false, "", "user@example.com", "FREQ=WEEKLY",
+			events[0].ExDates, "standup@example.com").
+		WillReturnRows(pgxmock.NewRows([]string{"id", "inserted"}).AddRow(uint(4), false))
+	mock.ExpectExec("RELEASE SAVEPOINT import_event").
+		WillReturnResult(pgxmock.NewResult("RELEASE", 0))
+	mock.ExpectCommit()
+	mock.ExpectRollback()
+
+	items, err := repo.ImportEvents(context.Background(), events)
+	assert.NoError(t, err)
+	assert.Equal(t, models.ImportUpdated, items[0].Status)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRepositoryImportEventsReportsFailedEvent(t *testing.T) {
+	repo, mock := newTestRepo(t)
+	defer mock.Close()
+
+	date := time.Now()
+	events := []*models.EventCreate{
+		{UserID: 1, Event: "First", Date: date, End: date, Mail: "user@example.com", UID: "first@example.com"},
+		{UserID: 1, Event: "Broken", Date: date, End: date, Mail: "user@example.com", UID: "broken@example.com"},
+		{UserID: 1, Event: "Third", Date: date, End: date, Mail: "user@example.com", UID: "third@example.com"},
+	}
+
+	mock.ExpectBegin()
+	mock.ExpectExec("SAVEPOINT import_event").
+		WillReturnResult(pgxmock.NewResult("SAVEPOINT", 0))
+	mock.ExpectQuery("INSERT INTO events").
+		WithArgs(1, "First", date, date, false, "", "user@example.com", "", events[0].ExDates, "first@example.com").
+		WillReturnRows(pgxmock.NewRows([]string{"id", "inserted"}).AddRow(uint(1), true))
+	mock.ExpectExec("RELEASE SAVEPOINT import_event").
+		WillReturnResult(pgxmock.NewResult("RELEASE", 0))
+	mock.ExpectExec("SAVEPOINT import_event").
+		WillReturnResult(pgxmock.NewResult("SAVEPOINT", 0))
+	mock.ExpectQuery("INSERT INTO events").
+		WithArgs(1, "Broken", date, date, false, "", "user@example.com", "", events[1].ExDates, "broken@example.com").
+		WillReturnError(&pgconn.PgError{Code: "22001", Message: "value too long for type character varying(255)"})
+	mock.ExpectExec("ROLLBACK TO SAVEPOINT import_event").
+		WillReturnResult(pgxmock.NewResult("ROLLBACK", 0))
+	mock.ExpectExec("SAVEPOINT import_event").
+		WillReturnResult(pgxmock.NewResult("SAVEPOINT", 0))
+	mock.ExpectQuery("INSERT INTO events").
+		WithArgs(1, "Third", date, date, false, "", "user@example.com", "", events[2].ExDates, "third@example.com").
+		WillReturnRows(pgxmock.NewRows([]string{"id", "inserted"}).AddRow(uint(3), true))
+	mock.ExpectExec("RELEASE SAVEPOINT import_event").
+		WillReturnResult(pgxmock.NewResult("RELEASE", 0))
+	mock.ExpectCommit()
+	mock.ExpectRollback()
+
+	items, err := repo.ImportEvents(context.Background(), events)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{models.ImportCreated, models.ImportFailed, models.ImportCreated},
+		[]string{items[0].Status, items[1].Status, items[2].Status})
+	assert.Equal(t, "value too long for type character varying(255)", items[1].Error)
+	assert.Equal(t, uint(3), items[2].ID)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,84 @@
+package feed
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+var (
+	ErrTokenNotFound = errors.New("feed token not found")
+)
+
+type DB interface {
+	Exec(ctx context.Context, sql string, arguments ...any) (pgconn.CommandTag, error)
+	QueryRow(ctx context.Context, sql string, optionsAndArgs ...any) pgx.Row
+}
+
+type Repository struct {
+	db DB
+}
+
+func New(db DB) *Repository {
+	return &Repository{
+		db: db,
+	}
+}
+
+// SaveToken stores the token hash for the user, replacing the previous one.
+func (r *Repository) SaveToken(ctx context.Context, userID int, tokenHash string) error {
+	query := `
+		INSERT INTO feed_tokens (
+		    user_id, token_hash
+		) VALUES ($1, $2)
+		ON CONFLICT (user_id) DO UPDATE
+		SET token_hash = EXCLUDED.token_hash, created_at = CURRENT_TIMESTAMP;
+	`
+
+	_, err := r.db.Exec(ctx, query, userID, tokenHash)
+	if err != nil {
+		return fmt.Errorf("repository/SaveToken - %w", err)
+	}
+
+	return nil
+}
+
+func (r *Repository) DeleteToken(ctx context.Context, userID int) error {
+	query := `
+		DELETE FROM feed_tokens
+		WHERE user_id = $1;
+	`
+
+	cmdTag, err := r.db.Exec(ctx, query, userID)
+	if err != nil {
+		return fmt.Errorf("repository/DeleteToken - %w", err)
+	}
+	if cmdTag.RowsAffected() == 0 {
+		return ErrTokenNotFound
+	}
+
+	return nil
+}
+
+func (r *Repository) GetUserID(ctx context.Context, tokenHash string) (int, error) {
+	query := `
+		SELECT user_id
+		FROM feed_tokens
+		WHERE token_hash = $1;
+	`
+
+	var userID int
+	err := r.db.QueryRow(ctx, query, tokenHash).Scan(&userID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return 0, ErrTokenNotFound
+		}
+
+		return 0, fmt.Errorf("repository/GetUserID - %w", err)
+	}
+
+	return userID, nil
+}
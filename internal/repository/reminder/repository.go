@@ -0,0 +1,367 @@
+package reminder
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+
+	"github.com/avraam311/improved-calendar-service/internal/models"
+)
+
+type DB interface {
+	Exec(ctx context.Context, sql string, arguments ...any) (pgconn.CommandTag, error)
+	Query(ctx context.Context, sql string, optionsAndArgs ...any) (pgx.Rows, error)
+	QueryRow(ctx context.Context, sql string, optionsAndArgs ...any) pgx.Row
+	Begin(ctx context.Context) (pgx.Tx, error)
+}
+
+var ErrDeadLetterNotFound = errors.New("dead letter not found")
+
+// missedGrace is how late a pending reminder may still be sent once its
+// occurrence has started. It keeps reminders at the start of an event, which
+// fire when the event starts, from being swept as missed before they are
+// claimed.
+const missedGrace = 5 * time.Minute
+
+const reminderColumns = `id, event_id, user_id, event, date, mail, offset_minutes, fire_at, channel, status, attempts,
+	next_attempt_at, COALESCE(last_error, ''), urgent`
+
+type Repository struct {
+	db DB
+}
+
+func New(db DB) *Repository {
+	return &Repository{
+		db: db,
+	}
+}
+
+// SaveReminders replaces the pending reminders of the event. Reminders that
+// were already sent for the same occurrence are not stored again. Deferred and
+// retried reminders of occurrences starting at or after since are kept only
+// if they are among the given ones, which refreshes their title, mail and
+// urgency, so a rescheduled, renamed or cancelled occurrence isn't reminded of
+// with stale details once the quiet time or the backoff is over.
+func (r *Repository) SaveReminders(ctx context.Context, eventID uint, since time.Time, reminders []*models.Reminder) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("repository/SaveReminders - %w", err)
+	}
+	defer func() {
+		_ = tx.Rollback(ctx)
+	}()
+
+	dates := make([]time.Time, 0, len(reminders))
+	offsets := make([]int, 0, len(reminders))
+	channels := make([]string, 0, len(reminders))
+	for _, reminder := range reminders {
+		dates = append(dates, reminder.Date)
+		offsets = append(offsets, reminder.Offset)
+		channels = append(channels, reminder.Channel)
+	}
+
+	query := `
+		DELETE FROM reminders
+		WHERE event_id = $1 AND (
+		    status = 'pending' OR (
+		        status IN ('deferred', 'retry') AND date >= $2
+		        AND (date, offset_minutes, channel) NOT IN (
+		            SELECT * FROM unnest($3::timestamp[], $4::int[], $5::text[])
+		        )
+		    )
+		);
+	`
+	_, err = tx.Exec(ctx, query, eventID, since, dates, offsets, channels)
+	if err != nil {
+		return fmt.Errorf("repository/SaveReminders - %w", err)
+	}
+
+	query = `
+		WITH kept AS (
+		    UPDATE reminders
+		    SET event = $3, mail = $5, urgent = $9, updated_at = CURRENT_TIMESTAMP
+		    WHERE event_id = $1 AND date = $4 AND offset_minutes = $6 AND channel = $8
+		      AND status IN ('deferred', 'retry')
+		)
+		INSERT INTO reminders (
+		    event_id, user_id, event, date, mail, offset_minutes, fire_at, channel, next_attempt_at, urgent
+		)
+		SELECT $1, $2, $3, $4, $5, $6, $7, $8, $7, $9
+		WHERE NOT EXISTS (
+		    SELECT 1 FROM reminders
+		    WHERE event_id = $1 AND date = $4 AND offset_minutes = $6 AND channel = $8 AND status <> 'pending'
+		);
+	`
+	for _, reminder := range reminders {
+		_, err = tx.Exec(ctx, query, eventID, reminder.UserID, reminder.Event, reminder.Date, reminder.Mail,
+			reminder.Offset, reminder.FireAt, reminder.Channel, reminder.Urgent)
+		if err != nil {
+			return fmt.Errorf("repository/SaveReminders - %w", err)
+		}
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return fmt.Errorf("repository/SaveReminders - %w", err)
+	}
+
+	return nil
+}
+
+// GetSent returns the reminders of the event that were sent, given up on or
+// are being sent for occurrences starting at or after since. Deferred and
+// retried reminders are still to be sent and are not returned.
+func (r *Repository) GetSent(ctx context.Context, eventID uint, since time.Time) ([]*models.Reminder, error) {
+	query := `
+		SELECT ` + reminderColumns + `
+		FROM reminders
+		WHERE event_id = $1 AND date >= $2 AND status NOT IN ('pending', 'deferred', 'retry');
+	`
+
+	rows, err := r.db.Query(ctx, query, eventID, since)
+	if err != nil {
+		return nil, fmt.Errorf("repository/GetSent - %w", err)
+	}
+
+	reminders, err := scanReminders(rows)
+	if err != nil {
+		return nil, fmt.Errorf("repository/GetSent - %w", err)
+	}
+
+	return reminders, nil
+}
+
+// GetUnscheduledEvents returns the series and single events that may still
+// need a reminder but have no pending or deferred one.
+func (r *Repository) GetUnscheduledEvents(ctx context.Context, now time.Time) ([]uint, error) {
+	query := `
+		SELECT e.id
+		FROM events e
+		WHERE e.parent_id IS NULL
+		  AND (COALESCE(e.rrule, '') <> '' OR e.date >= $1)
+		  AND NOT EXISTS (
+		      SELECT 1 FROM reminders r
+		      WHERE r.event_id = e.id AND (r.status IN ('pending', 'deferred') OR (COALESCE(e.rrule, '') = '' AND r.date >= e.date))
+		  )
+		ORDER BY e.id;
+	`
+
+	rows, err := r.db.Query(ctx, query, now)
+	if err != nil {
+		return nil, fmt.Errorf("repository/GetUnscheduledEvents - %w", err)
+	}
+	defer rows.Close()
+
+	var IDs []uint
+	for rows.Next() {
+		var ID uint
+		if err = rows.Scan(&ID); err != nil {
+			return nil, fmt.Errorf("repository/GetUnscheduledEvents - %w", err)
+		}
+		IDs = append(IDs, ID)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("repository/GetUnscheduledEvents - %w", err)
+	}
+
+	return IDs, nil
+}
+
+// ClaimDue claims up to limit due reminders for sending and returns them with
+// the status and next attempt they had before. The claim is committed before
+// anything is sent: the reminders are marked sending, which keeps concurrent
+// notifiers off them, and their next attempt is moved to leaseUntil. A
+// reminder whose result was not saved by then, because the notifier crashed or
+// lost the database, is due again and sent once more. The reminders carry the
+// time zone of their event and whether it lasts all day. Pending reminders of
+// occurrences that have already started and that missed their own fire time by
+// more than missedGrace are marked missed, while failed and deferred ones are
+// still sent.
+func (r *Repository) ClaimDue(ctx context.Context, now time.Time, limit int, leaseUntil time.Time) ([]*models.Reminder, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("repository/ClaimDue - %w", err)
+	}
+	defer func() {
+		_ = tx.Rollback(ctx)
+	}()
+
+	query := `
+		UPDATE reminders
+		SET status = 'missed', updated_at = CURRENT_TIMESTAMP
+		WHERE status = 'pending' AND attempts = 0 AND date < $1 AND fire_at < $2;
+	`
+	if _, err = tx.Exec(ctx, query, now, now.Add(-missedGrace)); err != nil {
+		return nil, fmt.Errorf("repository/ClaimDue - %w", err)
+	}
+
+	query = `
+		WITH due AS (
+		    SELECT id, status, next_attempt_at
+		    FROM reminders
+		    WHERE status IN ('pending', 'retry', 'sending', 'deferred') AND next_attempt_at <= $1
+		    ORDER BY next_attempt_at
+		    LIMIT $2
+		    FOR UPDATE SKIP LOCKED
+		)
+		UPDATE reminders r
+		SET status = 'sending', next_attempt_at = $3, updated_at = CURRENT_TIMESTAMP
+		FROM due, events e
+		WHERE r.id = due.id AND e.id = r.event_id
+		RETURNING r.id, r.event_id, r.user_id, r.event, r.date, r.mail, r.offset_minutes, r.fire_at, r.channel,
+		    due.status, r.attempts, due.next_attempt_at, COALESCE(r.last_error, ''), r.urgent, e.tz, e.all_day;
+	`
+	rows, err := tx.Query(ctx, query, now, limit, leaseUntil)
+	if err != nil {
+		return nil, fmt.Errorf("repository/ClaimDue - %w", err)
+	}
+	defer rows.Close()
+
+	claimed := []*models.Reminder{}
+	for rows.Next() {
+		var rem models.Reminder
+		err = rows.Scan(&rem.ID, &rem.EventID, &rem.UserID, &rem.Event, &rem.Date, &rem.Mail, &rem.Offset,
+			&rem.FireAt, &rem.Channel, &rem.Status, &rem.Attempts, &rem.NextAttemptAt, &rem.LastError, &rem.Urgent,
+			&rem.TZ, &rem.AllDay)
+		if err != nil {
+			return nil, fmt.Errorf("repository/ClaimDue - %w", err)
+		}
+		claimed = append(claimed, &rem)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("repository/ClaimDue - %w", err)
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("repository/ClaimDue - %w", err)
+	}
+
+	return claimed, nil
+}
+
+// SaveResult stores the outcome of sending a claimed reminder: its status,
+// attempts, next attempt and last error. A dead reminder is copied to the dead
+// letters by the same statement.
+func (r *Repository) SaveResult(ctx context.Context, reminder *models.Reminder) error {
+	query := `
+		WITH saved AS (
+		    UPDATE reminders
+		    SET status = $2, attempts = $3, next_attempt_at = $4, last_error = NULLIF($5, ''),
+		        updated_at = CURRENT_TIMESTAMP
+		    WHERE id = $1 AND status = 'sending'
+		    RETURNING id
+		)
+		INSERT INTO reminder_dead_letters (reminder_id, error, attempts)
+		SELECT id, $5, $3
+		FROM saved
+		WHERE $2 = 'dead';
+	`
+
+	_, err := r.db.Exec(ctx, query, reminder.ID, reminder.Status, reminder.Attempts, reminder.NextAttemptAt,
+		reminder.LastError)
+	if err != nil {
+		return fmt.Errorf("repository/SaveResult - %w", err)
+	}
+
+	return nil
+}
+
+// ListDeadLetters returns the newest dead letters first, up to limit.
+func (r *Repository) ListDeadLetters(ctx context.Context, limit int, includeReplayed bool) ([]*models.DeadLetter, error) {
+	query := `
+		SELECT d.id, d.error, d.attempts, d.created_at, d.replayed_at,
+		       r.id, r.event_id, r.user_id, r.event, r.date, r.mail, r.offset_minutes, r.fire_at, r.channel,
+		       r.status, r.attempts, r.next_attempt_at, COALESCE(r.last_error, ''), r.urgent
+		FROM reminder_dead_letters d
+		JOIN reminders r ON r.id = d.reminder_id
+		WHERE $1 OR d.replayed_at IS NULL
+		ORDER BY d.created_at DESC, d.id DESC
+		LIMIT $2;
+	`
+
+	rows, err := r.db.Query(ctx, query, includeReplayed, limit)
+	if err != nil {
+		return nil, fmt.Errorf("repository/ListDeadLetters - %w", err)
+	}
+	defer rows.Close()
+
+	letters := []*models.DeadLetter{}
+	for rows.Next() {
+		letter := models.DeadLetter{Reminder: &models.Reminder{}}
+		rem := letter.Reminder
+		err = rows.Scan(&letter.ID, &letter.Error, &letter.Attempts, &letter.CreatedAt, &letter.ReplayedAt,
+			&rem.ID, &rem.EventID, &rem.UserID, &rem.Event, &rem.Date, &rem.Mail, &rem.Offset, &rem.FireAt,
+			&rem.Channel, &rem.Status, &rem.Attempts, &rem.NextAttemptAt, &rem.LastError, &rem.Urgent)
+		if err != nil {
+			return nil, fmt.Errorf("repository/ListDeadLetters - %w", err)
+		}
+		letters = append(letters, &letter)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("repository/ListDeadLetters - %w", err)
+	}
+
+	return letters, nil
+}
+
+// ReplayDeadLetter marks the dead letter replayed and queues its reminder
+// again with a fresh set of attempts. A dead letter is replayed once.
+func (r *Repository) ReplayDeadLetter(ctx context.Context, ID uint, now time.Time) (uint, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("repository/ReplayDeadLetter - %w", err)
+	}
+	defer func() {
+		_ = tx.Rollback(ctx)
+	}()
+
+	query := `
+		UPDATE reminder_dead_letters
+		SET replayed_at = $2
+		WHERE id = $1 AND replayed_at IS NULL
+		RETURNING reminder_id;
+	`
+	var reminderID uint
+	err = tx.QueryRow(ctx, query, ID, now).Scan(&reminderID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return 0, ErrDeadLetterNotFound
+	}
+	if err != nil {
+		return 0, fmt.Errorf("repository/ReplayDeadLetter - %w", err)
+	}
+
+	query = `
+		UPDATE reminders
+		SET status = 'retry', attempts = 0, next_attempt_at = $2, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $1;
+	`
+	if _, err = tx.Exec(ctx, query, reminderID, now); err != nil {
+		return 0, fmt.Errorf("repository/ReplayDeadLetter - %w", err)
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return 0, fmt.Errorf("repository/ReplayDeadLetter - %w", err)
+	}
+
+	return reminderID, nil
+}
+
+func scanReminders(rows pgx.Rows) ([]*models.Reminder, error) {
+	defer rows.Close()
+
+	reminders := []*models.Reminder{}
+	for rows.Next() {
+		var rem models.Reminder
+		err := rows.Scan(&rem.ID, &rem.EventID, &rem.UserID, &rem.Event, &rem.Date, &rem.Mail, &rem.Offset,
+			&rem.FireAt, &rem.Channel, &rem.Status, &rem.Attempts, &rem.NextAttemptAt, &rem.LastError, &rem.Urgent)
+		if err != nil {
+			return nil, err
+		}
+		reminders = append(reminders, &rem)
+	}
+
+	return reminders, rows.Err()
+}
@@ -0,0 +1,215 @@
+package reminder
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/pashagolub/pgxmock/v4"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/avraam311/improved-calendar-service/internal/models"
+)
+
+func newTestRepo(t *testing.T) (*Repository, pgxmock.PgxPoolIface) {
+	mock, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatalf("failed to create pgxmock: %v", err)
+	}
+	return New(mock), mock
+}
+
+var claimedRows = []string{"id", "event_id", "user_id", "event", "date", "mail", "offset_minutes", "fire_at", "channel",
+	"status", "attempts", "next_attempt_at", "last_error", "urgent", "tz", "all_day"}
+
+func TestRepositoryClaimDue(t *testing.T) {
+	repo, mock := newTestRepo(t)
+	defer mock.Close()
+
+	now := time.Date(2025, 9, 1, 9, 0, 0, 0, time.UTC)
+	leaseUntil := now.Add(10 * time.Minute)
+	date := now.Add(30 * time.Minute)
+	fireAt := date.Add(-time.Hour)
+	started := now.Add(-15 * time.Minute)
+
+	// Only pending reminders are marked missed, deferred ones of started
+	// occurrences are still claimed.
+	mock.ExpectBegin()
+	mock.ExpectExec(`(?s)SET status = 'missed'.*WHERE status = 'pending' AND attempts = 0 AND date < \$1 AND fire_at < \$2`).
+		WithArgs(now, now.Add(-missedGrace)).
+		WillReturnResult(pgxmock.NewResult("UPDATE", 0))
+	mock.ExpectQuery(`(?s)WHERE status IN \('pending', 'retry', 'sending', 'deferred'\).*FOR UPDATE SKIP LOCKED.*RETURNING .*e.tz, e.all_day`).
+		WithArgs(now, 10, leaseUntil).
+		WillReturnRows(pgxmock.NewRows(claimedRows).
+			AddRow(uint(1), uint(7), 1, "Review", date, "alice@example.com", 60, fireAt, models.ChannelEmail, models.ReminderPending, 0, fireAt, "", false, "Europe/Berlin", false).
+			AddRow(uint(2), uint(8), 1, "Standup", date, "bob@example.com", 60, fireAt, models.ChannelEmail, models.ReminderRetry, 1, now, "smtp unavailable", false, "UTC", false).
+			AddRow(uint(3), uint(9), 1, "Retro", started, "carol@example.com", 60, started.Add(-time.Hour), models.ChannelEmail, models.ReminderDeferred, 0, now, "", false, "UTC", true))
+	mock.ExpectCommit()
+	mock.ExpectRollback()
+
+	claimed, err := repo.ClaimDue(context.Background(), now, 10, leaseUntil)
+	assert.NoError(t, err)
+	assert.Len(t, claimed, 3)
+	assert.Equal(t, models.ReminderPending, claimed[0].Status)
+	assert.Equal(t, "Europe/Berlin", claimed[0].TZ)
+	assert.Equal(t, 1, claimed[1].Attempts)
+	assert.Equal(t, models.ReminderDeferred, claimed[2].Status)
+	assert.True(t, claimed[2].AllDay)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRepositoryClaimDueAtStart(t *testing.T) {
+	repo, mock := newTestRepo(t)
+	defer mock.Close()
+
+	now := time.Date(2025, 9, 1, 9, 0, 30, 0, time.UTC)
+	leaseUntil := now.Add(10 * time.Minute)
+	date := time.Date(2025, 9, 1, 9, 0, 0, 0, time.UTC)
+
+	// A reminder at the start of the event fires when its occurrence starts,
+	// the sweep only takes reminders that missed their fire time by more than
+	// the grace, so it is claimed.
+	mock.ExpectBegin()
+	mock.ExpectExec(`(?s)SET status = 'missed'.*AND fire_at < \$2`).
+		WithArgs(now, now.Add(-missedGrace)).
+		WillReturnResult(pgxmock.NewResult("UPDATE", 0))
+	mock.ExpectQuery(`(?s)WHERE status IN \('pending', 'retry', 'sending', 'deferred'\).*FOR UPDATE SKIP LOCKED`).
+		WithArgs(now, 10, leaseUntil).
+		WillReturnRows(pgxmock.NewRows(claimedRows).
+			AddRow(uint(1), uint(7), 1, "Review", date, "alice@example.com", 0, date, models.ChannelEmail, models.ReminderPending, 0, date, "", false, "UTC", false))
+	mock.ExpectCommit()
+	mock.ExpectRollback()
+
+	claimed, err := repo.ClaimDue(context.Background(), now, 10, leaseUntil)
+	assert.NoError(t, err)
+	assert.Len(t, claimed, 1)
+	assert.Equal(t, 0, claimed[0].Offset)
+	assert.Equal(t, date, claimed[0].FireAt)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRepositorySaveResult(t *testing.T) {
+	repo, mock := newTestRepo(t)
+	defer mock.Close()
+
+	retryAt := time.Date(2025, 9, 1, 9, 2, 0, 0, time.UTC)
+	reminders := []*models.Reminder{
+		{ID: 1, Status: models.ReminderSent, Attempts: 1, NextAttemptAt: retryAt},
+		{ID: 2, Status: models.ReminderRetry, Attempts: 2, NextAttemptAt: retryAt, LastError: "smtp unavailable"},
+		{ID: 3, Status: models.ReminderDead, Attempts: 5, NextAttemptAt: retryAt, LastError: "smtp unavailable"},
+	}
+
+	mock.ExpectExec("INSERT INTO reminder_dead_letters").
+		WithArgs(uint(1), models.ReminderSent, 1, retryAt, "").
+		WillReturnResult(pgxmock.NewResult("INSERT", 0))
+	mock.ExpectExec("INSERT INTO reminder_dead_letters").
+		WithArgs(uint(2), models.ReminderRetry, 2, retryAt, "smtp unavailable").
+		WillReturnResult(pgxmock.NewResult("INSERT", 0))
+	mock.ExpectExec("INSERT INTO reminder_dead_letters").
+		WithArgs(uint(3), models.ReminderDead, 5, retryAt, "smtp unavailable").
+		WillReturnResult(pgxmock.NewResult("INSERT", 1))
+
+	for _, reminder := range reminders {
+		assert.NoError(t, repo.SaveResult(context.Background(), reminder))
+	}
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRepositoryReplayDeadLetter(t *testing.T) {
+	repo, mock := newTestRepo(t)
+	defer mock.Close()
+
+	now := time.Date(2025, 9, 1, 9, 0, 0, 0, time.UTC)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("UPDATE reminder_dead_letters").
+		WithArgs(uint(4), now).
+		WillReturnRows(pgxmock.NewRows([]string{"reminder_id"}).AddRow(uint(3)))
+	mock.ExpectExec("UPDATE reminders").
+		WithArgs(uint(3), now).
+		WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+	mock.ExpectCommit()
+	mock.ExpectRollback()
+
+	reminderID, err := repo.ReplayDeadLetter(context.Background(), 4, now)
+	assert.NoError(t, err)
+	assert.Equal(t, uint(3), reminderID)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRepositoryReplayDeadLetterNotFound(t *testing.T) {
+	repo, mock := newTestRepo(t)
+	defer mock.Close()
+
+	now := time.Date(2025, 9, 1, 9, 0, 0, 0, time.UTC)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("UPDATE reminder_dead_letters").
+		WithArgs(uint(4), now).
+		WillReturnError(pgx.ErrNoRows)
+	mock.ExpectRollback()
+
+	_, err := repo.ReplayDeadLetter(context.Background(), 4, now)
+	assert.ErrorIs(t, err, ErrDeadLetterNotFound)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRepositorySaveRemindersReplacesPending(t *testing.T) {
+	repo, mock := newTestRepo(t)
+	defer mock.Close()
+
+	now := time.Date(2025, 8, 30, 9, 0, 0, 0, time.UTC)
+	date := time.Date(2025, 9, 1, 9, 0, 0, 0, time.UTC)
+	reminders := []*models.Reminder{
+		{UserID: 1, Event: "Review", Date: date, Mail: "alice@example.com", Offset: 1440,
+			FireAt: date.AddDate(0, 0, -1), Channel: models.ChannelEmail},
+		{UserID: 1, Event: "Review", Date: date, Mail: "alice@example.com", Offset: 15,
+			FireAt: date.Add(-15 * time.Minute), Channel: models.ChannelEmail},
+	}
+
+	mock.ExpectBegin()
+	mock.ExpectExec("DELETE FROM reminders").
+		WithArgs(uint(7), now, []time.Time{date, date}, []int{1440, 15},
+			[]string{models.ChannelEmail, models.ChannelEmail}).
+		WillReturnResult(pgxmock.NewResult("DELETE", 1))
+	mock.ExpectExec("INSERT INTO reminders").
+		WithArgs(uint(7), 1, "Review", date, "alice@example.com", 1440, date.AddDate(0, 0, -1), models.ChannelEmail, false).
+		WillReturnResult(pgxmock.NewResult("INSERT", 0))
+	mock.ExpectExec("INSERT INTO reminders").
+		WithArgs(uint(7), 1, "Review", date, "alice@example.com", 15, date.Add(-15*time.Minute), models.ChannelEmail, false).
+		WillReturnResult(pgxmock.NewResult("INSERT", 1))
+	mock.ExpectCommit()
+	mock.ExpectRollback()
+
+	assert.NoError(t, repo.SaveReminders(context.Background(), 7, now, reminders))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRepositorySaveRemindersDropsStaleDeferred(t *testing.T) {
+	repo, mock := newTestRepo(t)
+	defer mock.Close()
+
+	now := time.Date(2025, 9, 1, 8, 0, 0, 0, time.UTC)
+	moved := time.Date(2025, 9, 1, 11, 0, 0, 0, time.UTC)
+	reminders := []*models.Reminder{
+		{UserID: 1, Event: "Design review", Date: moved, Mail: "alice@example.com", Offset: 60,
+			FireAt: moved.Add(-time.Hour), Channel: models.ChannelEmail},
+	}
+
+	// The reminder deferred for the old start of the occurrence is not among
+	// the new ones and is deleted with the pending ones, a deferred or retried
+	// reminder of the new start would be kept and renamed by the insert.
+	mock.ExpectBegin()
+	mock.ExpectExec(`(?s)DELETE FROM reminders.*status = 'pending' OR.*status IN \('deferred', 'retry'\) AND date >= \$2.*NOT IN`).
+		WithArgs(uint(7), now, []time.Time{moved}, []int{60}, []string{models.ChannelEmail}).
+		WillReturnResult(pgxmock.NewResult("DELETE", 1))
+	mock.ExpectExec(`(?s)UPDATE reminders.*SET event = \$3.*status IN \('deferred', 'retry'\).*INSERT INTO reminders`).
+		WithArgs(uint(7), 1, "Design review", moved, "alice@example.com", 60, moved.Add(-time.Hour), models.ChannelEmail, false).
+		WillReturnResult(pgxmock.NewResult("INSERT", 1))
+	mock.ExpectCommit()
+	mock.ExpectRollback()
+
+	assert.NoError(t, repo.SaveReminders(context.Background(), 7, now, reminders))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
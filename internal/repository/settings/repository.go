@@ -0,0 +1,83 @@
+package settings
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+
+	"github.com/avraam311/improved-calendar-service/internal/models"
+)
+
+type DB interface {
+	Exec(ctx context.Context, sql string, arguments ...any) (pgconn.CommandTag, error)
+	QueryRow(ctx context.Context, sql string, optionsAndArgs ...any) pgx.Row
+}
+
+type Repository struct {
+	db DB
+}
+
+func New(db DB) *Repository {
+	return &Repository{
+		db: db,
+	}
+}
+
+// GetSettings returns the settings of the user. Users who never saved their
+// settings get empty ones.
+func (r *Repository) GetSettings(ctx context.Context, userID int) (*models.UserSettings, error) {
+	query := `
+		SELECT COALESCE(conflict_policy, ''), COALESCE(locale, ''), reminders, channels, digest, quiet
+		FROM user_settings
+		WHERE user_id = $1;
+	`
+
+	settings := &models.UserSettings{UserID: userID}
+	err := r.db.QueryRow(ctx, query, userID).Scan(&settings.ConflictPolicy, &settings.Locale, &settings.Reminders,
+		&settings.Channels, &settings.Digest, &settings.Quiet)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return nil, fmt.Errorf("repository/GetSettings - %w", err)
+	}
+
+	return settings, nil
+}
+
+// SaveSettings replaces the settings of the user.
+func (r *Repository) SaveSettings(ctx context.Context, settings *models.UserSettings) error {
+	query := `
+		INSERT INTO user_settings (
+		    user_id, conflict_policy, locale, reminders, channels, digest, quiet
+		) VALUES ($1, NULLIF($2, ''), NULLIF($3, ''), $4, $5, $6, $7)
+		ON CONFLICT (user_id) DO UPDATE
+		SET conflict_policy = EXCLUDED.conflict_policy, locale = EXCLUDED.locale, reminders = EXCLUDED.reminders,
+		    channels = EXCLUDED.channels, digest = EXCLUDED.digest, quiet = EXCLUDED.quiet,
+		    updated_at = CURRENT_TIMESTAMP;
+	`
+
+	var reminders any
+	if settings.Reminders != nil {
+		reminders = settings.Reminders
+	}
+	var channels any
+	if settings.Channels != nil {
+		channels = settings.Channels
+	}
+	var digest any
+	if settings.Digest != nil {
+		digest = settings.Digest
+	}
+	var quiet any
+	if settings.Quiet != nil {
+		quiet = settings.Quiet
+	}
+	_, err := r.db.Exec(ctx, query, settings.UserID, settings.ConflictPolicy, settings.Locale, reminders, channels,
+		digest, quiet)
+	if err != nil {
+		return fmt.Errorf("repository/SaveSettings - %w", err)
+	}
+
+	return nil
+}
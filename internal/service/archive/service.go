@@ -0,0 +1,65 @@
+package archive
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/avraam311/improved-calendar-service/internal/models"
+)
+
+// defaultArchivedEvents is the number of archived events listed when no limit
+// is given.
+const defaultArchivedEvents = 100
+
+//go:generate mockgen -source=service.go -destination=../../mocks/mock_archive_service.go -package=mocks -mock_names=archiveRepo=MockarchiveServiceRepo,reminderScheduler=MockarchiveReminderScheduler
+type archiveRepo interface {
+	GetArchivedEvents(ctx context.Context, get *models.ArchiveGet) ([]*models.ArchivedEvent, error)
+	RestoreEvent(ctx context.Context, userID int, ID uint) (uint, error)
+}
+
+type reminderScheduler interface {
+	ScheduleReminders(ctx context.Context, IDs ...uint) error
+}
+
+// Service gives the users access to their events moved to the archive by the
+// cleaner.
+type Service struct {
+	archiveRepo archiveRepo
+	scheduler   reminderScheduler
+}
+
+func New(r archiveRepo, s reminderScheduler) *Service {
+	return &Service{
+		archiveRepo: r,
+		scheduler:   s,
+	}
+}
+
+func (s *Service) GetArchivedEvents(ctx context.Context, get *models.ArchiveGet) ([]*models.ArchivedEvent, error) {
+	if get.Limit == 0 {
+		get.Limit = defaultArchivedEvents
+	}
+
+	events, err := s.archiveRepo.GetArchivedEvents(ctx, get)
+	if err != nil {
+		return nil, fmt.Errorf("service/GetArchivedEvents - %w", err)
+	}
+
+	return events, nil
+}
+
+// RestoreEvent moves the archived event back to the calendar of the user and
+// schedules its reminders again. The cleaner keeps the event for its
+// retention counted from the restore.
+func (s *Service) RestoreEvent(ctx context.Context, restore *models.ArchiveRestore) (uint, error) {
+	ID, err := s.archiveRepo.RestoreEvent(ctx, restore.UserID, restore.ID)
+	if err != nil {
+		return 0, fmt.Errorf("service/RestoreEvent - %w", err)
+	}
+
+	if err = s.scheduler.ScheduleReminders(ctx, ID); err != nil {
+		return ID, fmt.Errorf("service/RestoreEvent - %w", err)
+	}
+
+	return ID, nil
+}
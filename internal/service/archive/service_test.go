@@ -0,0 +1,77 @@
+//go:build unit
+// +build unit
+
+package archive
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+
+	archiveR "github.com/avraam311/improved-calendar-service/internal/mocks"
+	"github.com/avraam311/improved-calendar-service/internal/models"
+	repository "github.com/avraam311/improved-calendar-service/internal/repository/archive"
+)
+
+func TestServiceGetArchivedEventsDefaultsLimit(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := archiveR.NewMockarchiveServiceRepo(ctrl)
+	svc := New(mockRepo, archiveR.NewMockarchiveReminderScheduler(ctrl))
+
+	mockRepo.EXPECT().
+		GetArchivedEvents(gomock.Any(), &models.ArchiveGet{UserID: 1, Limit: defaultArchivedEvents}).
+		Return([]*models.ArchivedEvent{{ID: 7}}, nil)
+
+	events, err := svc.GetArchivedEvents(context.Background(), &models.ArchiveGet{UserID: 1})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(events) != 1 {
+		t.Fatalf("expected 1 archived event, got %d", len(events))
+	}
+}
+
+func TestServiceRestoreEventSchedulesReminders(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := archiveR.NewMockarchiveServiceRepo(ctrl)
+	scheduler := archiveR.NewMockarchiveReminderScheduler(ctrl)
+	svc := New(mockRepo, scheduler)
+
+	mockRepo.EXPECT().
+		RestoreEvent(gomock.Any(), 1, uint(7)).
+		Return(uint(7), nil)
+	scheduler.EXPECT().
+		ScheduleReminders(gomock.Any(), uint(7)).
+		Return(nil)
+
+	ID, err := svc.RestoreEvent(context.Background(), &models.ArchiveRestore{ID: 7, UserID: 1})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if ID != 7 {
+		t.Fatalf("expected event 7, got %d", ID)
+	}
+}
+
+func TestServiceRestoreEventNotFound(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := archiveR.NewMockarchiveServiceRepo(ctrl)
+	svc := New(mockRepo, archiveR.NewMockarchiveReminderScheduler(ctrl))
+
+	mockRepo.EXPECT().
+		RestoreEvent(gomock.Any(), 1, uint(8)).
+		Return(uint(0), repository.ErrArchivedEventNotFound)
+
+	_, err := svc.RestoreEvent(context.Background(), &models.ArchiveRestore{ID: 8, UserID: 1})
+	if !errors.Is(err, repository.ErrArchivedEventNotFound) {
+		t.Fatalf("expected ErrArchivedEventNotFound, got %v", err)
+	}
+}
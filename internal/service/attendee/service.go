@@ -0,0 +1,312 @@
+package attendee
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/avraam311/improved-calendar-service/internal/models"
+	"github.com/avraam311/improved-calendar-service/internal/pkg/ical"
+	sender "github.com/avraam311/improved-calendar-service/internal/pkg/notifier"
+	"github.com/avraam311/improved-calendar-service/internal/pkg/templates"
+	attendeeR "github.com/avraam311/improved-calendar-service/internal/repository/attendee"
+)
+
+var (
+	ErrNotOrganizer = errors.New("only the organiser can do this")
+	ErrNotInvited   = errors.New("user is not invited to the event")
+	ErrNotSent      = errors.New("some emails were not sent")
+)
+
+//go:generate mockgen -source=service.go -destination=../../mocks/mock_attendee_service.go -package=mocks -mock_names=settingsRepo=MockattendeeSettingsRepo
+type attendeeRepo interface {
+	GetEvent(ctx context.Context, eventID uint) (*models.Event, string, error)
+	AddAttendees(ctx context.Context, eventID uint, attendees []*models.Attendee) ([]*models.Attendee, error)
+	RemoveAttendee(ctx context.Context, eventID uint, email string) (*models.Attendee, error)
+	SetStatus(ctx context.Context, response *models.AttendeeResponse) (*models.Attendee, error)
+	GetAttendees(ctx context.Context, eventID uint) ([]*models.Attendee, error)
+}
+
+type settingsRepo interface {
+	GetSettings(ctx context.Context, userID int) (*models.UserSettings, error)
+}
+
+type mailer interface {
+	Send(msg *sender.Message) error
+}
+
+type renderer interface {
+	Render(kind, locale string, data *templates.Data) (*templates.Rendered, error)
+}
+
+// Service keeps track of the attendees of events. Invitations, updates and
+// cancellations are mailed to the attendees and their responses to the
+// organiser as iMIP messages, in the language of the recipient.
+type Service struct {
+	attendeeRepo attendeeRepo
+	settingsRepo settingsRepo
+	mail         mailer
+	renderer     renderer
+}
+
+func New(r attendeeRepo, sr settingsRepo, m mailer, rn renderer) *Service {
+	return &Service{
+		attendeeRepo: r,
+		settingsRepo: sr,
+		mail:         m,
+		renderer:     rn,
+	}
+}
+
+// Invite adds the attendees to the event of the organiser and mails an
+// invitation to every new one. The attendees are stored even when some of
+// the invitations could not be sent, in which case ErrNotSent is returned
+// along with them.
+func (s *Service) Invite(ctx context.Context, invite *models.AttendeeInvite) ([]*models.Attendee, error) {
+	event, organizer, err := s.organizerEvent(ctx, invite.EventID, invite.UserID)
+	if err != nil {
+		return nil, fmt.Errorf("service/Invite - %w", err)
+	}
+
+	added, err := s.attendeeRepo.AddAttendees(ctx, invite.EventID, invite.Attendees)
+	if err != nil {
+		return nil, fmt.Errorf("service/Invite - %w", err)
+	}
+	if len(added) == 0 {
+		return added, nil
+	}
+
+	attendees, err := s.attendeeRepo.GetAttendees(ctx, invite.EventID)
+	if err != nil {
+		return added, fmt.Errorf("service/Invite - %w: %w", ErrNotSent, err)
+	}
+
+	cal := ical.NewInvitation(ical.MethodRequest, event, organizer, attendees, time.Now())
+	err = s.sendAll(ctx, event, added, templates.TypeInvite, eventData(event, organizer), ical.MethodRequest, cal)
+	if err != nil {
+		return added, fmt.Errorf("service/Invite - %w", err)
+	}
+
+	return added, nil
+}
+
+// Uninvite removes the attendee from the event of the organiser and mails a
+// cancellation to them.
+func (s *Service) Uninvite(ctx context.Context, remove *models.AttendeeRemove) (*models.Attendee, error) {
+	event, organizer, err := s.organizerEvent(ctx, remove.EventID, remove.UserID)
+	if err != nil {
+		return nil, fmt.Errorf("service/Uninvite - %w", err)
+	}
+
+	attendee, err := s.attendeeRepo.RemoveAttendee(ctx, remove.EventID, remove.Email)
+	if err != nil {
+		return nil, fmt.Errorf("service/Uninvite - %w", err)
+	}
+
+	attendees := []*models.Attendee{attendee}
+	cal := ical.NewInvitation(ical.MethodCancel, event, organizer, attendees, time.Now())
+	data := eventData(event, organizer)
+	data.Removed = true
+	err = s.sendAll(ctx, event, attendees, templates.TypeCancel, data, ical.MethodCancel, cal)
+	if err != nil {
+		return attendee, fmt.Errorf("service/Uninvite - %w", err)
+	}
+
+	return attendee, nil
+}
+
+// Respond records the response of an attendee and mails it to the organiser.
+// The response is stored even when the email could not be sent.
+func (s *Service) Respond(ctx context.Context, response *models.AttendeeResponse) (*models.Attendee, error) {
+	event, organizer, err := s.attendeeRepo.GetEvent(ctx, response.EventID)
+	if err != nil {
+		return nil, fmt.Errorf("service/Respond - %w", err)
+	}
+
+	attendee, err := s.attendeeRepo.SetStatus(ctx, response)
+	if err != nil {
+		return nil, fmt.Errorf("service/Respond - %w", err)
+	}
+
+	cal := ical.NewInvitation(ical.MethodReply, event, organizer, []*models.Attendee{attendee}, time.Now())
+	data := eventData(event, organizer)
+	data.Attendee, data.Status = attendee.Email, attendee.Status
+	err = s.send(ctx, organizer, event.UserID, templates.TypeReply, data, ical.MethodReply, cal)
+	if err != nil {
+		return attendee, fmt.Errorf("service/Respond - %w: %w", ErrNotSent, err)
+	}
+
+	return attendee, nil
+}
+
+// GetAttendees returns the attendees of the event to its organiser and to
+// the invited users.
+func (s *Service) GetAttendees(ctx context.Context, get *models.AttendeesGet) ([]*models.Attendee, error) {
+	event, _, err := s.attendeeRepo.GetEvent(ctx, get.EventID)
+	if err != nil {
+		return nil, fmt.Errorf("service/GetAttendees - %w", err)
+	}
+
+	attendees, err := s.attendeeRepo.GetAttendees(ctx, get.EventID)
+	if err != nil {
+		return nil, fmt.Errorf("service/GetAttendees - %w", err)
+	}
+
+	if event.UserID == get.UserID {
+		return attendees, nil
+	}
+	for _, attendee := range attendees {
+		if attendee.UserID == get.UserID {
+			return attendees, nil
+		}
+	}
+
+	return nil, fmt.Errorf("service/GetAttendees - %w", ErrNotInvited)
+}
+
+// NotifyUpdated mails the current state of the event to its attendees. Any
+// failure is reported as ErrNotSent, the update itself is already stored.
+func (s *Service) NotifyUpdated(ctx context.Context, eventID uint) error {
+	event, organizer, attendees, err := s.invitation(ctx, eventID)
+	if err != nil || len(attendees) == 0 {
+		return err
+	}
+
+	cal := ical.NewInvitation(ical.MethodRequest, event, organizer, attendees, time.Now())
+	err = s.sendAll(ctx, event, attendees, templates.TypeUpdate, eventData(event, organizer), ical.MethodRequest, cal)
+	if err != nil {
+		return fmt.Errorf("service/NotifyUpdated - %w", err)
+	}
+
+	return nil
+}
+
+// PrepareCancel loads the attendees of an event about to be deleted, they are
+// deleted along with it. The returned function mails them the cancellation.
+func (s *Service) PrepareCancel(ctx context.Context, eventID uint) (func() error, error) {
+	event, organizer, attendees, err := s.invitation(ctx, eventID)
+	if err != nil {
+		return nil, err
+	}
+
+	return func() error {
+		if len(attendees) == 0 {
+			return nil
+		}
+
+		cal := ical.NewCancellation(event, organizer, attendees, time.Now())
+		err := s.sendAll(ctx, event, attendees, templates.TypeCancel, eventData(event, organizer), ical.MethodCancel, cal)
+		if err != nil {
+			return fmt.Errorf("service/PrepareCancel - %w", err)
+		}
+
+		return nil
+	}, nil
+}
+
+// invitation loads the event and its attendees. Events that don't exist or
+// aren't series or single events have no attendees.
+func (s *Service) invitation(ctx context.Context, eventID uint) (*models.Event, string, []*models.Attendee, error) {
+	event, organizer, err := s.attendeeRepo.GetEvent(ctx, eventID)
+	if errors.Is(err, attendeeR.ErrEventNotFound) {
+		return nil, "", nil, nil
+	}
+	if err != nil {
+		return nil, "", nil, fmt.Errorf("service/invitation - %w: %w", ErrNotSent, err)
+	}
+
+	attendees, err := s.attendeeRepo.GetAttendees(ctx, eventID)
+	if err != nil {
+		return nil, "", nil, fmt.Errorf("service/invitation - %w: %w", ErrNotSent, err)
+	}
+
+	return event, organizer, attendees, nil
+}
+
+func (s *Service) organizerEvent(ctx context.Context, eventID uint, userID int) (*models.Event, string, error) {
+	event, organizer, err := s.attendeeRepo.GetEvent(ctx, eventID)
+	if err != nil {
+		return nil, "", err
+	}
+	if event.UserID != userID {
+		return nil, "", ErrNotOrganizer
+	}
+
+	return event, organizer, nil
+}
+
+// sendAll mails the notification to every attendee separately and reports
+// the failures as ErrNotSent. Outside attendees get it in the language of
+// the organiser.
+func (s *Service) sendAll(ctx context.Context, event *models.Event, attendees []*models.Attendee, kind string,
+	data *templates.Data, method string, cal *ical.Component) error {
+	var sendErrs []error
+	for _, attendee := range attendees {
+		userID := attendee.UserID
+		if userID == 0 {
+			userID = event.UserID
+		}
+		if err := s.send(ctx, attendee.Email, userID, kind, data, method, cal); err != nil {
+			sendErrs = append(sendErrs, err)
+		}
+	}
+	if len(sendErrs) > 0 {
+		return fmt.Errorf("%w: %w", ErrNotSent, errors.Join(sendErrs...))
+	}
+
+	return nil
+}
+
+func (s *Service) send(ctx context.Context, to string, userID int, kind string, data *templates.Data, method string,
+	cal *ical.Component) error {
+	var buf bytes.Buffer
+	if err := cal.Encode(&buf); err != nil {
+		return err
+	}
+
+	msg, err := s.renderer.Render(kind, s.locale(ctx, userID), data)
+	if err != nil {
+		return err
+	}
+
+	return s.mail.Send(&sender.Message{
+		To:       []string{to},
+		Subject:  msg.Subject,
+		Text:     msg.Text,
+		HTML:     msg.HTML,
+		Calendar: buf.Bytes(),
+		Method:   method,
+	})
+}
+
+// locale returns the locale of the user. The mail goes out in the default
+// language when the settings can't be read.
+func (s *Service) locale(ctx context.Context, userID int) string {
+	settings, err := s.settingsRepo.GetSettings(ctx, userID)
+	if err != nil {
+		return templates.DefaultLocale
+	}
+
+	return settings.Locale
+}
+
+// eventData describes the event in its own time zone.
+func eventData(event *models.Event, organizer string) *templates.Data {
+	return &templates.Data{
+		Event:     event.Event,
+		Date:      event.Date.In(location(event.TZ)),
+		AllDay:    event.AllDay,
+		Organizer: organizer,
+	}
+}
+
+func location(tz string) *time.Location {
+	loc, err := time.LoadLocation(tz)
+	if err != nil {
+		return time.UTC
+	}
+
+	return loc
+}
@@ -0,0 +1,253 @@
+//go:build unit
+// +build unit
+
+package attendee
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+
+	attendeeR "github.com/avraam311/improved-calendar-service/internal/mocks"
+	"github.com/avraam311/improved-calendar-service/internal/models"
+	"github.com/avraam311/improved-calendar-service/internal/pkg/ical"
+	sender "github.com/avraam311/improved-calendar-service/internal/pkg/notifier"
+	"github.com/avraam311/improved-calendar-service/internal/pkg/templates"
+	repository "github.com/avraam311/improved-calendar-service/internal/repository/attendee"
+)
+
+// newSettingsRepo returns settings without a locale for every user.
+func newSettingsRepo(ctrl *gomock.Controller) *attendeeR.MockattendeeSettingsRepo {
+	settingsRepo := attendeeR.NewMockattendeeSettingsRepo(ctrl)
+	settingsRepo.EXPECT().
+		GetSettings(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, userID int) (*models.UserSettings, error) {
+			return &models.UserSettings{UserID: userID}, nil
+		}).
+		AnyTimes()
+	return settingsRepo
+}
+
+func newRenderer(t *testing.T) *templates.Renderer {
+	renderer, err := templates.Load(templates.Default())
+	if err != nil {
+		t.Fatalf("failed to load templates: %v", err)
+	}
+	return renderer
+}
+
+func TestServiceInviteMailsNewAttendees(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := attendeeR.NewMockattendeeRepo(ctrl)
+	mockMail := attendeeR.NewMockmailer(ctrl)
+	svc := New(mockRepo, newSettingsRepo(ctrl), mockMail, newRenderer(t))
+
+	event := &models.Event{ID: 1, UserID: 1, Event: "Planning", Date: time.Date(2025, 9, 1, 10, 0, 0, 0, time.UTC), TZ: "UTC"}
+	invite := &models.AttendeeInvite{EventID: 1, UserID: 1, Attendees: []*models.Attendee{
+		{UserID: 2, Email: "bob@example.com"},
+		{Email: "carol@example.com"},
+	}}
+	added := []*models.Attendee{{ID: 1, EventID: 1, Email: "carol@example.com", Status: models.AttendeeNeedsAction}}
+
+	mockRepo.EXPECT().
+		GetEvent(gomock.Any(), uint(1)).
+		Return(event, "alice@example.com", nil)
+	mockRepo.EXPECT().
+		AddAttendees(gomock.Any(), uint(1), invite.Attendees).
+		Return(added, nil)
+	mockRepo.EXPECT().
+		GetAttendees(gomock.Any(), uint(1)).
+		Return(append([]*models.Attendee{{ID: 2, EventID: 1, UserID: 2, Email: "bob@example.com",
+			Status: models.AttendeeAccepted}}, added...), nil)
+	mockMail.EXPECT().
+		Send(gomock.Any()).
+		DoAndReturn(func(msg *sender.Message) error {
+			if len(msg.To) != 1 || msg.To[0] != "carol@example.com" || msg.Subject != "Invitation: Planning" {
+				t.Fatalf("unexpected message to %v: %q", msg.To, msg.Subject)
+			}
+			if !strings.Contains(msg.Text, "alice@example.com") || !strings.Contains(msg.Text, "2025-09-01 10:00") {
+				t.Fatalf("unexpected text %q", msg.Text)
+			}
+			cal := strings.ReplaceAll(string(msg.Calendar), "\r\n ", "")
+			if msg.Method != ical.MethodRequest || !strings.Contains(cal, "METHOD:REQUEST") ||
+				!strings.Contains(cal, "ORGANIZER:mailto:alice@example.com") ||
+				!strings.Contains(cal, "mailto:bob@example.com") {
+				t.Fatalf("unexpected calendar %q", cal)
+			}
+			return nil
+		})
+
+	attendees, err := svc.Invite(context.Background(), invite)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(attendees) != 1 || attendees[0] != added[0] {
+		t.Fatalf("unexpected attendees %v", attendees)
+	}
+}
+
+func TestServiceInviteRequiresOrganizer(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := attendeeR.NewMockattendeeRepo(ctrl)
+	svc := New(mockRepo, newSettingsRepo(ctrl), attendeeR.NewMockmailer(ctrl), newRenderer(t))
+
+	mockRepo.EXPECT().
+		GetEvent(gomock.Any(), uint(1)).
+		Return(&models.Event{ID: 1, UserID: 1}, "alice@example.com", nil)
+
+	_, err := svc.Invite(context.Background(), &models.AttendeeInvite{EventID: 1, UserID: 2,
+		Attendees: []*models.Attendee{{Email: "carol@example.com"}}})
+	if !errors.Is(err, ErrNotOrganizer) {
+		t.Fatalf("expected ErrNotOrganizer, got %v", err)
+	}
+}
+
+func TestServiceRespondKeepsStatusWhenMailFails(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := attendeeR.NewMockattendeeRepo(ctrl)
+	mockMail := attendeeR.NewMockmailer(ctrl)
+	svc := New(mockRepo, newSettingsRepo(ctrl), mockMail, newRenderer(t))
+
+	response := &models.AttendeeResponse{EventID: 1, UserID: 2, Status: models.AttendeeAccepted}
+	attendee := &models.Attendee{ID: 3, EventID: 1, UserID: 2, Email: "bob@example.com", Status: models.AttendeeAccepted}
+
+	mockRepo.EXPECT().
+		GetEvent(gomock.Any(), uint(1)).
+		Return(&models.Event{ID: 1, UserID: 1, Event: "Planning", TZ: "UTC"}, "alice@example.com", nil)
+	mockRepo.EXPECT().
+		SetStatus(gomock.Any(), response).
+		Return(attendee, nil)
+	mockMail.EXPECT().
+		Send(gomock.Any()).
+		DoAndReturn(func(msg *sender.Message) error {
+			if len(msg.To) != 1 || msg.To[0] != "alice@example.com" || msg.Method != ical.MethodReply {
+				t.Fatalf("unexpected message to %v with method %q", msg.To, msg.Method)
+			}
+			return errors.New("smtp unavailable")
+		})
+
+	got, err := svc.Respond(context.Background(), response)
+	if !errors.Is(err, ErrNotSent) {
+		t.Fatalf("expected ErrNotSent, got %v", err)
+	}
+	if got != attendee {
+		t.Fatalf("expected the stored attendee, got %v", got)
+	}
+}
+
+func TestServicePrepareCancelMailsAttendees(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := attendeeR.NewMockattendeeRepo(ctrl)
+	mockMail := attendeeR.NewMockmailer(ctrl)
+	svc := New(mockRepo, newSettingsRepo(ctrl), mockMail, newRenderer(t))
+
+	mockRepo.EXPECT().
+		GetEvent(gomock.Any(), uint(1)).
+		Return(&models.Event{ID: 1, UserID: 1, Event: "Planning", TZ: "UTC"}, "alice@example.com", nil)
+	mockRepo.EXPECT().
+		GetAttendees(gomock.Any(), uint(1)).
+		Return([]*models.Attendee{
+			{ID: 1, EventID: 1, Email: "bob@example.com", Status: models.AttendeeAccepted},
+			{ID: 2, EventID: 1, Email: "carol@example.com", Status: models.AttendeeNeedsAction},
+		}, nil)
+
+	cancel, err := svc.PrepareCancel(context.Background(), 1)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var sent []string
+	mockMail.EXPECT().
+		Send(gomock.Any()).
+		DoAndReturn(func(msg *sender.Message) error {
+			if msg.Method != ical.MethodCancel || !strings.Contains(string(msg.Calendar), "STATUS:CANCELLED") {
+				t.Fatalf("unexpected cancellation %q", msg.Calendar)
+			}
+			sent = append(sent, msg.To...)
+			return nil
+		}).
+		Times(2)
+
+	if err = cancel(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(sent) != 2 || sent[0] != "bob@example.com" || sent[1] != "carol@example.com" {
+		t.Fatalf("unexpected recipients %v", sent)
+	}
+}
+
+func TestServiceNotifyUpdatedIgnoresMissingEvent(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := attendeeR.NewMockattendeeRepo(ctrl)
+	svc := New(mockRepo, newSettingsRepo(ctrl), attendeeR.NewMockmailer(ctrl), newRenderer(t))
+
+	mockRepo.EXPECT().
+		GetEvent(gomock.Any(), uint(5)).
+		Return(nil, "", repository.ErrEventNotFound)
+
+	if err := svc.NotifyUpdated(context.Background(), 5); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestServiceNotifyUpdatedUsesAttendeeLocale(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := attendeeR.NewMockattendeeRepo(ctrl)
+	mockMail := attendeeR.NewMockmailer(ctrl)
+	settingsRepo := attendeeR.NewMockattendeeSettingsRepo(ctrl)
+	svc := New(mockRepo, settingsRepo, mockMail, newRenderer(t))
+
+	event := &models.Event{ID: 1, UserID: 1, Event: "Planning", Date: time.Date(2025, 9, 1, 10, 0, 0, 0, time.UTC), TZ: "UTC"}
+
+	mockRepo.EXPECT().
+		GetEvent(gomock.Any(), uint(1)).
+		Return(event, "alice@example.com", nil)
+	mockRepo.EXPECT().
+		GetAttendees(gomock.Any(), uint(1)).
+		Return([]*models.Attendee{
+			{ID: 1, EventID: 1, UserID: 2, Email: "bob@example.com", Status: models.AttendeeAccepted},
+			{ID: 2, EventID: 1, Email: "carol@example.com", Status: models.AttendeeNeedsAction},
+		}, nil)
+	settingsRepo.EXPECT().
+		GetSettings(gomock.Any(), 2).
+		Return(&models.UserSettings{UserID: 2, Locale: "ru"}, nil)
+	settingsRepo.EXPECT().
+		GetSettings(gomock.Any(), 1).
+		Return(&models.UserSettings{UserID: 1}, nil)
+
+	subjects := make(map[string]string)
+	mockMail.EXPECT().
+		Send(gomock.Any()).
+		DoAndReturn(func(msg *sender.Message) error {
+			if msg.HTML == "" {
+				t.Fatalf("expected an HTML body for %v", msg.To)
+			}
+			subjects[msg.To[0]] = msg.Subject
+			return nil
+		}).
+		Times(2)
+
+	if err := svc.NotifyUpdated(context.Background(), 1); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if subjects["bob@example.com"] != "Приглашение изменено: Planning" ||
+		subjects["carol@example.com"] != "Updated invitation: Planning" {
+		t.Fatalf("unexpected subjects %v", subjects)
+	}
+}
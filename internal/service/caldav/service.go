@@ -0,0 +1,70 @@
+package caldav
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+)
+
+//go:generate mockgen -source=service.go -destination=../../mocks/mock_caldav_service.go -package=mocks
+type passwordRepo interface {
+	SavePassword(ctx context.Context, userID int, passwordHash string) error
+	DeletePassword(ctx context.Context, userID int) error
+	GetUserID(ctx context.Context, passwordHash string) (int, error)
+}
+
+// Service manages the CalDAV passwords of the users. Only a hash of each
+// password is stored, the password itself is shown once on rotation.
+type Service struct {
+	passwordRepo passwordRepo
+}
+
+func New(r passwordRepo) *Service {
+	return &Service{
+		passwordRepo: r,
+	}
+}
+
+// RotatePassword issues a new CalDAV password for the user and invalidates the
+// old one.
+func (s *Service) RotatePassword(ctx context.Context, userID int) (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("service/RotatePassword - %w", err)
+	}
+	password := base64.RawURLEncoding.EncodeToString(b)
+
+	err := s.passwordRepo.SavePassword(ctx, userID, hashPassword(password))
+	if err != nil {
+		return "", fmt.Errorf("service/RotatePassword - %w", err)
+	}
+
+	return password, nil
+}
+
+func (s *Service) RevokePassword(ctx context.Context, userID int) error {
+	err := s.passwordRepo.DeletePassword(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("service/RevokePassword - %w", err)
+	}
+
+	return nil
+}
+
+// ResolvePassword returns the owner of the CalDAV password.
+func (s *Service) ResolvePassword(ctx context.Context, password string) (int, error) {
+	userID, err := s.passwordRepo.GetUserID(ctx, hashPassword(password))
+	if err != nil {
+		return 0, fmt.Errorf("service/ResolvePassword - %w", err)
+	}
+
+	return userID, nil
+}
+
+func hashPassword(password string) string {
+	sum := sha256.Sum256([]byte(password))
+	return hex.EncodeToString(sum[:])
+}
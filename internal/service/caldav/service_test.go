@@ -0,0 +1,49 @@
+//go:build unit
+// +build unit
+
+package caldav
+
+import (
+	"context"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+
+	caldavR "github.com/avraam311/improved-calendar-service/internal/mocks"
+)
+
+func TestServiceRotateAndResolvePassword(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := caldavR.NewMockpasswordRepo(ctrl)
+	svc := New(mockRepo)
+
+	var storedHash string
+	mockRepo.EXPECT().
+		SavePassword(gomock.Any(), 1, gomock.Any()).
+		DoAndReturn(func(_ context.Context, _ int, hash string) error {
+			storedHash = hash
+			return nil
+		})
+
+	password, err := svc.RotatePassword(context.Background(), 1)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if password == "" || storedHash == password {
+		t.Fatalf("expected the password to be stored hashed, got password %q hash %q", password, storedHash)
+	}
+
+	mockRepo.EXPECT().
+		GetUserID(gomock.Any(), storedHash).
+		Return(1, nil)
+
+	userID, err := svc.ResolvePassword(context.Background(), password)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if userID != 1 {
+		t.Fatalf("expected user 1, got %d", userID)
+	}
+}
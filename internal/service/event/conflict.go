@@ -0,0 +1,133 @@
+package event
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/avraam311/improved-calendar-service/internal/models"
+)
+
+var ErrConflict = errors.New("event conflicts with other events")
+
+// conflictHorizon limits how far ahead the occurrences of a recurring event
+// are checked for conflicts.
+const conflictHorizon = 365 * 24 * time.Hour
+
+// ConflictError is returned when an event overlaps other events of the user
+// and the conflict policy rejects it.
+type ConflictError struct {
+	Conflicts []*models.Event
+}
+
+func (e *ConflictError) Error() string {
+	return ErrConflict.Error()
+}
+
+func (e *ConflictError) Is(target error) bool {
+	return target == ErrConflict
+}
+
+// checkConflicts finds the events overlapping event. Under the reject policy
+// conflicts are returned as ConflictError, otherwise as warnings. The resolved
+// policy is stored in event.ConflictPolicy for the repository.
+func (s *Service) checkConflicts(ctx context.Context, event *models.Event) ([]*models.Event, error) {
+	policy, err := s.conflictPolicy(ctx, event.UserID, event.ConflictPolicy)
+	if err != nil {
+		return nil, err
+	}
+	event.ConflictPolicy = policy
+
+	conflicts, err := s.findConflicts(ctx, event)
+	if err != nil {
+		return nil, err
+	}
+	if len(conflicts) > 0 && policy == models.ConflictReject {
+		return nil, &ConflictError{Conflicts: conflicts}
+	}
+
+	return conflicts, nil
+}
+
+// serialize runs fn, which checks event for conflicts and stores it, under the
+// lock of the user in the repository when the event is stored under the reject
+// policy. The database constraint covers exclusive single events only, the
+// lock keeps concurrent requests from both storing overlapping series after
+// each checked the events stored before.
+func (s *Service) serialize(ctx context.Context, event *models.Event, fn func(ctx context.Context) error) error {
+	policy, err := s.conflictPolicy(ctx, event.UserID, event.ConflictPolicy)
+	if err != nil {
+		return err
+	}
+	event.ConflictPolicy = policy
+	if policy != models.ConflictReject {
+		return fn(ctx)
+	}
+
+	return s.eventRepo.WithUserLock(ctx, event.UserID, fn)
+}
+
+// conflictPolicy returns the policy requested for the operation, the one the
+// user chose in the settings or the service default, in this order.
+func (s *Service) conflictPolicy(ctx context.Context, userID int, requested string) (string, error) {
+	if requested != "" {
+		return requested, nil
+	}
+
+	settings, err := s.settingsRepo.GetSettings(ctx, userID)
+	if err != nil {
+		return "", err
+	}
+	if settings.ConflictPolicy != "" {
+		return settings.ConflictPolicy, nil
+	}
+
+	return s.conflictPolicyDefault, nil
+}
+
+// findConflicts returns the events of the user overlapping event, or one of
+// its occurrences within conflictHorizon. Events without duration and the
+// event itself never conflict.
+func (s *Service) findConflicts(ctx context.Context, event *models.Event) ([]*models.Event, error) {
+	spans := []*models.Event{event}
+	if event.RRule != "" {
+		occurrences, err := expand(event, event.Date, event.Date.Add(conflictHorizon), nil)
+		if err != nil {
+			return nil, err
+		}
+		spans = occurrences
+	}
+
+	var from, to time.Time
+	for i, span := range spans {
+		if i == 0 || span.Date.Before(from) {
+			from = span.Date
+		}
+		if span.End.After(to) {
+			to = span.End
+		}
+	}
+	if !to.After(from) {
+		return nil, nil
+	}
+
+	events, err := s.GetEvents(ctx, &models.EventGet{UserID: event.UserID, DateFrom: from, DateTo: to})
+	if err != nil {
+		return nil, err
+	}
+
+	var conflicts []*models.Event
+	for _, other := range events {
+		if event.ID != 0 && other.ID == event.ID {
+			continue
+		}
+		for _, span := range spans {
+			if span.Date.Before(other.End) && other.Date.Before(span.End) {
+				conflicts = append(conflicts, other)
+				break
+			}
+		}
+	}
+
+	return conflicts, nil
+}
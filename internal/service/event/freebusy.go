@@ -0,0 +1,77 @@
+package event
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/avraam311/improved-calendar-service/internal/models"
+)
+
+// GetFreeBusy returns the busy time of every user in the window, computed from
+// their events including the occurrences of recurring series.
+func (s *Service) GetFreeBusy(ctx context.Context, query *models.FreeBusyGet) ([]*models.FreeBusy, error) {
+	seen := make(map[int]bool, len(query.UserIDs))
+	result := make([]*models.FreeBusy, 0, len(query.UserIDs))
+	for _, userID := range query.UserIDs {
+		if seen[userID] {
+			continue
+		}
+		seen[userID] = true
+
+		events, err := s.GetEvents(ctx, &models.EventGet{UserID: userID, DateFrom: query.DateFrom, DateTo: query.DateTo})
+		if err != nil {
+			return nil, fmt.Errorf("service/GetFreeBusy - %w", err)
+		}
+
+		result = append(result, &models.FreeBusy{
+			UserID: userID,
+			Busy:   busyIntervals(events, query.DateFrom.UTC(), query.DateTo.UTC()),
+		})
+	}
+
+	return result, nil
+}
+
+// busyIntervals clips the events to [from, to] and merges them. Events without
+// duration don't make anyone busy.
+func busyIntervals(events []*models.Event, from, to time.Time) []models.Interval {
+	intervals := make([]models.Interval, 0, len(events))
+	for _, e := range events {
+		start, end := e.Date, e.End
+		if start.Before(from) {
+			start = from
+		}
+		if end.After(to) {
+			end = to
+		}
+		if end.After(start) {
+			intervals = append(intervals, models.Interval{Start: start, End: end})
+		}
+	}
+
+	return mergeIntervals(intervals)
+}
+
+// mergeIntervals sorts the intervals and merges the overlapping and adjacent
+// ones.
+func mergeIntervals(intervals []models.Interval) []models.Interval {
+	sort.Slice(intervals, func(i, j int) bool {
+		return intervals[i].Start.Before(intervals[j].Start)
+	})
+
+	merged := make([]models.Interval, 0, len(intervals))
+	for _, interval := range intervals {
+		last := len(merged) - 1
+		if last >= 0 && !interval.Start.After(merged[last].End) {
+			if interval.End.After(merged[last].End) {
+				merged[last].End = interval.End
+			}
+			continue
+		}
+		merged = append(merged, interval)
+	}
+
+	return merged
+}
@@ -0,0 +1,105 @@
+package event
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/avraam311/improved-calendar-service/internal/models"
+	"github.com/avraam311/improved-calendar-service/internal/pkg/ical"
+)
+
+// ImportEvents parses a VCALENDAR and stores its VEVENTs for the user. Events
+// whose UID is already known are updated instead of duplicated. The report
+// lists the outcome for every VEVENT in file order. When the reminders of the
+// stored events cannot be scheduled, the report is returned with the error.
+func (s *Service) ImportEvents(ctx context.Context, imp *models.EventImport, r io.Reader) (*models.ImportReport, error) {
+	cal, err := ical.Decode(r)
+	if err != nil {
+		return nil, fmt.Errorf("service/ImportEvents - %w", err)
+	}
+	if cal.Name != "VCALENDAR" {
+		return nil, fmt.Errorf("service/ImportEvents - %w: expected VCALENDAR, got %s", ical.ErrInvalidCalendar, cal.Name)
+	}
+
+	report := &models.ImportReport{}
+	var (
+		events  []*models.EventCreate
+		pending []*models.ImportItem
+	)
+	seen := make(map[string]bool)
+	for _, c := range cal.Components {
+		if c.Name != "VEVENT" {
+			continue
+		}
+
+		item := &models.ImportItem{}
+		report.Items = append(report.Items, item)
+		if uid := c.Prop("UID"); uid != nil {
+			item.UID = uid.Value
+		}
+
+		if c.Prop("RECURRENCE-ID") != nil {
+			item.Status = models.ImportSkipped
+			item.Error = "overrides of single occurrences are not supported"
+			continue
+		}
+
+		event, err := ical.ParseEvent(c)
+		if err == nil {
+			err = normalizeEventCreate(event)
+		}
+		if err != nil {
+			item.Status = models.ImportFailed
+			item.Error = err.Error()
+			continue
+		}
+
+		if seen[event.UID] {
+			item.Status = models.ImportSkipped
+			item.Error = "duplicate UID in file"
+			continue
+		}
+		seen[event.UID] = true
+
+		event.UserID = imp.UserID
+		event.Mail = imp.Mail
+		events = append(events, event)
+		pending = append(pending, item)
+	}
+
+	var remindErr error
+	if len(events) > 0 {
+		stored, err := s.eventRepo.ImportEvents(ctx, events)
+		if err != nil {
+			return nil, fmt.Errorf("service/ImportEvents - %w", err)
+		}
+		var scheduled []uint
+		for i, item := range pending {
+			item.ID = stored[i].ID
+			item.Status = stored[i].Status
+			item.Error = stored[i].Error
+			if item.Status == models.ImportCreated || item.Status == models.ImportUpdated {
+				scheduled = append(scheduled, item.ID)
+			}
+		}
+		if err = s.ScheduleReminders(ctx, scheduled...); err != nil {
+			remindErr = fmt.Errorf("service/ImportEvents - %w", err)
+		}
+	}
+
+	for _, item := range report.Items {
+		switch item.Status {
+		case models.ImportCreated:
+			report.Created++
+		case models.ImportUpdated:
+			report.Updated++
+		case models.ImportSkipped:
+			report.Skipped++
+		case models.ImportFailed:
+			report.Failed++
+		}
+	}
+
+	return report, remindErr
+}
@@ -0,0 +1,171 @@
+package event
+
+import (
+	"time"
+
+	"github.com/avraam311/improved-calendar-service/internal/models"
+	"github.com/avraam311/improved-calendar-service/internal/pkg/rrule"
+)
+
+// normalizeRRule validates the rule and rewrites it into canonical form.
+func normalizeRRule(rule string) (string, error) {
+	if rule == "" {
+		return "", nil
+	}
+
+	r, err := rrule.Parse(rule)
+	if err != nil {
+		return "", err
+	}
+
+	return r.String(), nil
+}
+
+// expand returns one event per occurrence of a recurring series overlapping
+// [from, to]. Occurrences that have an override are left out, the override
+// itself is emitted by mergeOverrides.
+func expand(series *models.Event, from, to time.Time, overridden map[time.Time]bool) ([]*models.Event, error) {
+	r, err := rrule.Parse(series.RRule)
+	if err != nil {
+		return nil, err
+	}
+
+	duration := series.End.Sub(series.Date)
+	dates := r.Between(seriesStart(series), from.Add(-duration), to, series.ExDates)
+	occurrences := make([]*models.Event, 0, len(dates))
+	for _, date := range dates {
+		if overridden[date.UTC()] || !overlaps(date, date.Add(duration), from, to) {
+			continue
+		}
+
+		recurrenceID := date.UTC()
+		occurrence := *series
+		occurrence.Date = date.UTC()
+		occurrence.End = date.Add(duration).UTC()
+		occurrence.RecurrenceID = &recurrenceID
+		occurrences = append(occurrences, &occurrence)
+	}
+
+	return occurrences, nil
+}
+
+// mergeOverrides expands every series in events and replaces overridden
+// occurrences with their exception rows, which take the ID and UID of their
+// series. Cancelled occurrences and overrides moved out of the window are
+// dropped.
+func mergeOverrides(events []*models.Event, from, to time.Time) ([]*models.Event, error) {
+	uids := make(map[uint]string)
+	overridden := make(map[uint]map[time.Time]bool)
+	for _, event := range events {
+		if event.ParentID == nil {
+			uids[event.ID] = event.UID
+		}
+		if event.ParentID == nil || event.RecurrenceID == nil {
+			continue
+		}
+		if overridden[*event.ParentID] == nil {
+			overridden[*event.ParentID] = make(map[time.Time]bool)
+		}
+		overridden[*event.ParentID][event.RecurrenceID.UTC()] = true
+	}
+
+	result := make([]*models.Event, 0, len(events))
+	for _, event := range events {
+		switch {
+		case event.ParentID != nil:
+			if event.Cancelled || !overlaps(event.Date, event.End, from, to) {
+				continue
+			}
+			override := *event
+			override.ID = *event.ParentID
+			if uid := uids[*event.ParentID]; uid != "" {
+				override.UID = uid
+			}
+			result = append(result, &override)
+		case event.RRule != "":
+			occurrences, err := expand(event, from, to, overridden[event.ID])
+			if err != nil {
+				return nil, err
+			}
+			result = append(result, occurrences...)
+		default:
+			result = append(result, event)
+		}
+	}
+
+	return result, nil
+}
+
+// seriesStart returns the start of the series in its time zone, so that the
+// occurrences keep their wall-clock time when the clocks change.
+func seriesStart(series *models.Event) time.Time {
+	return series.Date.In(location(series.TZ))
+}
+
+// occurs reports whether at is an occurrence of the series, ignoring exdates.
+func occurs(series *models.Event, at time.Time) (bool, error) {
+	r, err := rrule.Parse(series.RRule)
+	if err != nil {
+		return false, err
+	}
+
+	return len(r.Between(seriesStart(series), at, at, nil)) > 0, nil
+}
+
+// splitRules returns the rule of the series cut before at and the rule for the
+// remainder of the series. A COUNT is split between the two halves.
+func splitRules(series *models.Event, at time.Time) (string, string, error) {
+	r, err := rrule.Parse(series.RRule)
+	if err != nil {
+		return "", "", err
+	}
+
+	head, tail := *r, *r
+	head.Count = 0
+	head.Until = at.Add(-time.Second).UTC()
+	head.FloatingUntil = false
+	if r.Count > 0 {
+		tail.Count = r.Count - countBefore(series, r, at)
+	}
+
+	return head.String(), tail.String(), nil
+}
+
+// tailRule adjusts a rule sent for the occurrences of the series from at on.
+// Its COUNT is taken as the length of the whole series, like the COUNT of the
+// series itself, so the occurrences before at are subtracted from it. At least
+// the occurrence at is kept.
+func tailRule(series *models.Event, at time.Time, rule string) (string, error) {
+	r, err := rrule.Parse(rule)
+	if err != nil {
+		return "", err
+	}
+	if r.Count == 0 {
+		return rule, nil
+	}
+
+	s, err := rrule.Parse(series.RRule)
+	if err != nil {
+		return "", err
+	}
+	r.Count = max(r.Count-countBefore(series, s, at), 1)
+
+	return r.String(), nil
+}
+
+// countBefore returns the number of occurrences of the series before at.
+func countBefore(series *models.Event, r *rrule.Rule, at time.Time) int {
+	return len(r.Between(seriesStart(series), series.Date, at.Add(-time.Nanosecond), nil))
+}
+
+// shiftExDates keeps the exdates from at onwards and moves them by shift.
+func shiftExDates(exdates []time.Time, at time.Time, shift time.Duration) []time.Time {
+	var shifted []time.Time
+	for _, ex := range exdates {
+		if !ex.Before(at) {
+			shifted = append(shifted, ex.Add(shift))
+		}
+	}
+
+	return shifted
+}
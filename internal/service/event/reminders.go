@@ -0,0 +1,216 @@
+package event
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/avraam311/improved-calendar-service/internal/models"
+	eventR "github.com/avraam311/improved-calendar-service/internal/repository/event"
+)
+
+// reminderHorizon bounds the search for the next occurrence of a series.
+const reminderHorizon = 366 * 24 * time.Hour
+
+// defaultReminders apply to the events of users without reminder settings.
+var defaultReminders = []models.ReminderSpec{{Offset: 60}}
+
+// reminderKey identifies a reminder of one occurrence.
+type reminderKey struct {
+	date    time.Time
+	offset  int
+	channel string
+}
+
+// ScheduleReminders replaces the pending reminders of every event. Each
+// reminder of the event is queued for the next occurrence it wasn't sent for,
+// deferred and retried reminders of occurrences that are gone are dropped.
+// Events that no longer exist or have no upcoming start are left without
+// reminders.
+func (s *Service) ScheduleReminders(ctx context.Context, IDs ...uint) error {
+	if s.reminderRepo == nil {
+		return nil
+	}
+
+	now := time.Now().UTC()
+	for _, ID := range IDs {
+		reminders, err := s.nextReminders(ctx, ID, now)
+		if err != nil {
+			return fmt.Errorf("service/ScheduleReminders - %w", err)
+		}
+		if err = s.reminderRepo.SaveReminders(ctx, ID, now, reminders); err != nil {
+			return fmt.Errorf("service/ScheduleReminders - %w", err)
+		}
+	}
+
+	return nil
+}
+
+// RebuildReminders schedules the reminders of the events that have none
+// pending, e.g. the ones stored before the reminder queue existed.
+func (s *Service) RebuildReminders(ctx context.Context) error {
+	if s.reminderRepo == nil {
+		return nil
+	}
+
+	IDs, err := s.reminderRepo.GetUnscheduledEvents(ctx, time.Now().UTC())
+	if err != nil {
+		return fmt.Errorf("service/RebuildReminders - %w", err)
+	}
+	if err = s.ScheduleReminders(ctx, IDs...); err != nil {
+		return fmt.Errorf("service/RebuildReminders - %w", err)
+	}
+
+	return nil
+}
+
+func (s *Service) nextReminders(ctx context.Context, ID uint, now time.Time) ([]*models.Reminder, error) {
+	event, err := s.eventRepo.GetEvent(ctx, ID)
+	if errors.Is(err, eventR.ErrEventNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	specs, err := s.reminderSpecs(ctx, event)
+	if err != nil || len(specs) == 0 {
+		return nil, err
+	}
+
+	occurrences := []*models.Event{event}
+	if event.RRule != "" {
+		occurrences, err = s.upcomingOccurrences(ctx, event, now)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	sent, err := s.reminderRepo.GetSent(ctx, ID, now)
+	if err != nil {
+		return nil, err
+	}
+	done := make(map[reminderKey]bool, len(sent))
+	for _, reminder := range sent {
+		done[reminderKey{reminder.Date.UTC(), reminder.Offset, reminder.Channel}] = true
+	}
+
+	var reminders []*models.Reminder
+	for _, spec := range specs {
+		for _, occurrence := range occurrences {
+			date := occurrence.Date.UTC()
+			if date.Before(now) || done[reminderKey{date, spec.Offset, spec.Channel}] ||
+				superseded(specs, spec, date, now) {
+				continue
+			}
+
+			reminders = append(reminders, &models.Reminder{
+				EventID: event.ID,
+				UserID:  event.UserID,
+				Event:   occurrence.Event,
+				Date:    date,
+				Mail:    event.Mail,
+				Offset:  spec.Offset,
+				FireAt:  date.Add(-time.Duration(spec.Offset) * time.Minute),
+				Channel: spec.Channel,
+				Urgent:  event.Urgent,
+			})
+			break
+		}
+	}
+
+	return reminders, nil
+}
+
+// reminderSpecs returns the reminders of the event, falling back to the
+// settings of its owner and then to the service defaults. Reminders without a
+// channel go to the default channel of the owner, email unless set.
+// Duplicates are dropped.
+func (s *Service) reminderSpecs(ctx context.Context, event *models.Event) ([]models.ReminderSpec, error) {
+	settings, err := s.settingsRepo.GetSettings(ctx, event.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	specs := event.Reminders
+	if specs == nil {
+		specs = settings.Reminders
+	}
+	if specs == nil {
+		specs = defaultReminders
+	}
+	channel := models.ChannelEmail
+	if settings.Channels != nil && settings.Channels.Default != "" {
+		channel = settings.Channels.Default
+	}
+
+	seen := make(map[models.ReminderSpec]bool, len(specs))
+	unique := make([]models.ReminderSpec, 0, len(specs))
+	for _, spec := range specs {
+		if spec.Channel == "" {
+			spec.Channel = channel
+		}
+		if !seen[spec] {
+			seen[spec] = true
+			unique = append(unique, spec)
+		}
+	}
+
+	return unique, nil
+}
+
+// superseded reports whether the reminder for the occurrence at date is
+// overdue and a later reminder of the same occurrence on the same channel is
+// overdue as well, so only the latest one is sent for an event created at
+// short notice.
+func superseded(specs []models.ReminderSpec, spec models.ReminderSpec, date, now time.Time) bool {
+	fireAt := date.Add(-time.Duration(spec.Offset) * time.Minute)
+	if fireAt.After(now) {
+		return false
+	}
+
+	for _, other := range specs {
+		if other.Channel == spec.Channel && other.Offset < spec.Offset &&
+			!date.Add(-time.Duration(other.Offset)*time.Minute).After(now) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// upcomingOccurrences returns the occurrences of the series starting at or
+// after from in start order, taking modified and cancelled occurrences into
+// account.
+func (s *Service) upcomingOccurrences(ctx context.Context, series *models.Event, from time.Time) ([]*models.Event, error) {
+	to := from.Add(reminderHorizon)
+	events, err := s.eventRepo.GetEvents(ctx, &models.EventGet{UserID: series.UserID, DateFrom: from, DateTo: to})
+	if err != nil {
+		return nil, err
+	}
+
+	var own []*models.Event
+	for _, event := range events {
+		if event.ID == series.ID || (event.ParentID != nil && *event.ParentID == series.ID) {
+			own = append(own, event)
+		}
+	}
+	occurrences, err := mergeOverrides(own, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	upcoming := occurrences[:0]
+	for _, occurrence := range occurrences {
+		if !occurrence.Date.Before(from) {
+			upcoming = append(upcoming, occurrence)
+		}
+	}
+	sort.SliceStable(upcoming, func(i, j int) bool {
+		return upcoming[i].Date.Before(upcoming[j].Date)
+	})
+
+	return upcoming, nil
+}
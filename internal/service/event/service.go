@@ -2,61 +2,556 @@ package event
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"sort"
+	"time"
 
 	"github.com/avraam311/improved-calendar-service/internal/models"
+	"github.com/avraam311/improved-calendar-service/internal/pkg/ical"
+)
+
+var (
+	ErrNotRecurring         = errors.New("event is not recurring")
+	ErrRecurrenceIDRequired = errors.New("recurrence_id is required for this scope")
+	ErrOccurrenceNotFound   = errors.New("occurrence not found")
+	ErrInvalidEnd           = errors.New("event end must not be before its start")
+	ErrInvalidTimeZone      = errors.New("unknown time zone")
 )
 
 //go:generate mockgen -source=service.go -destination=../../mocks/mock_service.go -package=mocks
 type eventRepo interface {
 	CreateEvent(ctx context.Context, event *models.EventCreate) (uint, error)
+	CreateSeries(ctx context.Context, series *models.EventCreate, overrides []*models.Event) (uint, error)
 	UpdateEvent(ctx context.Context, event *models.Event) (uint, error)
+	WithUserLock(ctx context.Context, userID int, fn func(ctx context.Context) error) error
+	ReplaceSeries(ctx context.Context, series *models.Event, overrides []*models.Event) (uint, error)
 	DeleteEvent(ctx context.Context, ID uint) (uint, error)
+	GetEvent(ctx context.Context, ID uint) (*models.Event, error)
 	GetEvents(ctx context.Context, eventGet *models.EventGet) ([]*models.Event, error)
+	SaveOverride(ctx context.Context, override *models.Event) (uint, error)
+	SplitSeries(ctx context.Context, ID uint, rule string, from time.Time, next *models.Event) (uint, error)
+	ImportEvents(ctx context.Context, events []*models.EventCreate) ([]*models.ImportItem, error)
+	GetUserEvents(ctx context.Context, userID int) ([]*models.Event, error)
+	GetEventByUID(ctx context.Context, userID int, uid string) ([]*models.Event, error)
 }
 
+type settingsRepo interface {
+	GetSettings(ctx context.Context, userID int) (*models.UserSettings, error)
+}
+
+type reminderRepo interface {
+	SaveReminders(ctx context.Context, eventID uint, since time.Time, reminders []*models.Reminder) error
+	GetSent(ctx context.Context, eventID uint, since time.Time) ([]*models.Reminder, error)
+	GetUnscheduledEvents(ctx context.Context, now time.Time) ([]uint, error)
+}
+
+// attendeeNotifier mails updates and cancellations to the attendees.
+type attendeeNotifier interface {
+	NotifyUpdated(ctx context.Context, eventID uint) error
+	PrepareCancel(ctx context.Context, eventID uint) (func() error, error)
+}
+
+// Service manages the events of users. Every change of an event reschedules
+// its reminder in the reminder queue, a nil reminder repository disables
+// reminders.
 type Service struct {
-	eventRepo eventRepo
+	eventRepo             eventRepo
+	settingsRepo          settingsRepo
+	reminderRepo          reminderRepo
+	attendees             attendeeNotifier
+	conflictPolicyDefault string
 }
 
-func New(r eventRepo) *Service {
+func New(r eventRepo, sr settingsRepo, rr reminderRepo, n attendeeNotifier, conflictPolicy string) *Service {
 	return &Service{
-		eventRepo: r,
+		eventRepo:             r,
+		settingsRepo:          sr,
+		reminderRepo:          rr,
+		attendees:             n,
+		conflictPolicyDefault: conflictPolicy,
 	}
 }
 
-func (s *Service) CreateEvent(ctx context.Context, event *models.EventCreate) (uint, error) {
-	ID, err := s.eventRepo.CreateEvent(ctx, event)
+// CreateEvent stores the event and returns the events it overlaps, unless the
+// conflict policy rejects overlapping events.
+func (s *Service) CreateEvent(ctx context.Context, event *models.EventCreate) (uint, []*models.Event, error) {
+	err := normalizeEventCreate(event)
+	if err != nil {
+		return 0, nil, fmt.Errorf("service/CreateEvent - %w", err)
+	}
+	if event.UID == "" {
+		event.UID = ical.NewUID()
+	}
+
+	candidate := &models.Event{
+		UserID:         event.UserID,
+		Date:           event.Date,
+		End:            event.End,
+		TZ:             event.TZ,
+		RRule:          event.RRule,
+		ExDates:        event.ExDates,
+		ConflictPolicy: event.ConflictPolicy,
+	}
+	var (
+		ID        uint
+		conflicts []*models.Event
+	)
+	err = s.serialize(ctx, candidate, func(ctx context.Context) error {
+		var err error
+		if conflicts, err = s.checkConflicts(ctx, candidate); err != nil {
+			return err
+		}
+		event.ConflictPolicy = candidate.ConflictPolicy
+
+		ID, err = s.eventRepo.CreateEvent(ctx, event)
+		return err
+	})
 	if err != nil {
-		return 0, fmt.Errorf("service/CreateEvent - %w", err)
+		return 0, nil, fmt.Errorf("service/CreateEvent - %w", err)
 	}
 
-	return ID, nil
+	if err = s.ScheduleReminders(ctx, ID); err != nil {
+		return ID, conflicts, fmt.Errorf("service/CreateEvent - %w", err)
+	}
+
+	return ID, conflicts, nil
 }
 
-func (s *Service) UpdateEvent(ctx context.Context, event *models.Event) (uint, error) {
-	ID, err := s.eventRepo.UpdateEvent(ctx, event)
+// CreateSeries is CreateEvent for a series sent together with its modified
+// occurrences. The series and the overrides are stored in one transaction.
+func (s *Service) CreateSeries(ctx context.Context, event *models.EventCreate, overrides []*models.Event) (uint, []*models.Event, error) {
+	err := normalizeEventCreate(event)
 	if err != nil {
-		return 0, fmt.Errorf("service/UpdateEvent - %w", err)
+		return 0, nil, fmt.Errorf("service/CreateSeries - %w", err)
+	}
+	if event.UID == "" {
+		event.UID = ical.NewUID()
 	}
 
-	return ID, nil
+	series := &models.Event{
+		UserID:         event.UserID,
+		Date:           event.Date,
+		End:            event.End,
+		TZ:             event.TZ,
+		RRule:          event.RRule,
+		ExDates:        event.ExDates,
+		ConflictPolicy: event.ConflictPolicy,
+	}
+	var (
+		ID        uint
+		conflicts []*models.Event
+	)
+	err = s.serialize(ctx, series, func(ctx context.Context) error {
+		var err error
+		if conflicts, err = s.checkConflicts(ctx, series); err != nil {
+			return err
+		}
+		event.ConflictPolicy = series.ConflictPolicy
+
+		overrideConflicts, err := s.prepareOverrides(ctx, series, overrides)
+		if err != nil {
+			return err
+		}
+		conflicts = append(conflicts, overrideConflicts...)
+
+		ID, err = s.eventRepo.CreateSeries(ctx, event, overrides)
+		return err
+	})
+	if err != nil {
+		return 0, nil, fmt.Errorf("service/CreateSeries - %w", err)
+	}
+
+	if err = s.ScheduleReminders(ctx, ID); err != nil {
+		return ID, conflicts, fmt.Errorf("service/CreateSeries - %w", err)
+	}
+
+	return ID, conflicts, nil
+}
+
+// ReplaceSeries updates the whole series like UpdateEvent and replaces all of
+// its overrides with the given ones in one transaction, so overrides missing
+// from the new version are dropped.
+func (s *Service) ReplaceSeries(ctx context.Context, event *models.Event, overrides []*models.Event) (uint, []*models.Event, error) {
+	err := normalizeEvent(event, "")
+	if err != nil {
+		return 0, nil, fmt.Errorf("service/ReplaceSeries - %w", err)
+	}
+	var (
+		ID        uint
+		conflicts []*models.Event
+	)
+	err = s.serialize(ctx, event, func(ctx context.Context) error {
+		var err error
+		if conflicts, err = s.checkConflicts(ctx, event); err != nil {
+			return err
+		}
+
+		overrideConflicts, err := s.prepareOverrides(ctx, event, overrides)
+		if err != nil {
+			return err
+		}
+		conflicts = append(conflicts, overrideConflicts...)
+
+		ID, err = s.eventRepo.ReplaceSeries(ctx, event, overrides)
+		return err
+	})
+	if err != nil {
+		return 0, nil, fmt.Errorf("service/ReplaceSeries - %w", err)
+	}
+
+	if err = s.ScheduleReminders(ctx, ID); err != nil {
+		return ID, conflicts, fmt.Errorf("service/ReplaceSeries - %w", err)
+	}
+
+	if err = s.attendees.NotifyUpdated(ctx, ID); err != nil {
+		return ID, conflicts, fmt.Errorf("service/ReplaceSeries - %w", err)
+	}
+
+	return ID, conflicts, nil
+}
+
+// prepareOverrides checks that every override replaces an occurrence of the
+// series, normalizes it and returns the events it overlaps.
+func (s *Service) prepareOverrides(ctx context.Context, series *models.Event, overrides []*models.Event) ([]*models.Event, error) {
+	var conflicts []*models.Event
+	for _, override := range overrides {
+		if series.RRule == "" {
+			return nil, ErrNotRecurring
+		}
+		override.RecurrenceID = utcTime(override.RecurrenceID)
+		if override.RecurrenceID == nil {
+			return nil, ErrRecurrenceIDRequired
+		}
+		ok, err := occurs(series, *override.RecurrenceID)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			return nil, ErrOccurrenceNotFound
+		}
+		if err = normalizeEvent(override, series.TZ); err != nil {
+			return nil, err
+		}
+
+		candidate := *override
+		candidate.ID = series.ID
+		candidate.UserID = series.UserID
+		candidate.RRule = ""
+		candidate.ConflictPolicy = series.ConflictPolicy
+		overlapping, err := s.checkConflicts(ctx, &candidate)
+		if err != nil {
+			return nil, err
+		}
+		conflicts = append(conflicts, overlapping...)
+	}
+
+	return conflicts, nil
+}
+
+// UpdateEvent updates the whole event by default. For recurring series the
+// scope selects a single occurrence or the occurrence and all following ones.
+// Like CreateEvent it returns the events the updated event overlaps. The
+// attendees are notified about the update, a failed notification is returned
+// along with the ID as the update is already stored.
+func (s *Service) UpdateEvent(ctx context.Context, event *models.Event) (uint, []*models.Event, error) {
+	event.RecurrenceID = utcTime(event.RecurrenceID)
+
+	var (
+		ID        uint
+		conflicts []*models.Event
+		err       error
+	)
+	switch event.Scope {
+	case models.ScopeThis:
+		ID, conflicts, err = s.updateOccurrence(ctx, event)
+	case models.ScopeFollowing:
+		ID, conflicts, err = s.updateFollowing(ctx, event)
+	default:
+		if err = normalizeEvent(event, ""); err == nil {
+			err = s.serialize(ctx, event, func(ctx context.Context) error {
+				var err error
+				if conflicts, err = s.checkConflicts(ctx, event); err != nil {
+					return err
+				}
+
+				ID, err = s.eventRepo.UpdateEvent(ctx, event)
+				return err
+			})
+		}
+	}
+	if err != nil {
+		return 0, nil, fmt.Errorf("service/UpdateEvent - %w", err)
+	}
+
+	scheduled := []uint{event.ID}
+	if ID != event.ID {
+		scheduled = append(scheduled, ID)
+	}
+	if err = s.ScheduleReminders(ctx, scheduled...); err != nil {
+		return ID, conflicts, fmt.Errorf("service/UpdateEvent - %w", err)
+	}
+
+	if err = s.attendees.NotifyUpdated(ctx, event.ID); err != nil {
+		return ID, conflicts, fmt.Errorf("service/UpdateEvent - %w", err)
+	}
+
+	return ID, conflicts, nil
+}
+
+func (s *Service) updateOccurrence(ctx context.Context, event *models.Event) (uint, []*models.Event, error) {
+	series, err := s.getOccurrenceSeries(ctx, event.ID, event.RecurrenceID)
+	if err != nil {
+		return 0, nil, err
+	}
+	if err = normalizeEvent(event, series.TZ); err != nil {
+		return 0, nil, err
+	}
+	candidate := *event
+	candidate.UserID = series.UserID
+	candidate.RRule = ""
+
+	override := &models.Event{
+		Event:        event.Event,
+		Date:         event.Date,
+		End:          event.End,
+		AllDay:       event.AllDay,
+		ParentID:     &series.ID,
+		RecurrenceID: event.RecurrenceID,
+	}
+	var conflicts []*models.Event
+	err = s.serialize(ctx, &candidate, func(ctx context.Context) error {
+		var err error
+		if conflicts, err = s.checkConflicts(ctx, &candidate); err != nil {
+			return err
+		}
+
+		_, err = s.eventRepo.SaveOverride(ctx, override)
+		return err
+	})
+	if err != nil {
+		return 0, nil, err
+	}
+
+	return series.ID, conflicts, nil
 }
 
-func (s *Service) DeleteEvent(ctx context.Context, ID uint) (uint, error) {
-	ID, err := s.eventRepo.DeleteEvent(ctx, ID)
+func (s *Service) updateFollowing(ctx context.Context, event *models.Event) (uint, []*models.Event, error) {
+	series, err := s.getOccurrenceSeries(ctx, event.ID, event.RecurrenceID)
+	if err != nil {
+		return 0, nil, err
+	}
+	if err = normalizeEvent(event, series.TZ); err != nil {
+		return 0, nil, err
+	}
+
+	at := *event.RecurrenceID
+	head, tail, err := splitRules(series, at)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	next := &models.Event{
+		Event:     event.Event,
+		Date:      event.Date,
+		End:       event.End,
+		AllDay:    event.AllDay,
+		TZ:        event.TZ,
+		RRule:     event.RRule,
+		ExDates:   event.ExDates,
+		Reminders: event.Reminders,
+		Urgent:    event.Urgent,
+	}
+	if next.RRule == "" {
+		next.RRule = tail
+	} else if next.RRule, err = tailRule(series, at, next.RRule); err != nil {
+		return 0, nil, err
+	}
+	if next.Reminders == nil {
+		next.Reminders = series.Reminders
+	}
+	if next.ExDates == nil {
+		next.ExDates = shiftExDates(series.ExDates, at, event.Date.Sub(at))
+	}
+
+	next.UserID = series.UserID
+	candidate := *next
+	candidate.ID = series.ID
+	candidate.ConflictPolicy = event.ConflictPolicy
+
+	var (
+		ID        uint
+		conflicts []*models.Event
+	)
+	err = s.serialize(ctx, &candidate, func(ctx context.Context) error {
+		var err error
+		if conflicts, err = s.checkConflicts(ctx, &candidate); err != nil {
+			return err
+		}
+		next.ConflictPolicy = candidate.ConflictPolicy
+
+		// From the first occurrence on the whole series changes, its overrides
+		// belong to the old occurrences and are dropped as SplitSeries does.
+		if !at.After(series.Date) {
+			next.ID = series.ID
+			ID, err = s.eventRepo.ReplaceSeries(ctx, next, nil)
+			return err
+		}
+
+		ID, err = s.eventRepo.SplitSeries(ctx, series.ID, head, at, next)
+		return err
+	})
+
+	return ID, conflicts, err
+}
+
+// DeleteEvent deletes the whole event by default. For recurring series the
+// scope cancels a single occurrence or ends the series before the occurrence.
+// The attendees get a cancellation of a deleted event and an update of a
+// changed series, a failed notification is returned along with the ID.
+func (s *Service) DeleteEvent(ctx context.Context, event *models.EventDelete) (uint, error) {
+	event.RecurrenceID = utcTime(event.RecurrenceID)
+
+	var (
+		ID        uint
+		err       error
+		notifyErr error
+	)
+	switch event.Scope {
+	case models.ScopeThis:
+		if ID, err = s.deleteOccurrence(ctx, event); err == nil {
+			notifyErr = s.attendees.NotifyUpdated(ctx, event.ID)
+		}
+	case models.ScopeFollowing:
+		if ID, err = s.deleteFollowing(ctx, event); err == nil {
+			notifyErr = s.attendees.NotifyUpdated(ctx, event.ID)
+		}
+	default:
+		var cancel func() error
+		cancel, notifyErr = s.attendees.PrepareCancel(ctx, event.ID)
+		if ID, err = s.eventRepo.DeleteEvent(ctx, event.ID); err == nil && notifyErr == nil {
+			notifyErr = cancel()
+		}
+	}
 	if err != nil {
 		return 0, fmt.Errorf("service/DeleteEvent - %w", err)
 	}
+	if err = s.ScheduleReminders(ctx, event.ID); err != nil {
+		return ID, fmt.Errorf("service/DeleteEvent - %w", err)
+	}
+	if notifyErr != nil {
+		return ID, fmt.Errorf("service/DeleteEvent - %w", notifyErr)
+	}
 
 	return ID, nil
 }
 
+func (s *Service) deleteOccurrence(ctx context.Context, event *models.EventDelete) (uint, error) {
+	series, err := s.getOccurrenceSeries(ctx, event.ID, event.RecurrenceID)
+	if err != nil {
+		return 0, err
+	}
+
+	override := &models.Event{
+		Event:        series.Event,
+		Date:         *event.RecurrenceID,
+		End:          event.RecurrenceID.Add(series.End.Sub(series.Date)),
+		AllDay:       series.AllDay,
+		ParentID:     &series.ID,
+		RecurrenceID: event.RecurrenceID,
+		Cancelled:    true,
+	}
+	if _, err = s.eventRepo.SaveOverride(ctx, override); err != nil {
+		return 0, err
+	}
+
+	return series.ID, nil
+}
+
+func (s *Service) deleteFollowing(ctx context.Context, event *models.EventDelete) (uint, error) {
+	series, err := s.getOccurrenceSeries(ctx, event.ID, event.RecurrenceID)
+	if err != nil {
+		return 0, err
+	}
+
+	at := *event.RecurrenceID
+	if !at.After(series.Date) {
+		return s.eventRepo.DeleteEvent(ctx, series.ID)
+	}
+
+	head, _, err := splitRules(series, at)
+	if err != nil {
+		return 0, err
+	}
+
+	return s.eventRepo.SplitSeries(ctx, series.ID, head, at, nil)
+}
+
+// getOccurrenceSeries loads the series with the given ID and checks that
+// recurrenceID is one of its occurrences.
+func (s *Service) getOccurrenceSeries(ctx context.Context, ID uint, recurrenceID *time.Time) (*models.Event, error) {
+	if recurrenceID == nil {
+		return nil, ErrRecurrenceIDRequired
+	}
+
+	series, err := s.eventRepo.GetEvent(ctx, ID)
+	if err != nil {
+		return nil, err
+	}
+	if series.RRule == "" {
+		return nil, ErrNotRecurring
+	}
+
+	ok, err := occurs(series, *recurrenceID)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, ErrOccurrenceNotFound
+	}
+
+	return series, nil
+}
+
 func (s *Service) GetEvents(ctx context.Context, eventGet *models.EventGet) ([]*models.Event, error) {
-	events, err := s.eventRepo.GetEvents(ctx, eventGet)
+	query := *eventGet
+	query.DateFrom = eventGet.DateFrom.UTC()
+	query.DateTo = eventGet.DateTo.UTC()
+
+	events, err := s.eventRepo.GetEvents(ctx, &query)
+	if err != nil {
+		return nil, fmt.Errorf("service/GetEvents - %w", err)
+	}
+
+	result, err := mergeOverrides(events, eventGet.DateFrom, eventGet.DateTo)
 	if err != nil {
 		return nil, fmt.Errorf("service/GetEvents - %w", err)
 	}
 
+	sort.SliceStable(result, func(i, j int) bool {
+		return result[i].Date.Before(result[j].Date)
+	})
+
+	return result, nil
+}
+
+// GetUserEvents returns the stored events of the user without expanding
+// recurrences: series, single events and overrides of single occurrences.
+func (s *Service) GetUserEvents(ctx context.Context, userID int) ([]*models.Event, error) {
+	events, err := s.eventRepo.GetUserEvents(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("service/GetUserEvents - %w", err)
+	}
+
+	return events, nil
+}
+
+// GetEventByUID returns the event with the given UID followed by its overrides.
+func (s *Service) GetEventByUID(ctx context.Context, userID int, uid string) ([]*models.Event, error) {
+	events, err := s.eventRepo.GetEventByUID(ctx, userID, uid)
+	if err != nil {
+		return nil, fmt.Errorf("service/GetEventByUID - %w", err)
+	}
+
 	return events, nil
 }
@@ -5,6 +5,8 @@ package event
 
 import (
 	"context"
+	"errors"
+	"strings"
 	"testing"
 	"time"
 
@@ -12,6 +14,7 @@ import (
 
 	eventR "github.com/avraam311/improved-calendar-service/internal/mocks"
 	"github.com/avraam311/improved-calendar-service/internal/models"
+	repository "github.com/avraam311/improved-calendar-service/internal/repository/event"
 )
 
 func TestServiceCreateEvent(t *testing.T) {
@@ -19,7 +22,7 @@ func TestServiceCreateEvent(t *testing.T) {
 	defer ctrl.Finish()
 
 	mockRepo := eventR.NewMockeventRepo(ctrl)
-	svc := New(mockRepo)
+	svc := New(mockRepo, newSettingsRepo(ctrl), nil, newNotifier(ctrl), models.ConflictWarn)
 
 	ev := &models.EventCreate{
 		UserID: 1,
@@ -32,7 +35,7 @@ func TestServiceCreateEvent(t *testing.T) {
 		CreateEvent(gomock.Any(), ev).
 		Return(eventID, nil)
 
-	id, err := svc.CreateEvent(context.Background(), ev)
+	id, _, err := svc.CreateEvent(context.Background(), ev)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -46,7 +49,7 @@ func TestServiceUpdateEvent(t *testing.T) {
 	defer ctrl.Finish()
 
 	mockRepo := eventR.NewMockeventRepo(ctrl)
-	svc := New(mockRepo)
+	svc := New(mockRepo, newSettingsRepo(ctrl), nil, newNotifier(ctrl), models.ConflictWarn)
 
 	eventID := uint(1)
 	ev := &models.Event{
@@ -60,7 +63,7 @@ func TestServiceUpdateEvent(t *testing.T) {
 		UpdateEvent(gomock.Any(), ev).
 		Return(eventID, nil)
 
-	id, err := svc.UpdateEvent(context.Background(), ev)
+	id, _, err := svc.UpdateEvent(context.Background(), ev)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -74,7 +77,7 @@ func TestServiceDeleteEvent(t *testing.T) {
 	defer ctrl.Finish()
 
 	mockRepo := eventR.NewMockeventRepo(ctrl)
-	svc := New(mockRepo)
+	svc := New(mockRepo, newSettingsRepo(ctrl), nil, newNotifier(ctrl), models.ConflictWarn)
 
 	eventID := uint(1)
 
@@ -82,7 +85,7 @@ func TestServiceDeleteEvent(t *testing.T) {
 		DeleteEvent(gomock.Any(), eventID).
 		Return(eventID, nil)
 
-	id, err := svc.DeleteEvent(context.Background(), eventID)
+	id, err := svc.DeleteEvent(context.Background(), &models.EventDelete{ID: eventID})
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -91,12 +94,41 @@ func TestServiceDeleteEvent(t *testing.T) {
 	}
 }
 
+func TestServiceDeleteEventCancelsForAttendees(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := eventR.NewMockeventRepo(ctrl)
+	notifier := eventR.NewMockattendeeNotifier(ctrl)
+	svc := New(mockRepo, newSettingsRepo(ctrl), nil, notifier, models.ConflictWarn)
+
+	eventID := uint(1)
+	sendErr := errors.New("smtp unavailable")
+
+	gomock.InOrder(
+		notifier.EXPECT().
+			PrepareCancel(gomock.Any(), eventID).
+			Return(func() error { return sendErr }, nil),
+		mockRepo.EXPECT().
+			DeleteEvent(gomock.Any(), eventID).
+			Return(eventID, nil),
+	)
+
+	id, err := svc.DeleteEvent(context.Background(), &models.EventDelete{ID: eventID})
+	if !errors.Is(err, sendErr) {
+		t.Fatalf("expected the send error, got %v", err)
+	}
+	if id != eventID {
+		t.Fatalf("expected id %v, got %v", eventID, id)
+	}
+}
+
 func TestServiceGetEvents(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 
 	mockRepo := eventR.NewMockeventRepo(ctrl)
-	svc := New(mockRepo)
+	svc := New(mockRepo, newSettingsRepo(ctrl), nil, newNotifier(ctrl), models.ConflictWarn)
 
 	mockEvents := []*models.Event{
 		{ID: uint(1), UserID: 1, Event: "Event Week", Date: time.Now()},
@@ -123,3 +155,1013 @@ func TestServiceGetEvents(t *testing.T) {
 		t.Fatalf("unexpected error: %v", err)
 	}
 }
+
+func TestServiceGetEventsExpandsRecurrence(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := eventR.NewMockeventRepo(ctrl)
+	svc := New(mockRepo, newSettingsRepo(ctrl), nil, newNotifier(ctrl), models.ConflictWarn)
+
+	from := time.Date(2025, 9, 1, 0, 0, 0, 0, time.UTC)
+	getData := &models.EventGet{UserID: 1, DateFrom: from, DateTo: from.AddDate(0, 0, 7)}
+
+	mockEvents := []*models.Event{
+		{ID: uint(1), UserID: 1, Event: "Standup", Date: time.Date(2025, 8, 4, 9, 0, 0, 0, time.UTC), RRule: "FREQ=WEEKLY;BYDAY=MO,TH"},
+		{ID: uint(2), UserID: 1, Event: "Review", Date: time.Date(2025, 9, 2, 12, 0, 0, 0, time.UTC)},
+	}
+
+	mockRepo.EXPECT().
+		GetEvents(gomock.Any(), getData).
+		Return(mockEvents, nil)
+
+	events, err := svc.GetEvents(context.Background(), getData)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(events) != 3 {
+		t.Fatalf("expected 3 events, got %d", len(events))
+	}
+
+	want := []time.Time{
+		time.Date(2025, 9, 1, 9, 0, 0, 0, time.UTC),
+		time.Date(2025, 9, 2, 12, 0, 0, 0, time.UTC),
+		time.Date(2025, 9, 4, 9, 0, 0, 0, time.UTC),
+	}
+	for i, ev := range events {
+		if !ev.Date.Equal(want[i]) {
+			t.Fatalf("event %d: expected date %v, got %v", i, want[i], ev.Date)
+		}
+	}
+}
+
+func TestServiceGetEventsReturnsOverlappingOccurrences(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := eventR.NewMockeventRepo(ctrl)
+	svc := New(mockRepo, newSettingsRepo(ctrl), nil, newNotifier(ctrl), models.ConflictWarn)
+
+	from := time.Date(2025, 9, 2, 0, 0, 0, 0, time.UTC)
+	getData := &models.EventGet{UserID: 1, DateFrom: from, DateTo: from.Add(24 * time.Hour)}
+
+	start := time.Date(2025, 8, 30, 23, 0, 0, 0, time.UTC)
+	mockEvents := []*models.Event{
+		{ID: uint(1), UserID: 1, Event: "Night shift", Date: start, End: start.Add(8 * time.Hour), RRule: "FREQ=DAILY"},
+	}
+
+	mockRepo.EXPECT().
+		GetEvents(gomock.Any(), getData).
+		Return(mockEvents, nil)
+
+	events, err := svc.GetEvents(context.Background(), getData)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(events) != 2 {
+		t.Fatalf("expected 2 events, got %d", len(events))
+	}
+	if want := time.Date(2025, 9, 1, 23, 0, 0, 0, time.UTC); !events[0].Date.Equal(want) {
+		t.Fatalf("expected first occurrence at %v, got %v", want, events[0].Date)
+	}
+	if want := time.Date(2025, 9, 3, 7, 0, 0, 0, time.UTC); !events[1].End.Equal(want) {
+		t.Fatalf("expected last occurrence to end at %v, got %v", want, events[1].End)
+	}
+}
+
+func TestServiceGetEventsKeepsLocalTimeAcrossDST(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := eventR.NewMockeventRepo(ctrl)
+	svc := New(mockRepo, newSettingsRepo(ctrl), nil, newNotifier(ctrl), models.ConflictWarn)
+
+	from := time.Date(2025, 10, 20, 0, 0, 0, 0, time.UTC)
+	getData := &models.EventGet{UserID: 1, DateFrom: from, DateTo: from.AddDate(0, 0, 14)}
+
+	// 09:00 in Berlin, summer time.
+	start := time.Date(2025, 10, 6, 7, 0, 0, 0, time.UTC)
+	mockEvents := []*models.Event{
+		{ID: uint(1), UserID: 1, Event: "Sync", Date: start, End: start.Add(time.Hour), TZ: "Europe/Berlin", RRule: "FREQ=WEEKLY"},
+	}
+
+	mockRepo.EXPECT().
+		GetEvents(gomock.Any(), getData).
+		Return(mockEvents, nil)
+
+	events, err := svc.GetEvents(context.Background(), getData)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := []time.Time{
+		time.Date(2025, 10, 20, 7, 0, 0, 0, time.UTC),
+		time.Date(2025, 10, 27, 8, 0, 0, 0, time.UTC),
+	}
+	if len(events) != len(want) {
+		t.Fatalf("expected %d events, got %d", len(want), len(events))
+	}
+	for i, ev := range events {
+		if !ev.Date.Equal(want[i]) {
+			t.Fatalf("event %d: expected date %v, got %v", i, want[i], ev.Date)
+		}
+	}
+}
+
+func TestServiceCreateEventInvalidTimeZone(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := eventR.NewMockeventRepo(ctrl)
+	svc := New(mockRepo, newSettingsRepo(ctrl), nil, newNotifier(ctrl), models.ConflictWarn)
+
+	ev := &models.EventCreate{UserID: 1, Event: "Meeting", Date: time.Now(), TZ: "Mars/Olympus"}
+
+	_, _, err := svc.CreateEvent(context.Background(), ev)
+	if !errors.Is(err, ErrInvalidTimeZone) {
+		t.Fatalf("expected ErrInvalidTimeZone, got %v", err)
+	}
+}
+
+func TestServiceCreateEventAllDay(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := eventR.NewMockeventRepo(ctrl)
+	svc := New(mockRepo, newSettingsRepo(ctrl), nil, newNotifier(ctrl), models.ConflictWarn)
+
+	ev := &models.EventCreate{
+		UserID: 1,
+		Event:  "Holiday",
+		Date:   time.Date(2025, 12, 31, 15, 0, 0, 0, time.UTC),
+		AllDay: true,
+		UID:    "holiday@example.com",
+	}
+
+	mockRepo.EXPECT().
+		GetEvents(gomock.Any(), gomock.Any()).
+		Return(nil, nil)
+	mockRepo.EXPECT().
+		CreateEvent(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, e *models.EventCreate) (uint, error) {
+			if !e.Date.Equal(time.Date(2025, 12, 31, 0, 0, 0, 0, time.UTC)) || !e.End.Equal(time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)) {
+				t.Fatalf("unexpected span %v - %v", e.Date, e.End)
+			}
+			return uint(1), nil
+		})
+
+	if _, _, err := svc.CreateEvent(context.Background(), ev); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestServiceCreateEventEndBeforeStart(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := eventR.NewMockeventRepo(ctrl)
+	svc := New(mockRepo, newSettingsRepo(ctrl), nil, newNotifier(ctrl), models.ConflictWarn)
+
+	date := time.Date(2025, 9, 1, 10, 0, 0, 0, time.UTC)
+	ev := &models.EventCreate{UserID: 1, Event: "Meeting", Date: date, End: date.Add(-time.Hour)}
+
+	_, _, err := svc.CreateEvent(context.Background(), ev)
+	if !errors.Is(err, ErrInvalidEnd) {
+		t.Fatalf("expected ErrInvalidEnd, got %v", err)
+	}
+}
+
+func TestServiceGetEventsMergesOverrides(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := eventR.NewMockeventRepo(ctrl)
+	svc := New(mockRepo, newSettingsRepo(ctrl), nil, newNotifier(ctrl), models.ConflictWarn)
+
+	from := time.Date(2025, 9, 1, 0, 0, 0, 0, time.UTC)
+	getData := &models.EventGet{UserID: 1, DateFrom: from, DateTo: from.AddDate(0, 0, 7)}
+
+	seriesID := uint(1)
+	moved := time.Date(2025, 9, 2, 9, 0, 0, 0, time.UTC)
+	cancelled := time.Date(2025, 9, 3, 9, 0, 0, 0, time.UTC)
+	mockEvents := []*models.Event{
+		{ID: seriesID, UserID: 1, Event: "Standup", Date: time.Date(2025, 9, 1, 9, 0, 0, 0, time.UTC), RRule: "FREQ=DAILY;COUNT=3", UID: "standup@example.com"},
+		{ID: uint(2), UserID: 1, Event: "Standup (moved)", Date: moved.Add(2 * time.Hour), ParentID: &seriesID, RecurrenceID: &moved},
+		{ID: uint(3), UserID: 1, Event: "Standup", Date: cancelled, ParentID: &seriesID, RecurrenceID: &cancelled, Cancelled: true},
+	}
+
+	mockRepo.EXPECT().
+		GetEvents(gomock.Any(), getData).
+		Return(mockEvents, nil)
+
+	events, err := svc.GetEvents(context.Background(), getData)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(events) != 2 {
+		t.Fatalf("expected 2 events, got %d", len(events))
+	}
+	if events[1].ID != seriesID || events[1].Event != "Standup (moved)" || !events[1].RecurrenceID.Equal(moved) {
+		t.Fatalf("expected moved occurrence of series %d, got %+v", seriesID, events[1])
+	}
+	if events[1].UID != "standup@example.com" {
+		t.Fatalf("expected moved occurrence with the series UID, got %q", events[1].UID)
+	}
+}
+
+func TestServiceCreateEventRejectsConflict(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := eventR.NewMockeventRepo(ctrl)
+	svc := New(mockRepo, newSettingsRepo(ctrl), nil, newNotifier(ctrl), models.ConflictWarn)
+
+	date := time.Date(2025, 9, 1, 10, 0, 0, 0, time.UTC)
+	existing := &models.Event{ID: 2, UserID: 1, Event: "Review", Date: date.Add(30 * time.Minute), End: date.Add(90 * time.Minute)}
+	ev := &models.EventCreate{UserID: 1, Event: "Meeting", Date: date, End: date.Add(time.Hour), ConflictPolicy: models.ConflictReject}
+
+	expectUserLock(mockRepo, 1)
+	mockRepo.EXPECT().
+		GetEvents(gomock.Any(), &models.EventGet{UserID: 1, DateFrom: ev.Date, DateTo: ev.End}).
+		Return([]*models.Event{existing}, nil)
+
+	_, _, err := svc.CreateEvent(context.Background(), ev)
+	var conflictErr *ConflictError
+	if !errors.As(err, &conflictErr) || !errors.Is(err, ErrConflict) {
+		t.Fatalf("expected ConflictError, got %v", err)
+	}
+	if len(conflictErr.Conflicts) != 1 || conflictErr.Conflicts[0] != existing {
+		t.Fatalf("unexpected conflicts %v", conflictErr.Conflicts)
+	}
+}
+
+func TestServiceCreateEventStoresExclusiveSeriesUnderUserLock(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := eventR.NewMockeventRepo(ctrl)
+	svc := New(mockRepo, newSettingsRepo(ctrl), nil, newNotifier(ctrl), models.ConflictWarn)
+
+	date := time.Date(2025, 9, 1, 10, 0, 0, 0, time.UTC)
+	ev := &models.EventCreate{UserID: 1, Event: "Standup", Date: date, End: date.Add(time.Hour), RRule: "FREQ=DAILY", ConflictPolicy: models.ConflictReject}
+
+	locked := false
+	mockRepo.EXPECT().
+		WithUserLock(gomock.Any(), 1, gomock.Any()).
+		DoAndReturn(func(ctx context.Context, _ int, fn func(ctx context.Context) error) error {
+			locked = true
+			defer func() { locked = false }()
+			return fn(ctx)
+		})
+	mockRepo.EXPECT().
+		GetEvents(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(context.Context, *models.EventGet) ([]*models.Event, error) {
+			if !locked {
+				t.Fatal("conflicts checked outside the user lock")
+			}
+			return []*models.Event{}, nil
+		})
+	mockRepo.EXPECT().
+		CreateEvent(gomock.Any(), ev).
+		DoAndReturn(func(context.Context, *models.EventCreate) (uint, error) {
+			if !locked {
+				t.Fatal("series stored outside the user lock")
+			}
+			return uint(1), nil
+		})
+
+	if _, _, err := svc.CreateEvent(context.Background(), ev); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestServiceCreateEventWarnsAboutConflicts(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := eventR.NewMockeventRepo(ctrl)
+	settingsRepo := eventR.NewMocksettingsRepo(ctrl)
+	svc := New(mockRepo, settingsRepo, nil, newNotifier(ctrl), models.ConflictReject)
+
+	date := time.Date(2025, 9, 1, 10, 0, 0, 0, time.UTC)
+	adjacent := &models.Event{ID: 2, UserID: 1, Event: "Lunch", Date: date.Add(time.Hour), End: date.Add(2 * time.Hour)}
+	standup := &models.Event{ID: 3, UserID: 1, Event: "Standup", Date: date.Add(-time.Hour), End: date.Add(-45 * time.Minute), RRule: "FREQ=DAILY"}
+	review := &models.Event{ID: 5, UserID: 1, Event: "Review", Date: date.AddDate(0, 0, 1).Add(30 * time.Minute), End: date.AddDate(0, 0, 1).Add(90 * time.Minute)}
+	ev := &models.EventCreate{UserID: 1, Event: "Planning", Date: date, End: date.Add(time.Hour), RRule: "FREQ=DAILY;COUNT=2"}
+
+	settingsRepo.EXPECT().
+		GetSettings(gomock.Any(), 1).
+		Return(&models.UserSettings{UserID: 1, ConflictPolicy: models.ConflictWarn}, nil)
+	mockRepo.EXPECT().
+		GetEvents(gomock.Any(), gomock.Any()).
+		Return([]*models.Event{adjacent, standup, review}, nil)
+	mockRepo.EXPECT().
+		CreateEvent(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, e *models.EventCreate) (uint, error) {
+			if e.ConflictPolicy != models.ConflictWarn {
+				t.Fatalf("expected policy %q, got %q", models.ConflictWarn, e.ConflictPolicy)
+			}
+			return uint(4), nil
+		})
+
+	_, conflicts, err := svc.CreateEvent(context.Background(), ev)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(conflicts) != 1 || conflicts[0] != review {
+		t.Fatalf("expected the review to conflict, got %v", conflicts)
+	}
+}
+
+func TestServiceUpdateEventThisOccurrence(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := eventR.NewMockeventRepo(ctrl)
+	svc := New(mockRepo, newSettingsRepo(ctrl), nil, newNotifier(ctrl), models.ConflictWarn)
+
+	seriesID := uint(1)
+	series := &models.Event{ID: seriesID, UserID: 1, Event: "Standup", Date: time.Date(2025, 9, 1, 9, 0, 0, 0, time.UTC), RRule: "FREQ=DAILY"}
+	occurrence := time.Date(2025, 9, 9, 9, 0, 0, 0, time.UTC)
+	ev := &models.Event{
+		ID:           seriesID,
+		UserID:       1,
+		Event:        "Standup",
+		Date:         occurrence.Add(time.Hour),
+		End:          occurrence.Add(90 * time.Minute),
+		Scope:        models.ScopeThis,
+		RecurrenceID: &occurrence,
+	}
+
+	mockRepo.EXPECT().
+		GetEvent(gomock.Any(), seriesID).
+		Return(series, nil)
+	mockRepo.EXPECT().
+		GetEvents(gomock.Any(), gomock.Any()).
+		Return([]*models.Event{series}, nil)
+	mockRepo.EXPECT().
+		SaveOverride(gomock.Any(), &models.Event{Event: ev.Event, Date: ev.Date, End: ev.End, ParentID: &seriesID, RecurrenceID: &occurrence}).
+		Return(uint(7), nil)
+
+	id, _, err := svc.UpdateEvent(context.Background(), ev)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if id != seriesID {
+		t.Fatalf("expected id %v, got %v", seriesID, id)
+	}
+}
+
+func TestServiceDeleteEventFollowingSplitsSeries(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := eventR.NewMockeventRepo(ctrl)
+	svc := New(mockRepo, newSettingsRepo(ctrl), nil, newNotifier(ctrl), models.ConflictWarn)
+
+	seriesID := uint(1)
+	series := &models.Event{ID: seriesID, UserID: 1, Event: "Standup", Date: time.Date(2025, 9, 1, 9, 0, 0, 0, time.UTC), RRule: "FREQ=DAILY;COUNT=10"}
+	occurrence := time.Date(2025, 9, 5, 9, 0, 0, 0, time.UTC)
+
+	mockRepo.EXPECT().
+		GetEvent(gomock.Any(), seriesID).
+		Return(series, nil)
+	mockRepo.EXPECT().
+		SplitSeries(gomock.Any(), seriesID, "FREQ=DAILY;UNTIL=20250905T085959Z", occurrence, nil).
+		Return(seriesID, nil)
+
+	_, err := svc.DeleteEvent(context.Background(), &models.EventDelete{ID: seriesID, Scope: models.ScopeFollowing, RecurrenceID: &occurrence})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestServiceUpdateEventFollowingFromFirstOccurrenceDropsOverrides(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := eventR.NewMockeventRepo(ctrl)
+	svc := New(mockRepo, newSettingsRepo(ctrl), nil, newNotifier(ctrl), models.ConflictWarn)
+
+	seriesID := uint(1)
+	start := time.Date(2025, 9, 1, 9, 0, 0, 0, time.UTC)
+	series := &models.Event{ID: seriesID, UserID: 1, Event: "Standup", Date: start, End: start.Add(time.Hour), RRule: "FREQ=DAILY"}
+	ev := &models.Event{
+		ID:           seriesID,
+		UserID:       1,
+		Event:        "Standup",
+		Date:         start.Add(time.Hour),
+		End:          start.Add(2 * time.Hour),
+		Scope:        models.ScopeFollowing,
+		RecurrenceID: &start,
+	}
+
+	mockRepo.EXPECT().
+		GetEvent(gomock.Any(), seriesID).
+		Return(series, nil)
+	mockRepo.EXPECT().
+		GetEvents(gomock.Any(), gomock.Any()).
+		Return([]*models.Event{series}, nil)
+	mockRepo.EXPECT().
+		ReplaceSeries(gomock.Any(), gomock.Any(), nil).
+		DoAndReturn(func(_ context.Context, next *models.Event, _ []*models.Event) (uint, error) {
+			if next.ID != seriesID || !next.Date.Equal(ev.Date) || next.RRule != "FREQ=DAILY" {
+				t.Fatalf("unexpected series %+v", next)
+			}
+			return seriesID, nil
+		})
+
+	id, _, err := svc.UpdateEvent(context.Background(), ev)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if id != seriesID {
+		t.Fatalf("expected id %v, got %v", seriesID, id)
+	}
+}
+
+func TestServiceUpdateEventFollowingKeepsRejectPolicy(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := eventR.NewMockeventRepo(ctrl)
+	svc := New(mockRepo, newSettingsRepo(ctrl), nil, newNotifier(ctrl), models.ConflictWarn)
+
+	seriesID := uint(1)
+	start := time.Date(2025, 9, 1, 9, 0, 0, 0, time.UTC)
+	series := &models.Event{ID: seriesID, UserID: 1, Event: "Standup", Date: start, End: start.Add(time.Hour), RRule: "FREQ=DAILY"}
+	ev := &models.Event{
+		ID:             seriesID,
+		UserID:         1,
+		Event:          "Standup",
+		Date:           start.Add(time.Hour),
+		End:            start.Add(2 * time.Hour),
+		Scope:          models.ScopeFollowing,
+		RecurrenceID:   &start,
+		ConflictPolicy: models.ConflictReject,
+	}
+
+	mockRepo.EXPECT().
+		GetEvent(gomock.Any(), seriesID).
+		Return(series, nil)
+	mockRepo.EXPECT().
+		WithUserLock(gomock.Any(), 1, gomock.Any()).
+		DoAndReturn(func(ctx context.Context, _ int, fn func(ctx context.Context) error) error {
+			return fn(ctx)
+		})
+	mockRepo.EXPECT().
+		GetEvents(gomock.Any(), gomock.Any()).
+		Return([]*models.Event{series}, nil)
+	// The whole series is replaced and stays exclusive.
+	mockRepo.EXPECT().
+		ReplaceSeries(gomock.Any(), gomock.Any(), nil).
+		DoAndReturn(func(_ context.Context, next *models.Event, _ []*models.Event) (uint, error) {
+			if next.ConflictPolicy != models.ConflictReject {
+				t.Fatalf("expected the reject policy, got %q", next.ConflictPolicy)
+			}
+			return seriesID, nil
+		})
+
+	if _, _, err := svc.UpdateEvent(context.Background(), ev); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestServiceUpdateEventFollowingSplitsCountOfClientRule(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := eventR.NewMockeventRepo(ctrl)
+	svc := New(mockRepo, newSettingsRepo(ctrl), nil, newNotifier(ctrl), models.ConflictWarn)
+
+	seriesID := uint(1)
+	start := time.Date(2025, 9, 1, 9, 0, 0, 0, time.UTC)
+	series := &models.Event{ID: seriesID, UserID: 1, Event: "Standup", Date: start, End: start.Add(time.Hour), RRule: "FREQ=DAILY;COUNT=10"}
+	occurrence := time.Date(2025, 9, 5, 9, 0, 0, 0, time.UTC)
+	ev := &models.Event{
+		ID:           seriesID,
+		UserID:       1,
+		Event:        "Standup",
+		Date:         occurrence.Add(time.Hour),
+		End:          occurrence.Add(2 * time.Hour),
+		RRule:        "FREQ=DAILY;COUNT=10",
+		Scope:        models.ScopeFollowing,
+		RecurrenceID: &occurrence,
+	}
+
+	mockRepo.EXPECT().
+		GetEvent(gomock.Any(), seriesID).
+		Return(series, nil)
+	mockRepo.EXPECT().
+		GetEvents(gomock.Any(), gomock.Any()).
+		Return([]*models.Event{series}, nil)
+	mockRepo.EXPECT().
+		SplitSeries(gomock.Any(), seriesID, "FREQ=DAILY;UNTIL=20250905T085959Z", occurrence, gomock.Any()).
+		DoAndReturn(func(_ context.Context, _ uint, _ string, _ time.Time, next *models.Event) (uint, error) {
+			if next.RRule != "FREQ=DAILY;COUNT=6" {
+				t.Fatalf("expected the remaining 6 occurrences, got rule %q", next.RRule)
+			}
+			return uint(2), nil
+		})
+
+	id, _, err := svc.UpdateEvent(context.Background(), ev)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if id != 2 {
+		t.Fatalf("expected id 2, got %v", id)
+	}
+}
+
+func TestServiceReplaceSeriesReplacesOverrides(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := eventR.NewMockeventRepo(ctrl)
+	svc := New(mockRepo, newSettingsRepo(ctrl), nil, newNotifier(ctrl), models.ConflictWarn)
+
+	start := time.Date(2025, 9, 1, 9, 0, 0, 0, time.UTC)
+	series := &models.Event{ID: 1, UserID: 1, Event: "Standup", Date: start, End: start.Add(time.Hour), RRule: "FREQ=DAILY"}
+	occurrence := start.AddDate(0, 0, 2)
+	override := &models.Event{Event: "Late standup", Date: occurrence.Add(time.Hour), End: occurrence.Add(2 * time.Hour), RecurrenceID: &occurrence}
+
+	mockRepo.EXPECT().
+		GetEvents(gomock.Any(), gomock.Any()).
+		Return([]*models.Event{series}, nil).
+		Times(2)
+	mockRepo.EXPECT().
+		ReplaceSeries(gomock.Any(), series, []*models.Event{override}).
+		Return(uint(1), nil)
+
+	id, _, err := svc.ReplaceSeries(context.Background(), series, []*models.Event{override})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if id != 1 {
+		t.Fatalf("expected id 1, got %v", id)
+	}
+}
+
+func TestServiceReplaceSeriesRejectsUnknownOccurrence(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := eventR.NewMockeventRepo(ctrl)
+	svc := New(mockRepo, newSettingsRepo(ctrl), nil, newNotifier(ctrl), models.ConflictWarn)
+
+	start := time.Date(2025, 9, 1, 9, 0, 0, 0, time.UTC)
+	series := &models.Event{ID: 1, UserID: 1, Event: "Standup", Date: start, End: start.Add(time.Hour), RRule: "FREQ=WEEKLY"}
+	notAnOccurrence := start.AddDate(0, 0, 2)
+	override := &models.Event{Event: "Standup", Date: notAnOccurrence, End: notAnOccurrence.Add(time.Hour), RecurrenceID: &notAnOccurrence}
+
+	mockRepo.EXPECT().
+		GetEvents(gomock.Any(), gomock.Any()).
+		Return([]*models.Event{series}, nil)
+
+	_, _, err := svc.ReplaceSeries(context.Background(), series, []*models.Event{override})
+	if !errors.Is(err, ErrOccurrenceNotFound) {
+		t.Fatalf("expected ErrOccurrenceNotFound, got %v", err)
+	}
+}
+
+func TestServiceDeleteEventThisRequiresRecurringSeries(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := eventR.NewMockeventRepo(ctrl)
+	svc := New(mockRepo, newSettingsRepo(ctrl), nil, newNotifier(ctrl), models.ConflictWarn)
+
+	eventID := uint(1)
+	occurrence := time.Date(2025, 9, 5, 9, 0, 0, 0, time.UTC)
+
+	mockRepo.EXPECT().
+		GetEvent(gomock.Any(), eventID).
+		Return(&models.Event{ID: eventID, UserID: 1, Event: "Review", Date: occurrence}, nil)
+
+	_, err := svc.DeleteEvent(context.Background(), &models.EventDelete{ID: eventID, Scope: models.ScopeThis, RecurrenceID: &occurrence})
+	if !errors.Is(err, ErrNotRecurring) {
+		t.Fatalf("expected ErrNotRecurring, got %v", err)
+	}
+}
+
+func TestServiceImportEvents(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := eventR.NewMockeventRepo(ctrl)
+	svc := New(mockRepo, newSettingsRepo(ctrl), nil, newNotifier(ctrl), models.ConflictWarn)
+
+	data := "BEGIN:VCALENDAR\r\n" +
+		"BEGIN:VEVENT\r\nUID:a@example.com\r\nDTSTART:20250901T090000Z\r\nSUMMARY:A\r\nEND:VEVENT\r\n" +
+		"BEGIN:VEVENT\r\nUID:b@example.com\r\nSUMMARY:No start\r\nEND:VEVENT\r\n" +
+		"BEGIN:VEVENT\r\nUID:a@example.com\r\nDTSTART:20250902T090000Z\r\nSUMMARY:A again\r\nEND:VEVENT\r\n" +
+		"END:VCALENDAR\r\n"
+
+	mockRepo.EXPECT().
+		ImportEvents(gomock.Any(), gomock.Len(1)).
+		Return([]*models.ImportItem{{UID: "a@example.com", ID: 5, Status: models.ImportUpdated}}, nil)
+
+	report, err := svc.ImportEvents(context.Background(), &models.EventImport{UserID: 1, Mail: "user@example.com"}, strings.NewReader(data))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if report.Updated != 1 || report.Failed != 1 || report.Skipped != 1 || len(report.Items) != 3 {
+		t.Fatalf("unexpected report: %+v", report)
+	}
+	if report.Items[0].ID != 5 {
+		t.Fatalf("expected item id 5, got %d", report.Items[0].ID)
+	}
+}
+
+func TestServiceImportEventsReturnsReportWhenRemindersFail(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := eventR.NewMockeventRepo(ctrl)
+	reminderRepo := eventR.NewMockreminderRepo(ctrl)
+	svc := New(mockRepo, newSettingsRepo(ctrl), reminderRepo, newNotifier(ctrl), models.ConflictWarn)
+
+	data := "BEGIN:VCALENDAR\r\n" +
+		"BEGIN:VEVENT\r\nUID:a@example.com\r\nDTSTART:20250901T090000Z\r\nSUMMARY:A\r\nEND:VEVENT\r\n" +
+		"END:VCALENDAR\r\n"
+
+	mockRepo.EXPECT().
+		ImportEvents(gomock.Any(), gomock.Len(1)).
+		Return([]*models.ImportItem{{UID: "a@example.com", ID: 5, Status: models.ImportCreated}}, nil)
+	mockRepo.EXPECT().
+		GetEvent(gomock.Any(), uint(5)).
+		Return(nil, errors.New("connection lost"))
+
+	report, err := svc.ImportEvents(context.Background(), &models.EventImport{UserID: 1, Mail: "user@example.com"}, strings.NewReader(data))
+	if err == nil {
+		t.Fatal("expected error")
+	}
+	if report == nil || report.Created != 1 || report.Items[0].ID != 5 {
+		t.Fatalf("expected report of the stored event, got %+v", report)
+	}
+}
+
+func TestServiceGetFreeBusy(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := eventR.NewMockeventRepo(ctrl)
+	svc := New(mockRepo, newSettingsRepo(ctrl), nil, newNotifier(ctrl), models.ConflictWarn)
+
+	from := time.Date(2025, 9, 1, 0, 0, 0, 0, time.UTC)
+	to := from.AddDate(0, 0, 2)
+	at := func(day, hour, minute int) time.Time {
+		return from.AddDate(0, 0, day).Add(time.Duration(hour)*time.Hour + time.Duration(minute)*time.Minute)
+	}
+
+	mockRepo.EXPECT().
+		GetEvents(gomock.Any(), &models.EventGet{UserID: 1, DateFrom: from, DateTo: to}).
+		Return([]*models.Event{
+			{ID: 1, UserID: 1, Event: "Standup", Date: at(-1, 9, 0), End: at(-1, 9, 30), RRule: "FREQ=DAILY"},
+			{ID: 2, UserID: 1, Event: "Planning", Date: at(0, 9, 15), End: at(0, 10, 0)},
+			{ID: 3, UserID: 1, Event: "Lunch", Date: at(0, 10, 0), End: at(0, 11, 0)},
+			{ID: 4, UserID: 1, Event: "Reminder", Date: at(0, 15, 0), End: at(0, 15, 0)},
+		}, nil)
+	mockRepo.EXPECT().
+		GetEvents(gomock.Any(), &models.EventGet{UserID: 2, DateFrom: from, DateTo: to}).
+		Return([]*models.Event{
+			{ID: 5, UserID: 2, Event: "Trip", Date: at(-1, 12, 0), End: at(0, 12, 0)},
+		}, nil)
+
+	freeBusy, err := svc.GetFreeBusy(context.Background(), &models.FreeBusyGet{UserIDs: []int{1, 2, 1}, DateFrom: from, DateTo: to})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := []*models.FreeBusy{
+		{UserID: 1, Busy: []models.Interval{{Start: at(0, 9, 0), End: at(0, 11, 0)}, {Start: at(1, 9, 0), End: at(1, 9, 30)}}},
+		{UserID: 2, Busy: []models.Interval{{Start: from, End: at(0, 12, 0)}}},
+	}
+	if len(freeBusy) != len(want) {
+		t.Fatalf("expected %d users, got %d", len(want), len(freeBusy))
+	}
+	for i := range want {
+		if freeBusy[i].UserID != want[i].UserID || len(freeBusy[i].Busy) != len(want[i].Busy) {
+			t.Fatalf("unexpected free/busy %+v", freeBusy[i])
+		}
+		for j, busy := range want[i].Busy {
+			if !freeBusy[i].Busy[j].Start.Equal(busy.Start) || !freeBusy[i].Busy[j].End.Equal(busy.End) {
+				t.Fatalf("user %d: expected %v, got %v", want[i].UserID, busy, freeBusy[i].Busy[j])
+			}
+		}
+	}
+}
+
+func TestServiceFindSlots(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := eventR.NewMockeventRepo(ctrl)
+	svc := New(mockRepo, newSettingsRepo(ctrl), nil, newNotifier(ctrl), models.ConflictWarn)
+
+	friday := time.Date(2025, 9, 5, 0, 0, 0, 0, time.UTC)
+	to := friday.AddDate(0, 0, 3)
+	at := func(hour, minute int) time.Time {
+		return friday.Add(time.Duration(hour)*time.Hour + time.Duration(minute)*time.Minute)
+	}
+
+	mockRepo.EXPECT().
+		GetEvents(gomock.Any(), &models.EventGet{UserID: 1, DateFrom: friday, DateTo: to}).
+		Return([]*models.Event{{ID: 1, UserID: 1, Event: "Review", Date: at(9, 0), End: at(10, 15)}}, nil)
+	mockRepo.EXPECT().
+		GetEvents(gomock.Any(), &models.EventGet{UserID: 2, DateFrom: friday, DateTo: to}).
+		Return([]*models.Event{
+			{ID: 2, UserID: 2, Event: "Lunch", Date: at(11, 0), End: at(12, 0)},
+			{ID: 3, UserID: 2, Event: "Workshop", Date: at(13, 0), End: at(15, 0)},
+		}, nil)
+
+	slots, err := svc.FindSlots(context.Background(), &models.SlotQuery{
+		UserIDs:  []int{1, 2},
+		Duration: 60,
+		DateFrom: friday,
+		DateTo:   to,
+		Limit:    4,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := []time.Time{at(12, 0), at(15, 0), at(17, 0), at(15, 30)}
+	if len(slots) != len(want) {
+		t.Fatalf("expected %d slots, got %v", len(want), slots)
+	}
+	for i, start := range want {
+		if !slots[i].Start.Equal(start) || !slots[i].End.Equal(start.Add(time.Hour)) {
+			t.Fatalf("slot %d: expected start %v, got %v", i, start, slots[i])
+		}
+	}
+}
+
+func TestServiceFindSlotsInvalidWorkingHours(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := eventR.NewMockeventRepo(ctrl)
+	svc := New(mockRepo, newSettingsRepo(ctrl), nil, newNotifier(ctrl), models.ConflictWarn)
+
+	from := time.Date(2025, 9, 1, 0, 0, 0, 0, time.UTC)
+	_, err := svc.FindSlots(context.Background(), &models.SlotQuery{
+		UserIDs:   []int{1},
+		Duration:  30,
+		DateFrom:  from,
+		DateTo:    from.AddDate(0, 0, 1),
+		WorkStart: "18:00",
+		WorkEnd:   "09:00",
+	})
+	if !errors.Is(err, ErrInvalidWorkingHours) {
+		t.Fatalf("expected ErrInvalidWorkingHours, got %v", err)
+	}
+}
+
+func TestServiceFindSlotsRejectsLongWindow(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := eventR.NewMockeventRepo(ctrl)
+	svc := New(mockRepo, newSettingsRepo(ctrl), nil, newNotifier(ctrl), models.ConflictWarn)
+
+	from := time.Date(2025, 9, 1, 0, 0, 0, 0, time.UTC)
+	_, err := svc.FindSlots(context.Background(), &models.SlotQuery{
+		UserIDs:  []int{1},
+		Duration: 30,
+		DateFrom: from,
+		DateTo:   from.AddDate(1, 0, 0),
+	})
+	if !errors.Is(err, ErrSlotWindowTooLong) {
+		t.Fatalf("expected ErrSlotWindowTooLong, got %v", err)
+	}
+}
+
+func newSettingsRepo(ctrl *gomock.Controller) *eventR.MocksettingsRepo {
+	settingsRepo := eventR.NewMocksettingsRepo(ctrl)
+	settingsRepo.EXPECT().
+		GetSettings(gomock.Any(), gomock.Any()).
+		Return(&models.UserSettings{}, nil).
+		AnyTimes()
+	return settingsRepo
+}
+
+// expectUserLock expects the repository to run a function once under the
+// lock of the user.
+func expectUserLock(mockRepo *eventR.MockeventRepo, userID int) {
+	mockRepo.EXPECT().
+		WithUserLock(gomock.Any(), userID, gomock.Any()).
+		DoAndReturn(func(ctx context.Context, _ int, fn func(ctx context.Context) error) error {
+			return fn(ctx)
+		})
+}
+
+func newNotifier(ctrl *gomock.Controller) *eventR.MockattendeeNotifier {
+	notifier := eventR.NewMockattendeeNotifier(ctrl)
+	notifier.EXPECT().
+		NotifyUpdated(gomock.Any(), gomock.Any()).
+		Return(nil).
+		AnyTimes()
+	notifier.EXPECT().
+		PrepareCancel(gomock.Any(), gomock.Any()).
+		Return(func() error { return nil }, nil).
+		AnyTimes()
+	return notifier
+}
+
+func TestServiceCreateEventSchedulesReminder(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := eventR.NewMockeventRepo(ctrl)
+	reminderRepo := eventR.NewMockreminderRepo(ctrl)
+	svc := New(mockRepo, newSettingsRepo(ctrl), reminderRepo, newNotifier(ctrl), models.ConflictWarn)
+
+	date := time.Now().Add(2 * time.Hour).UTC().Truncate(time.Second)
+	ev := &models.EventCreate{UserID: 1, Event: "Review", Date: date, Mail: "alice@example.com"}
+
+	mockRepo.EXPECT().
+		CreateEvent(gomock.Any(), ev).
+		Return(uint(4), nil)
+	mockRepo.EXPECT().
+		GetEvent(gomock.Any(), uint(4)).
+		Return(&models.Event{ID: 4, UserID: 1, Event: "Review", Date: date, End: date, Mail: "alice@example.com"}, nil)
+	reminderRepo.EXPECT().
+		GetSent(gomock.Any(), uint(4), gomock.Any()).
+		Return(nil, nil)
+	reminderRepo.EXPECT().
+		SaveReminders(gomock.Any(), uint(4), gomock.Any(), []*models.Reminder{{EventID: 4, UserID: 1, Event: "Review", Date: date,
+			Mail: "alice@example.com", Offset: 60, FireAt: date.Add(-time.Hour), Channel: models.ChannelEmail}}).
+		Return(nil)
+
+	if _, _, err := svc.CreateEvent(context.Background(), ev); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestServiceDeleteEventRemovesReminder(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := eventR.NewMockeventRepo(ctrl)
+	reminderRepo := eventR.NewMockreminderRepo(ctrl)
+	svc := New(mockRepo, newSettingsRepo(ctrl), reminderRepo, newNotifier(ctrl), models.ConflictWarn)
+
+	mockRepo.EXPECT().
+		DeleteEvent(gomock.Any(), uint(4)).
+		Return(uint(4), nil)
+	mockRepo.EXPECT().
+		GetEvent(gomock.Any(), uint(4)).
+		Return(nil, repository.ErrEventNotFound)
+	reminderRepo.EXPECT().
+		SaveReminders(gomock.Any(), uint(4), gomock.Any(), nil).
+		Return(nil)
+
+	if _, err := svc.DeleteEvent(context.Background(), &models.EventDelete{ID: 4}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestServiceNextRemindersSkipsChangedOccurrences(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := eventR.NewMockeventRepo(ctrl)
+	reminderRepo := eventR.NewMockreminderRepo(ctrl)
+	svc := New(mockRepo, newSettingsRepo(ctrl), reminderRepo, newNotifier(ctrl), models.ConflictWarn)
+
+	seriesID := uint(2)
+	start := time.Date(2025, 9, 1, 9, 0, 0, 0, time.UTC)
+	now := time.Date(2025, 9, 3, 8, 0, 0, 0, time.UTC)
+	series := &models.Event{ID: seriesID, UserID: 1, Event: "Standup", Date: start, End: start.Add(15 * time.Minute),
+		TZ: "UTC", RRule: "FREQ=DAILY", Mail: "alice@example.com",
+		Reminders: []models.ReminderSpec{{Offset: 1440}, {Offset: 15, Channel: models.ChannelEmail}}}
+	today := time.Date(2025, 9, 3, 9, 0, 0, 0, time.UTC)
+	cancelled := &models.Event{ID: 10, UserID: 1, Event: "Standup", Date: today, End: today.Add(15 * time.Minute),
+		ParentID: &seriesID, RecurrenceID: &today, Cancelled: true}
+	tomorrow := today.AddDate(0, 0, 1)
+	moved := tomorrow.Add(time.Hour)
+	override := &models.Event{ID: 9, UserID: 1, Event: "Standup (moved)", Date: moved, End: moved.Add(15 * time.Minute),
+		ParentID: &seriesID, RecurrenceID: &tomorrow}
+	other := &models.Event{ID: 3, UserID: 1, Event: "Lunch", Date: today.Add(3 * time.Hour), End: today.Add(4 * time.Hour)}
+
+	mockRepo.EXPECT().
+		GetEvent(gomock.Any(), seriesID).
+		Return(series, nil)
+	mockRepo.EXPECT().
+		GetEvents(gomock.Any(), &models.EventGet{UserID: 1, DateFrom: now, DateTo: now.Add(reminderHorizon)}).
+		Return([]*models.Event{series, cancelled, override, other}, nil)
+	reminderRepo.EXPECT().
+		GetSent(gomock.Any(), seriesID, now).
+		Return([]*models.Reminder{{EventID: seriesID, Date: moved, Offset: 1440, Channel: models.ChannelEmail,
+			Status: models.ReminderSent}}, nil)
+
+	reminders, err := svc.nextReminders(context.Background(), seriesID, now)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(reminders) != 2 {
+		t.Fatalf("expected 2 reminders, got %d", len(reminders))
+	}
+
+	dayBefore, atShortNotice := reminders[0], reminders[1]
+	afterMoved := time.Date(2025, 9, 5, 9, 0, 0, 0, time.UTC)
+	if !dayBefore.Date.Equal(afterMoved) || !dayBefore.FireAt.Equal(afterMoved.AddDate(0, 0, -1)) ||
+		dayBefore.Channel != models.ChannelEmail {
+		t.Fatalf("expected the day-before reminder of the occurrence after the moved one, got %+v", dayBefore)
+	}
+	if !atShortNotice.Date.Equal(moved) || atShortNotice.Event != "Standup (moved)" ||
+		!atShortNotice.FireAt.Equal(moved.Add(-15*time.Minute)) || atShortNotice.Mail != "alice@example.com" {
+		t.Fatalf("expected the 15-minute reminder of the moved occurrence, got %+v", atShortNotice)
+	}
+}
+
+func TestServiceNextRemindersKeepsLatestOverdueReminder(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := eventR.NewMockeventRepo(ctrl)
+	reminderRepo := eventR.NewMockreminderRepo(ctrl)
+	settingsRepo := eventR.NewMocksettingsRepo(ctrl)
+	svc := New(mockRepo, settingsRepo, reminderRepo, newNotifier(ctrl), models.ConflictWarn)
+
+	now := time.Date(2025, 9, 3, 8, 0, 0, 0, time.UTC)
+	date := now.Add(10 * time.Minute)
+
+	mockRepo.EXPECT().
+		GetEvent(gomock.Any(), uint(5)).
+		Return(&models.Event{ID: 5, UserID: 1, Event: "Call", Date: date, End: date, Mail: "alice@example.com"}, nil)
+	settingsRepo.EXPECT().
+		GetSettings(gomock.Any(), 1).
+		Return(&models.UserSettings{UserID: 1, Reminders: []models.ReminderSpec{{Offset: 60}, {Offset: 30}, {Offset: 0}}}, nil)
+	reminderRepo.EXPECT().
+		GetSent(gomock.Any(), uint(5), now).
+		Return(nil, nil)
+
+	reminders, err := svc.nextReminders(context.Background(), 5, now)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(reminders) != 2 || reminders[0].Offset != 30 || reminders[1].Offset != 0 {
+		t.Fatalf("expected the 30-minute and the at-start reminders, got %+v", reminders)
+	}
+}
+
+func TestServiceNextRemindersUseDefaultChannel(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := eventR.NewMockeventRepo(ctrl)
+	reminderRepo := eventR.NewMockreminderRepo(ctrl)
+	settingsRepo := eventR.NewMocksettingsRepo(ctrl)
+	svc := New(mockRepo, settingsRepo, reminderRepo, newNotifier(ctrl), models.ConflictWarn)
+
+	now := time.Date(2025, 9, 3, 8, 0, 0, 0, time.UTC)
+	date := now.Add(2 * time.Hour)
+	reminders := []models.ReminderSpec{{Offset: 30}, {Offset: 10, Channel: models.ChannelEmail}}
+
+	mockRepo.EXPECT().
+		GetEvent(gomock.Any(), uint(5)).
+		Return(&models.Event{ID: 5, UserID: 1, Event: "Call", Date: date, End: date, Reminders: reminders}, nil)
+	settingsRepo.EXPECT().
+		GetSettings(gomock.Any(), 1).
+		Return(&models.UserSettings{UserID: 1, Channels: &models.ChannelSettings{Default: models.ChannelTelegram}}, nil)
+	reminderRepo.EXPECT().
+		GetSent(gomock.Any(), uint(5), now).
+		Return(nil, nil)
+
+	got, err := svc.nextReminders(context.Background(), 5, now)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(got) != 2 || got[0].Channel != models.ChannelTelegram || got[1].Channel != models.ChannelEmail {
+		t.Fatalf("expected a telegram and an email reminder, got %+v", got)
+	}
+}
+
+func TestServiceNextRemindersKeepUrgency(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := eventR.NewMockeventRepo(ctrl)
+	reminderRepo := eventR.NewMockreminderRepo(ctrl)
+	svc := New(mockRepo, newSettingsRepo(ctrl), reminderRepo, newNotifier(ctrl), models.ConflictWarn)
+
+	now := time.Date(2025, 9, 3, 8, 0, 0, 0, time.UTC)
+	date := now.Add(2 * time.Hour)
+
+	mockRepo.EXPECT().
+		GetEvent(gomock.Any(), uint(6)).
+		Return(&models.Event{ID: 6, UserID: 1, Event: "Incident review", Date: date, End: date, Urgent: true}, nil)
+	reminderRepo.EXPECT().
+		GetSent(gomock.Any(), uint(6), now).
+		Return(nil, nil)
+
+	got, err := svc.nextReminders(context.Background(), 6, now)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(got) != 1 || !got[0].Urgent {
+		t.Fatalf("expected an urgent reminder, got %+v", got)
+	}
+}
@@ -0,0 +1,193 @@
+package event
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/avraam311/improved-calendar-service/internal/models"
+)
+
+var (
+	ErrInvalidWorkingHours = errors.New("working hours must end after they start")
+	ErrSlotWindowTooLong   = errors.New("slot search window is too long")
+)
+
+const (
+	defaultWorkStart = "09:00"
+	defaultWorkEnd   = "18:00"
+	defaultSlotStep  = 30
+	defaultSlotLimit = 10
+
+	// maxSlotWindow bounds the window searched for slots, the candidates of
+	// every step in it are ranked in memory.
+	maxSlotWindow = 31 * 24 * time.Hour
+)
+
+// FindSlots returns up to query.Limit slots in the window when all users are
+// free. Slots that leave no gap before or after them come first, so that the
+// free time of the attendees stays in large blocks, then earlier slots. The
+// window may not be longer than maxSlotWindow.
+func (s *Service) FindSlots(ctx context.Context, query *models.SlotQuery) ([]models.Interval, error) {
+	if query.DateTo.Sub(query.DateFrom) > maxSlotWindow {
+		return nil, fmt.Errorf("service/FindSlots - %w", ErrSlotWindowTooLong)
+	}
+	_, loc, err := normalizeTZ(query.TZ)
+	if err != nil {
+		return nil, fmt.Errorf("service/FindSlots - %w", err)
+	}
+	workStart, workEnd, err := workingHours(query.WorkStart, query.WorkEnd)
+	if err != nil {
+		return nil, fmt.Errorf("service/FindSlots - %w", err)
+	}
+
+	freeBusy, err := s.GetFreeBusy(ctx, &models.FreeBusyGet{
+		UserIDs:  query.UserIDs,
+		DateFrom: query.DateFrom,
+		DateTo:   query.DateTo,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("service/FindSlots - %w", err)
+	}
+
+	var busy []models.Interval
+	for _, fb := range freeBusy {
+		busy = append(busy, fb.Busy...)
+	}
+	busy = mergeIntervals(busy)
+
+	duration := time.Duration(query.Duration) * time.Minute
+	step := query.Step
+	if step == 0 {
+		step = defaultSlotStep
+	}
+
+	type candidate struct {
+		slot models.Interval
+		gap  time.Duration
+	}
+	var candidates []candidate
+	for _, free := range freeIntervals(busy, query.DateFrom.In(loc), query.DateTo.In(loc), workStart, workEnd, query.IncludeWeekends) {
+		for _, start := range slotStarts(free, duration, step) {
+			end := start.Add(duration)
+			candidates = append(candidates, candidate{
+				slot: models.Interval{Start: start.UTC(), End: end.UTC()},
+				gap:  min(start.Sub(free.Start), free.End.Sub(end)),
+			})
+		}
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		if candidates[i].gap != candidates[j].gap {
+			return candidates[i].gap < candidates[j].gap
+		}
+		return candidates[i].slot.Start.Before(candidates[j].slot.Start)
+	})
+
+	limit := query.Limit
+	if limit == 0 {
+		limit = defaultSlotLimit
+	}
+	slots := make([]models.Interval, 0, min(limit, len(candidates)))
+	for _, c := range candidates[:min(limit, len(candidates))] {
+		slots = append(slots, c.slot)
+	}
+
+	return slots, nil
+}
+
+// workingHours parses the working hours as minutes after midnight.
+func workingHours(start, end string) (int, int, error) {
+	if start == "" {
+		start = defaultWorkStart
+	}
+	if end == "" {
+		end = defaultWorkEnd
+	}
+
+	from, err := time.Parse("15:04", start)
+	if err != nil {
+		return 0, 0, ErrInvalidWorkingHours
+	}
+	to, err := time.Parse("15:04", end)
+	if err != nil || !to.After(from) {
+		return 0, 0, ErrInvalidWorkingHours
+	}
+
+	return from.Hour()*60 + from.Minute(), to.Hour()*60 + to.Minute(), nil
+}
+
+// freeIntervals returns the working hours of every day in [from, to] minus
+// the busy intervals. Days are taken in the location of from.
+func freeIntervals(busy []models.Interval, from, to time.Time, workStart, workEnd int, weekends bool) []models.Interval {
+	var free []models.Interval
+	for day := midnight(from); day.Before(to); day = day.AddDate(0, 0, 1) {
+		if !weekends && (day.Weekday() == time.Saturday || day.Weekday() == time.Sunday) {
+			continue
+		}
+
+		start := atClock(day, workStart)
+		end := atClock(day, workEnd)
+		if start.Before(from) {
+			start = from
+		}
+		if end.After(to) {
+			end = to
+		}
+
+		for _, b := range busy {
+			if !end.After(start) {
+				break
+			}
+			if !b.End.After(start) || !b.Start.Before(end) {
+				continue
+			}
+			if b.Start.After(start) {
+				free = append(free, models.Interval{Start: start, End: b.Start.In(from.Location())})
+			}
+			start = b.End.In(from.Location())
+		}
+		if end.After(start) {
+			free = append(free, models.Interval{Start: start, End: end})
+		}
+	}
+
+	return free
+}
+
+// slotStarts returns the starts of the slots of the given duration within
+// free: its start, the points of the grid of step minutes counted from
+// midnight and the start of the slot ending with free.
+func slotStarts(free models.Interval, duration time.Duration, step int) []time.Time {
+	last := free.End.Add(-duration)
+	if last.Before(free.Start) {
+		return nil
+	}
+
+	starts := []time.Time{free.Start}
+	y, m, d := free.Start.Date()
+	for k := (free.Start.Hour()*60+free.Start.Minute())/step + 1; ; k++ {
+		next := time.Date(y, m, d, 0, k*step, 0, 0, free.Start.Location())
+		if next.After(last) {
+			break
+		}
+		starts = append(starts, next)
+	}
+	if !last.Equal(starts[len(starts)-1]) {
+		starts = append(starts, last)
+	}
+
+	return starts
+}
+
+func midnight(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
+
+// atClock returns the wall-clock time minutes after the midnight of day, so
+// that working hours stay the same when the clocks change.
+func atClock(day time.Time, minutes int) time.Time {
+	return time.Date(day.Year(), day.Month(), day.Day(), 0, minutes, 0, 0, day.Location())
+}
@@ -0,0 +1,140 @@
+package event
+
+import (
+	"strings"
+	"time"
+
+	"github.com/avraam311/improved-calendar-service/internal/models"
+)
+
+// normalizeEvent validates the recurrence rule, time zone and span of an event
+// and converts its times to UTC, the database stores timestamps without zone.
+// Events without a time zone get fallback or UTC.
+func normalizeEvent(event *models.Event, fallback string) error {
+	rule, err := normalizeRRule(event.RRule)
+	if err != nil {
+		return err
+	}
+	event.RRule = rule
+
+	if event.TZ == "" {
+		event.TZ = fallback
+	}
+	tz, loc, err := normalizeTZ(event.TZ)
+	if err != nil {
+		return err
+	}
+	event.TZ = tz
+
+	event.Date, event.End, err = normalizeSpan(event.Date, event.End, event.AllDay, loc)
+	if err != nil {
+		return err
+	}
+	event.ExDates = utcTimes(event.ExDates)
+
+	return nil
+}
+
+func normalizeEventCreate(event *models.EventCreate) error {
+	e := &models.Event{
+		Date:    event.Date,
+		End:     event.End,
+		AllDay:  event.AllDay,
+		TZ:      event.TZ,
+		RRule:   event.RRule,
+		ExDates: event.ExDates,
+	}
+	if err := normalizeEvent(e, ""); err != nil {
+		return err
+	}
+
+	event.Date, event.End, event.TZ, event.RRule, event.ExDates = e.Date, e.End, e.TZ, e.RRule, e.ExDates
+	return nil
+}
+
+// normalizeTZ checks an IANA time zone name. An empty name is UTC.
+func normalizeTZ(tz string) (string, *time.Location, error) {
+	if tz == "" || strings.EqualFold(tz, "UTC") {
+		return "UTC", time.UTC, nil
+	}
+	if strings.EqualFold(tz, "Local") {
+		return "", nil, ErrInvalidTimeZone
+	}
+
+	loc, err := time.LoadLocation(tz)
+	if err != nil {
+		return "", nil, ErrInvalidTimeZone
+	}
+
+	return loc.String(), loc, nil
+}
+
+// location returns the time zone of a stored event.
+func location(tz string) *time.Location {
+	loc, err := time.LoadLocation(tz)
+	if err != nil {
+		return time.UTC
+	}
+
+	return loc
+}
+
+// normalizeSpan checks the end of an event and fills it in when it is not
+// given. Timed events without an end take no time. All-day events cover
+// whole days in loc: they start at midnight of the day written in date and
+// end at midnight, one day later by default.
+func normalizeSpan(date, end time.Time, allDay bool, loc *time.Location) (time.Time, time.Time, error) {
+	if allDay {
+		date = time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, loc)
+		if end.IsZero() {
+			end = date.AddDate(0, 0, 1)
+		} else {
+			endDay := time.Date(end.Year(), end.Month(), end.Day(), 0, 0, 0, 0, loc)
+			if end.Hour() != 0 || end.Minute() != 0 || end.Second() != 0 || end.Nanosecond() != 0 {
+				endDay = endDay.AddDate(0, 0, 1)
+			}
+			end = endDay
+		}
+	}
+	if end.IsZero() {
+		end = date
+	}
+
+	if end.Before(date) || (allDay && !end.After(date)) {
+		return time.Time{}, time.Time{}, ErrInvalidEnd
+	}
+
+	return date.UTC(), end.UTC(), nil
+}
+
+func utcTime(t *time.Time) *time.Time {
+	if t == nil {
+		return nil
+	}
+
+	utc := t.UTC()
+	return &utc
+}
+
+func utcTimes(times []time.Time) []time.Time {
+	if times == nil {
+		return nil
+	}
+
+	utc := make([]time.Time, 0, len(times))
+	for _, t := range times {
+		utc = append(utc, t.UTC())
+	}
+
+	return utc
+}
+
+// overlaps reports whether the event [start, end) overlaps [from, to]. Events
+// without duration overlap when they start inside the window.
+func overlaps(start, end, from, to time.Time) bool {
+	if start.After(to) {
+		return false
+	}
+
+	return end.After(from) || !start.Before(from)
+}
@@ -0,0 +1,69 @@
+package feed
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+)
+
+//go:generate mockgen -source=service.go -destination=../../mocks/mock_feed_service.go -package=mocks
+type feedRepo interface {
+	SaveToken(ctx context.Context, userID int, tokenHash string) error
+	DeleteToken(ctx context.Context, userID int) error
+	GetUserID(ctx context.Context, tokenHash string) (int, error)
+}
+
+// Service manages the secret tokens of the per-user calendar feeds. Only a
+// hash of each token is stored, the token itself is shown once on rotation.
+type Service struct {
+	feedRepo feedRepo
+}
+
+func New(r feedRepo) *Service {
+	return &Service{
+		feedRepo: r,
+	}
+}
+
+// RotateToken issues a new feed token for the user and invalidates the old one.
+func (s *Service) RotateToken(ctx context.Context, userID int) (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("service/RotateToken - %w", err)
+	}
+	token := base64.RawURLEncoding.EncodeToString(b)
+
+	err := s.feedRepo.SaveToken(ctx, userID, hashToken(token))
+	if err != nil {
+		return "", fmt.Errorf("service/RotateToken - %w", err)
+	}
+
+	return token, nil
+}
+
+func (s *Service) RevokeToken(ctx context.Context, userID int) error {
+	err := s.feedRepo.DeleteToken(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("service/RevokeToken - %w", err)
+	}
+
+	return nil
+}
+
+// ResolveToken returns the owner of the feed token.
+func (s *Service) ResolveToken(ctx context.Context, token string) (int, error) {
+	userID, err := s.feedRepo.GetUserID(ctx, hashToken(token))
+	if err != nil {
+		return 0, fmt.Errorf("service/ResolveToken - %w", err)
+	}
+
+	return userID, nil
+}
+
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,49 @@
+//go:build unit
+// +build unit
+
+package feed
+
+import (
+	"context"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+
+	feedR "github.com/avraam311/improved-calendar-service/internal/mocks"
+)
+
+func TestServiceRotateAndResolveToken(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := feedR.NewMockfeedRepo(ctrl)
+	svc := New(mockRepo)
+
+	var storedHash string
+	mockRepo.EXPECT().
+		SaveToken(gomock.Any(), 1, gomock.Any()).
+		DoAndReturn(func(_ context.Context, _ int, hash string) error {
+			storedHash = hash
+			return nil
+		})
+
+	token, err := svc.RotateToken(context.Background(), 1)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if token == "" || storedHash == token {
+		t.Fatalf("expected the token to be stored hashed, got token %q hash %q", token, storedHash)
+	}
+
+	mockRepo.EXPECT().
+		GetUserID(gomock.Any(), storedHash).
+		Return(1, nil)
+
+	userID, err := svc.ResolveToken(context.Background(), token)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if userID != 1 {
+		t.Fatalf("expected user 1, got %d", userID)
+	}
+}
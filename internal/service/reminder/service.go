@@ -0,0 +1,56 @@
+package reminder
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/avraam311/improved-calendar-service/internal/models"
+)
+
+// defaultDeadLetters is the number of dead letters listed when no limit is
+// given.
+const defaultDeadLetters = 100
+
+//go:generate mockgen -source=service.go -destination=../../mocks/mock_reminder_service.go -package=mocks -mock_names=reminderRepo=MockreminderServiceRepo
+type reminderRepo interface {
+	ListDeadLetters(ctx context.Context, limit int, includeReplayed bool) ([]*models.DeadLetter, error)
+	ReplayDeadLetter(ctx context.Context, ID uint, now time.Time) (uint, error)
+}
+
+// Service gives the administrators access to the reminders that could not be
+// delivered.
+type Service struct {
+	reminderRepo reminderRepo
+}
+
+func New(r reminderRepo) *Service {
+	return &Service{
+		reminderRepo: r,
+	}
+}
+
+func (s *Service) ListDeadLetters(ctx context.Context, get *models.DeadLettersGet) ([]*models.DeadLetter, error) {
+	limit := get.Limit
+	if limit == 0 {
+		limit = defaultDeadLetters
+	}
+
+	letters, err := s.reminderRepo.ListDeadLetters(ctx, limit, get.IncludeReplayed)
+	if err != nil {
+		return nil, fmt.Errorf("service/ListDeadLetters - %w", err)
+	}
+
+	return letters, nil
+}
+
+// ReplayDeadLetter queues the reminder of the dead letter for delivery right
+// away, with a fresh set of attempts. It returns the ID of the reminder.
+func (s *Service) ReplayDeadLetter(ctx context.Context, ID uint) (uint, error) {
+	reminderID, err := s.reminderRepo.ReplayDeadLetter(ctx, ID, time.Now().UTC())
+	if err != nil {
+		return 0, fmt.Errorf("service/ReplayDeadLetter - %w", err)
+	}
+
+	return reminderID, nil
+}
@@ -0,0 +1,53 @@
+//go:build unit
+// +build unit
+
+package reminder
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+
+	reminderR "github.com/avraam311/improved-calendar-service/internal/mocks"
+	"github.com/avraam311/improved-calendar-service/internal/models"
+	repository "github.com/avraam311/improved-calendar-service/internal/repository/reminder"
+)
+
+func TestServiceListDeadLettersDefaultsLimit(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := reminderR.NewMockreminderServiceRepo(ctrl)
+	svc := New(mockRepo)
+
+	mockRepo.EXPECT().
+		ListDeadLetters(gomock.Any(), defaultDeadLetters, false).
+		Return([]*models.DeadLetter{{ID: 1}}, nil)
+
+	letters, err := svc.ListDeadLetters(context.Background(), &models.DeadLettersGet{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(letters) != 1 {
+		t.Fatalf("expected 1 dead letter, got %d", len(letters))
+	}
+}
+
+func TestServiceReplayDeadLetterNotFound(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := reminderR.NewMockreminderServiceRepo(ctrl)
+	svc := New(mockRepo)
+
+	mockRepo.EXPECT().
+		ReplayDeadLetter(gomock.Any(), uint(4), gomock.Any()).
+		Return(uint(0), repository.ErrDeadLetterNotFound)
+
+	_, err := svc.ReplayDeadLetter(context.Background(), 4)
+	if !errors.Is(err, repository.ErrDeadLetterNotFound) {
+		t.Fatalf("expected ErrDeadLetterNotFound, got %v", err)
+	}
+}
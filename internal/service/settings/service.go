@@ -0,0 +1,42 @@
+package settings
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/avraam311/improved-calendar-service/internal/models"
+)
+
+//go:generate mockgen -source=service.go -destination=../../mocks/mock_settings_service.go -package=mocks -mock_names=settingsRepo=MocksettingsServiceRepo
+type settingsRepo interface {
+	GetSettings(ctx context.Context, userID int) (*models.UserSettings, error)
+	SaveSettings(ctx context.Context, settings *models.UserSettings) error
+}
+
+type Service struct {
+	settingsRepo settingsRepo
+}
+
+func New(r settingsRepo) *Service {
+	return &Service{
+		settingsRepo: r,
+	}
+}
+
+func (s *Service) GetSettings(ctx context.Context, userID int) (*models.UserSettings, error) {
+	settings, err := s.settingsRepo.GetSettings(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("service/GetSettings - %w", err)
+	}
+
+	return settings, nil
+}
+
+func (s *Service) SaveSettings(ctx context.Context, settings *models.UserSettings) error {
+	err := s.settingsRepo.SaveSettings(ctx, settings)
+	if err != nil {
+		return fmt.Errorf("service/SaveSettings - %w", err)
+	}
+
+	return nil
+}
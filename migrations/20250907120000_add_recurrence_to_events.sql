@@ -0,0 +1,14 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE events
+    ADD COLUMN IF NOT EXISTS rrule TEXT,
+    ADD COLUMN IF NOT EXISTS exdates TIMESTAMP[] NOT NULL DEFAULT '{}';
+
+-- +goose StatementEnd
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE events
+    DROP COLUMN IF EXISTS exdates,
+    DROP COLUMN IF EXISTS rrule;
+
+-- +goose StatementEnd
@@ -0,0 +1,26 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE events ADD PRIMARY KEY (id);
+
+ALTER TABLE events
+    ADD COLUMN IF NOT EXISTS parent_id INT REFERENCES events (id) ON DELETE CASCADE,
+    ADD COLUMN IF NOT EXISTS recurrence_id TIMESTAMP,
+    ADD COLUMN IF NOT EXISTS cancelled BOOLEAN NOT NULL DEFAULT false;
+
+CREATE UNIQUE INDEX IF NOT EXISTS events_parent_id_recurrence_id_idx
+    ON events (parent_id, recurrence_id)
+    WHERE parent_id IS NOT NULL;
+
+-- +goose StatementEnd
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS events_parent_id_recurrence_id_idx;
+
+ALTER TABLE events
+    DROP COLUMN IF EXISTS cancelled,
+    DROP COLUMN IF EXISTS recurrence_id,
+    DROP COLUMN IF EXISTS parent_id;
+
+ALTER TABLE events DROP CONSTRAINT IF EXISTS events_pkey;
+
+-- +goose StatementEnd
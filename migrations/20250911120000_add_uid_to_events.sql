@@ -0,0 +1,20 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE events ADD COLUMN IF NOT EXISTS uid TEXT;
+
+UPDATE events
+SET uid = id || '@improved-calendar-service'
+WHERE uid IS NULL AND parent_id IS NULL;
+
+CREATE UNIQUE INDEX IF NOT EXISTS events_user_id_uid_idx
+    ON events (user_id, uid)
+    WHERE parent_id IS NULL;
+
+-- +goose StatementEnd
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS events_user_id_uid_idx;
+
+ALTER TABLE events DROP COLUMN IF EXISTS uid;
+
+-- +goose StatementEnd
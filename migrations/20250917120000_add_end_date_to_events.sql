@@ -0,0 +1,26 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE events
+    ADD COLUMN IF NOT EXISTS end_date TIMESTAMP,
+    ADD COLUMN IF NOT EXISTS all_day BOOLEAN NOT NULL DEFAULT false;
+
+UPDATE events SET end_date = date WHERE end_date IS NULL;
+
+ALTER TABLE events
+    ALTER COLUMN end_date SET NOT NULL,
+    ADD CONSTRAINT events_end_date_check CHECK (end_date >= date);
+
+CREATE INDEX IF NOT EXISTS events_user_id_date_end_date_idx
+    ON events (user_id, date, end_date);
+
+-- +goose StatementEnd
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS events_user_id_date_end_date_idx;
+
+ALTER TABLE events
+    DROP CONSTRAINT IF EXISTS events_end_date_check,
+    DROP COLUMN IF EXISTS all_day,
+    DROP COLUMN IF EXISTS end_date;
+
+-- +goose StatementEnd
@@ -0,0 +1,23 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE EXTENSION IF NOT EXISTS btree_gist;
+
+ALTER TABLE events
+    ADD COLUMN IF NOT EXISTS exclusive BOOLEAN NOT NULL DEFAULT false,
+    ADD COLUMN IF NOT EXISTS period TSRANGE GENERATED ALWAYS AS (tsrange(date, end_date, '[)')) STORED;
+
+-- Events created under the reject conflict policy may not overlap each other.
+-- Recurring series have no finite range and are checked by the service.
+ALTER TABLE events ADD CONSTRAINT events_no_overlap
+    EXCLUDE USING gist (user_id WITH =, period WITH &&)
+    WHERE (exclusive AND NOT cancelled AND rrule IS NULL);
+
+-- +goose StatementEnd
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE events
+    DROP CONSTRAINT IF EXISTS events_no_overlap,
+    DROP COLUMN IF EXISTS period,
+    DROP COLUMN IF EXISTS exclusive;
+
+-- +goose StatementEnd
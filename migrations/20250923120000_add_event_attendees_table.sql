@@ -0,0 +1,21 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS event_attendees (
+    id SERIAL PRIMARY KEY,
+    event_id INT NOT NULL REFERENCES events (id) ON DELETE CASCADE,
+    user_id INT,
+    email TEXT NOT NULL,
+    status TEXT NOT NULL DEFAULT 'needs-action',
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (event_id, email)
+);
+
+CREATE INDEX IF NOT EXISTS event_attendees_user_id_idx ON event_attendees (user_id);
+
+-- +goose StatementEnd
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS event_attendees;
+
+-- +goose StatementEnd
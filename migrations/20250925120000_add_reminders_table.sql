@@ -0,0 +1,26 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS reminders (
+    id SERIAL PRIMARY KEY,
+    event_id INT NOT NULL REFERENCES events (id) ON DELETE CASCADE,
+    user_id INT NOT NULL,
+    event TEXT NOT NULL,
+    date TIMESTAMP NOT NULL,
+    mail TEXT NOT NULL,
+    fire_at TIMESTAMP NOT NULL,
+    channel TEXT NOT NULL DEFAULT 'email',
+    status TEXT NOT NULL DEFAULT 'pending',
+    attempts INT NOT NULL DEFAULT 0,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS reminders_due_idx ON reminders (fire_at) WHERE status = 'pending';
+CREATE UNIQUE INDEX IF NOT EXISTS reminders_event_pending_idx ON reminders (event_id) WHERE status = 'pending';
+
+-- +goose StatementEnd
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS reminders;
+
+-- +goose StatementEnd
@@ -0,0 +1,23 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE events ADD COLUMN IF NOT EXISTS reminders JSONB;
+ALTER TABLE user_settings ADD COLUMN IF NOT EXISTS reminders JSONB;
+ALTER TABLE reminders ADD COLUMN IF NOT EXISTS offset_minutes INT NOT NULL DEFAULT 60;
+
+DROP INDEX IF EXISTS reminders_event_pending_idx;
+CREATE UNIQUE INDEX IF NOT EXISTS reminders_event_pending_idx
+    ON reminders (event_id, offset_minutes, channel) WHERE status = 'pending';
+
+-- +goose StatementEnd
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS reminders_event_pending_idx;
+DELETE FROM reminders a USING reminders b
+    WHERE a.status = 'pending' AND b.status = 'pending' AND a.event_id = b.event_id AND a.id > b.id;
+CREATE UNIQUE INDEX IF NOT EXISTS reminders_event_pending_idx ON reminders (event_id) WHERE status = 'pending';
+
+ALTER TABLE reminders DROP COLUMN IF EXISTS offset_minutes;
+ALTER TABLE user_settings DROP COLUMN IF EXISTS reminders;
+ALTER TABLE events DROP COLUMN IF EXISTS reminders;
+
+-- +goose StatementEnd
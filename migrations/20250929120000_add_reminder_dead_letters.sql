@@ -0,0 +1,31 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE reminders ADD COLUMN IF NOT EXISTS next_attempt_at TIMESTAMP;
+UPDATE reminders SET next_attempt_at = fire_at WHERE next_attempt_at IS NULL;
+ALTER TABLE reminders ALTER COLUMN next_attempt_at SET NOT NULL;
+ALTER TABLE reminders ADD COLUMN IF NOT EXISTS last_error TEXT;
+
+DROP INDEX IF EXISTS reminders_due_idx;
+CREATE INDEX IF NOT EXISTS reminders_due_idx ON reminders (next_attempt_at) WHERE status IN ('pending', 'retry');
+
+CREATE TABLE IF NOT EXISTS reminder_dead_letters (
+    id SERIAL PRIMARY KEY,
+    reminder_id INT NOT NULL REFERENCES reminders (id) ON DELETE CASCADE,
+    error TEXT NOT NULL,
+    attempts INT NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    replayed_at TIMESTAMP
+);
+
+-- +goose StatementEnd
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS reminder_dead_letters;
+
+DROP INDEX IF EXISTS reminders_due_idx;
+CREATE INDEX IF NOT EXISTS reminders_due_idx ON reminders (fire_at) WHERE status = 'pending';
+
+ALTER TABLE reminders DROP COLUMN IF EXISTS last_error;
+ALTER TABLE reminders DROP COLUMN IF EXISTS next_attempt_at;
+
+-- +goose StatementEnd
@@ -0,0 +1,10 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE user_settings ADD COLUMN IF NOT EXISTS channels JSONB;
+
+-- +goose StatementEnd
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE user_settings DROP COLUMN IF EXISTS channels;
+
+-- +goose StatementEnd
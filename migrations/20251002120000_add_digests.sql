@@ -0,0 +1,20 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE user_settings ADD COLUMN IF NOT EXISTS digest JSONB;
+
+CREATE TABLE IF NOT EXISTS digests (
+    user_id INT NOT NULL,
+    kind TEXT NOT NULL,
+    period DATE NOT NULL,
+    sent_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (user_id, kind, period)
+);
+
+-- +goose StatementEnd
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS digests;
+
+ALTER TABLE user_settings DROP COLUMN IF EXISTS digest;
+
+-- +goose StatementEnd
@@ -0,0 +1,14 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE events ADD COLUMN IF NOT EXISTS urgent BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE reminders ADD COLUMN IF NOT EXISTS urgent BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE user_settings ADD COLUMN IF NOT EXISTS quiet JSONB;
+
+-- +goose StatementEnd
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE user_settings DROP COLUMN IF EXISTS quiet;
+ALTER TABLE reminders DROP COLUMN IF EXISTS urgent;
+ALTER TABLE events DROP COLUMN IF EXISTS urgent;
+
+-- +goose StatementEnd
@@ -0,0 +1,34 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS events_archive (
+    id INT PRIMARY KEY,
+    user_id INT NOT NULL,
+    event TEXT NOT NULL,
+    date TIMESTAMP NOT NULL,
+    end_date TIMESTAMP NOT NULL,
+    all_day BOOLEAN NOT NULL DEFAULT false,
+    tz TEXT NOT NULL DEFAULT 'UTC',
+    mail TEXT NOT NULL,
+    rrule TEXT,
+    exdates TIMESTAMP[] NOT NULL DEFAULT '{}',
+    uid TEXT,
+    parent_id INT,
+    recurrence_id TIMESTAMP,
+    cancelled BOOLEAN NOT NULL DEFAULT false,
+    exclusive BOOLEAN NOT NULL DEFAULT false,
+    reminders JSONB,
+    urgent BOOLEAN NOT NULL DEFAULT false,
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL,
+    archived_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS events_archive_user_id_date_idx ON events_archive (user_id, date) WHERE parent_id IS NULL;
+CREATE INDEX IF NOT EXISTS events_archive_parent_id_idx ON events_archive (parent_id) WHERE parent_id IS NOT NULL;
+
+-- +goose StatementEnd
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS events_archive;
+
+-- +goose StatementEnd
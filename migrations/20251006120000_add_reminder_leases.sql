@@ -0,0 +1,16 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Claimed reminders are 'sending' until their result is saved, a claim whose
+-- lease in next_attempt_at expired is due again.
+DROP INDEX IF EXISTS reminders_due_idx;
+CREATE INDEX IF NOT EXISTS reminders_due_idx ON reminders (next_attempt_at) WHERE status IN ('pending', 'retry', 'sending');
+
+-- +goose StatementEnd
+-- +goose Down
+-- +goose StatementBegin
+UPDATE reminders SET status = 'retry' WHERE status = 'sending';
+
+DROP INDEX IF EXISTS reminders_due_idx;
+CREATE INDEX IF NOT EXISTS reminders_due_idx ON reminders (next_attempt_at) WHERE status IN ('pending', 'retry');
+
+-- +goose StatementEnd
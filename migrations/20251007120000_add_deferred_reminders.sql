@@ -0,0 +1,19 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Reminders put off by the quiet time of the user are 'deferred', so the
+-- missed sweep of pending reminders leaves them alone.
+UPDATE reminders SET status = 'deferred' WHERE status = 'pending' AND next_attempt_at > fire_at;
+
+DROP INDEX IF EXISTS reminders_due_idx;
+CREATE INDEX IF NOT EXISTS reminders_due_idx ON reminders (next_attempt_at)
+    WHERE status IN ('pending', 'retry', 'sending', 'deferred');
+
+-- +goose StatementEnd
+-- +goose Down
+-- +goose StatementBegin
+UPDATE reminders SET status = 'pending' WHERE status = 'deferred';
+
+DROP INDEX IF EXISTS reminders_due_idx;
+CREATE INDEX IF NOT EXISTS reminders_due_idx ON reminders (next_attempt_at) WHERE status IN ('pending', 'retry', 'sending');
+
+-- +goose StatementEnd
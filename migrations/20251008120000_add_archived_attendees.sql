@@ -0,0 +1,12 @@
+-- +goose Up
+-- +goose StatementBegin
+-- The attendees of an archived event, as a JSON array of their rows, so they
+-- come back with the event.
+ALTER TABLE events_archive ADD COLUMN IF NOT EXISTS attendees JSONB;
+
+-- +goose StatementEnd
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE events_archive DROP COLUMN IF EXISTS attendees;
+
+-- +goose StatementEnd
@@ -0,0 +1,13 @@
+-- +goose Up
+-- +goose StatementBegin
+-- The end of the last occurrence of a series bounded by a COUNT, which SQL
+-- can't compute from the rule. The cleaner fills it in and updates of the
+-- series clear it.
+ALTER TABLE events ADD COLUMN IF NOT EXISTS series_end TIMESTAMP;
+
+-- +goose StatementEnd
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE events DROP COLUMN IF EXISTS series_end;
+
+-- +goose StatementEnd
@@ -0,0 +1,13 @@
+-- +goose Up
+-- +goose StatementBegin
+-- The time an event was restored from the archive. The retention of a
+-- restored event counts from it, so the cleaner doesn't archive it again on
+-- its next run.
+ALTER TABLE events ADD COLUMN IF NOT EXISTS restored_at TIMESTAMP;
+
+-- +goose StatementEnd
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE events DROP COLUMN IF EXISTS restored_at;
+
+-- +goose StatementEnd